@@ -21,6 +21,10 @@ import (
 
 var outputFormat string
 var outputPlain bool
+var outputQuery string
+var outputColumns []string
+var outputNoTruncate bool
+var outputTemplate string
 
 const defaultProfileName string = "default"
 
@@ -194,9 +198,17 @@ func init() {
 
 	Command.PersistentFlags().StringVar(&outputFormat, "format", output.DefaultFormat.String(), "output text format ["+output.FormatOptions()+"]")
 	Command.PersistentFlags().BoolVar(&outputPlain, "plain", false, "output compact text")
+	Command.PersistentFlags().StringSliceVar(&outputColumns, "columns", []string{}, "the columns, in order, of Text, CSV and TSV output")
+	Command.PersistentFlags().BoolVar(&outputNoTruncate, "no-truncate", false, "do not truncate Text output to the terminal width")
+	Command.PersistentFlags().StringVar(&outputQuery, "output-query", "", "select part of the output with a gjson or JSONPath expression, e.g. \"#.name\"")
+	Command.PersistentFlags().StringVar(&outputTemplate, "output-template", "", "print each result with a Go template, e.g. '{{.name}} {{.guid}}'")
 }
 
 func initConfig() {
 	utils.LogIfError(output.SetFormat(output.ParseFormat(outputFormat)))
 	utils.LogIfError(output.SetPrettyPrint(!outputPlain))
+	utils.LogIfError(output.SetColumns(outputColumns))
+	utils.LogIfError(output.SetNoTruncate(outputNoTruncate))
+	utils.LogIfError(output.SetQuery(outputQuery))
+	utils.LogIfFatal(output.SetTemplate(outputTemplate))
 }
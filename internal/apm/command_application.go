@@ -13,8 +13,9 @@ import (
 )
 
 var (
-	appName string
-	appGUID string
+	appName  string
+	appGUID  string
+	appLimit int
 )
 
 // Command represents the apm command
@@ -31,6 +32,8 @@ var cmdAppSearch = &cobra.Command{
 	Long: `Search for a New Relic application
 
 The search command performs a query for an APM application name and/or account ID.
+Results are fetched a page at a time until --limit applications have been found.
+Use --limit 0 to return every matching application.
 `,
 	Example: "newrelic apm application search --name <appName>",
 	Run: func(cmd *cobra.Command, args []string) {
@@ -68,15 +71,14 @@ The search command performs a query for an APM application name and/or account I
 					params.Tags = []entities.EntitySearchQueryBuilderTag{{Key: "accountId", Value: apmAccountID}}
 				}
 
-				results, err := nrClient.Entities.GetEntitySearch(
-					entities.EntitySearchOptions{},
-					"",
-					params,
-					[]entities.EntitySearchSortCriteria{},
-				)
-
-				entityResults = results.Results.Entities
+				var results *client.EntitySearchPage
+				results, err = client.SearchEntities(utils.SignalCtx, &nrClient.NerdGraph, client.EntitySearchParams{QueryBuilder: params}, appLimit)
 				utils.LogIfFatal(err)
+
+				entityResults = results.Entities
+				if len(entityResults) < results.Count {
+					log.Infof("showing %d of %d matching applications, use --limit to see more", len(entityResults), results.Count)
+				}
 			}
 
 			utils.LogIfFatal(output.Print(entityResults))
@@ -120,4 +122,5 @@ func init() {
 
 	cmdApp.AddCommand(cmdAppSearch)
 	cmdAppSearch.Flags().StringVarP(&appName, "name", "n", "", "search for results matching the given APM application name")
+	cmdAppSearch.Flags().IntVar(&appLimit, "limit", client.DefaultEntitySearchLimit, "the maximum number of applications to return, or 0 for all")
 }
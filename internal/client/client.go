@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -16,6 +17,12 @@ var (
 	version     = "dev"
 )
 
+// NerdGraphQuerier runs a NerdGraph query, decoding the response into respBody.
+// It is satisfied by the NerdGraph client and by mocks in tests.
+type NerdGraphQuerier interface {
+	QueryWithResponseAndContext(ctx context.Context, query string, variables map[string]interface{}, respBody interface{}) error
+}
+
 // CreateNRClient initializes the New Relic client.
 func CreateNRClient(cfg *config.Config, creds *credentials.Credentials) (*newrelic.NewRelic, *credentials.Profile, error) {
 	var (
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/newrelic/newrelic-client-go/pkg/entities"
+)
+
+// DefaultEntitySearchLimit is the default maximum number of entities returned
+// by commands that search for entities.
+const DefaultEntitySearchLimit = 200
+
+// EntitySearchParams describes an entity search.  Query is an entity search
+// query string, such as "domain = 'APM' AND reporting = 'true'", and is used
+// instead of the query builder when given.
+type EntitySearchParams struct {
+	Query        string
+	QueryBuilder entities.EntitySearchQueryBuilder
+	SortBy       []entities.EntitySearchSortCriteria
+}
+
+// EntitySearchPage is a page of entity search results.
+type EntitySearchPage struct {
+	Count    int
+	Entities []entities.EntityOutlineInterface
+}
+
+// SearchEntities returns the entities matching a search, following the
+// result cursor until the results are exhausted or limit entities have been
+// returned.  A limit of zero or less returns every result.  The count is the
+// total number of matching entities, which can be more than those returned.
+func SearchEntities(ctx context.Context, nerdGraph NerdGraphQuerier, params EntitySearchParams, limit int) (*EntitySearchPage, error) {
+	vars := map[string]interface{}{}
+
+	if params.Query != "" {
+		vars["query"] = params.Query
+	} else {
+		vars["queryBuilder"] = params.QueryBuilder
+	}
+
+	if len(params.SortBy) > 0 {
+		vars["sortBy"] = params.SortBy
+	}
+
+	page := &EntitySearchPage{Entities: []entities.EntityOutlineInterface{}}
+	seen := map[string]bool{}
+
+	for {
+		resp := entitySearchResponse{}
+		if err := nerdGraph.QueryWithResponseAndContext(ctx, entitySearchQuery, vars, &resp); err != nil {
+			return nil, err
+		}
+
+		search := resp.Actor.EntitySearch
+		page.Count = search.Count
+
+		for _, e := range search.Results.Entities {
+			if limit > 0 && len(page.Entities) >= limit {
+				return page, nil
+			}
+
+			page.Entities = append(page.Entities, e)
+		}
+
+		cursor := search.Results.NextCursor
+		if cursor == "" || (limit > 0 && len(page.Entities) >= limit) {
+			return page, nil
+		}
+
+		if seen[cursor] {
+			return nil, fmt.Errorf("the entity search returned the cursor %s twice", cursor)
+		}
+
+		seen[cursor] = true
+		vars["cursor"] = cursor
+	}
+}
+
+type entitySearchResponse struct {
+	Actor struct {
+		EntitySearch entities.EntitySearch `json:"entitySearch"`
+	} `json:"actor"`
+}
+
+const entitySearchQuery = `query(
+	$query: String,
+	$queryBuilder: EntitySearchQueryBuilder,
+	$sortBy: [EntitySearchSortCriteria],
+	$cursor: String,
+) { actor { entitySearch(
+	query: $query,
+	queryBuilder: $queryBuilder,
+	sortBy: $sortBy,
+) {
+	count
+	results(cursor: $cursor) {
+		entities {
+			__typename
+			accountId
+			domain
+			entityType
+			guid
+			indexedAt
+			name
+			permalink
+			reporting
+			type
+			... on ApmApplicationEntityOutline {
+				__typename
+				alertSeverity
+				applicationId
+				language
+			}
+			... on ApmDatabaseInstanceEntityOutline {
+				__typename
+				host
+				portOrPath
+				vendor
+			}
+			... on ApmExternalServiceEntityOutline {
+				__typename
+				host
+			}
+			... on BrowserApplicationEntityOutline {
+				__typename
+				agentInstallType
+				alertSeverity
+				applicationId
+				servingApmApplicationId
+			}
+			... on DashboardEntityOutline {
+				__typename
+				dashboardParentGuid
+			}
+			... on GenericEntityOutline {
+				__typename
+			}
+			... on GenericInfrastructureEntityOutline {
+				__typename
+				alertSeverity
+				integrationTypeCode
+			}
+			... on InfrastructureAwsLambdaFunctionEntityOutline {
+				__typename
+				alertSeverity
+				integrationTypeCode
+				runtime
+			}
+			... on InfrastructureHostEntityOutline {
+				__typename
+				alertSeverity
+			}
+			... on MobileApplicationEntityOutline {
+				__typename
+				alertSeverity
+				applicationId
+			}
+			... on SecureCredentialEntityOutline {
+				__typename
+				description
+				secureCredentialId
+				updatedAt
+			}
+			... on SyntheticMonitorEntityOutline {
+				__typename
+				alertSeverity
+				monitorId
+				monitorType
+				monitoredUrl
+				period
+			}
+			... on ThirdPartyServiceEntityOutline {
+				__typename
+				alertSeverity
+			}
+			... on UnavailableEntityOutline {
+				__typename
+			}
+			... on WorkloadEntityOutline {
+				__typename
+				alertSeverity
+				createdAt
+				updatedAt
+			}
+		}
+		nextCursor
+	}
+} } }`
@@ -0,0 +1,91 @@
+// +build unit
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockNerdGraph struct {
+	pages []string
+	vars  []map[string]interface{}
+}
+
+func (m *mockNerdGraph) QueryWithResponseAndContext(ctx context.Context, query string, vars map[string]interface{}, respBody interface{}) error {
+	copied := map[string]interface{}{}
+	for k, v := range vars {
+		copied[k] = v
+	}
+
+	page := m.pages[len(m.vars)]
+	m.vars = append(m.vars, copied)
+
+	return json.Unmarshal([]byte(page), respBody)
+}
+
+func entityPage(cursor string, guids ...string) string {
+	entities := []map[string]string{}
+	for _, g := range guids {
+		entities = append(entities, map[string]string{"__typename": "GenericEntityOutline", "guid": g})
+	}
+
+	out, _ := json.Marshal(map[string]interface{}{
+		"actor": map[string]interface{}{
+			"entitySearch": map[string]interface{}{
+				"count":   5,
+				"results": map[string]interface{}{"entities": entities, "nextCursor": cursor},
+			},
+		},
+	})
+
+	return string(out)
+}
+
+func TestSearchEntities(t *testing.T) {
+	m := &mockNerdGraph{pages: []string{
+		entityPage("c1", "a", "b"),
+		entityPage("c2", "c", "d"),
+		entityPage("", "e"),
+	}}
+
+	page, err := SearchEntities(context.Background(), m, EntitySearchParams{Query: "domain = 'APM'"}, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, page.Count)
+	require.Len(t, page.Entities, 5)
+	assert.Equal(t, "e", string(page.Entities[4].GetGUID()))
+
+	require.Len(t, m.vars, 3)
+	assert.Equal(t, map[string]interface{}{"query": "domain = 'APM'"}, m.vars[0])
+	assert.Equal(t, "c2", m.vars[2]["cursor"])
+}
+
+func TestSearchEntities_Limit(t *testing.T) {
+	m := &mockNerdGraph{pages: []string{
+		entityPage("c1", "a", "b"),
+		entityPage("c2", "c", "d"),
+	}}
+
+	page, err := SearchEntities(context.Background(), m, EntitySearchParams{}, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, page.Count)
+	assert.Len(t, page.Entities, 3)
+	assert.Len(t, m.vars, 2)
+	assert.Contains(t, m.vars[0], "queryBuilder")
+}
+
+func TestSearchEntities_RepeatedCursor(t *testing.T) {
+	m := &mockNerdGraph{pages: []string{
+		entityPage("c1", "a"),
+		entityPage("c1", "b"),
+	}}
+
+	_, err := SearchEntities(context.Background(), m, EntitySearchParams{}, 0)
+	assert.EqualError(t, err, "the entity search returned the cursor c1 twice")
+}
@@ -6,14 +6,19 @@ import (
 
 // Should these be moved out or made into higher-level flags?
 var (
-	entityAlertSeverity string
-	entityDomain        string
-	entityFields        []string
-	entityGUID          string
-	entityName          string
-	entityReporting     string
-	entityType          string
-	entityValues        []string
+	entityAlertSeverity   string
+	entityCount           bool
+	entityDomain          string
+	entityFields          []string
+	entityGUID            string
+	entityLimit           int
+	entityName            string
+	entityQuery           string
+	entityReporting       string
+	entitySearchAccountID int
+	entitySort            []string
+	entityType            string
+	entityValues          []string
 )
 
 // Command represents the entities command
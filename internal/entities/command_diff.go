@@ -0,0 +1,59 @@
+package entities
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+)
+
+var diffExitCode bool
+
+var cmdEntityDiff = &cobra.Command{
+	Use:   "diff <old snapshot> <new snapshot>",
+	Short: "Compare two entity snapshots",
+	Long: `Compare two entity snapshots
+
+The diff command compares two snapshots taken with the snapshot command.  It lists
+the entities added and removed, and for the entities in both, the changes to their
+name, alert severity, reporting status and tags.  Entities that stop reporting show
+as a change to reporting from true to false.
+
+Use --exit-code to fail when there are differences, such as when checking for drift
+in CI.
+`,
+	Example: `newrelic entity diff snap-old.json snap-new.json
+newrelic entity diff snap-old.json snap-new.json --exit-code --format Text`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		older, err := readEntitySnapshot(args[0])
+		utils.LogIfFatal(err)
+
+		newer, err := readEntitySnapshot(args[1])
+		utils.LogIfFatal(err)
+
+		changes := diffSnapshots(older, newer)
+
+		counts := map[string]int{}
+		for _, c := range changes {
+			counts[c.Change]++
+		}
+
+		if len(changes) > 0 {
+			utils.LogIfFatal(output.Print(changes))
+		}
+
+		log.Infof("%d added, %d removed, %d changes to existing entities",
+			counts[snapshotChangeAdded], counts[snapshotChangeRemoved], counts[snapshotChangeChanged])
+
+		if diffExitCode && len(changes) > 0 {
+			log.Fatal("the snapshots differ")
+		}
+	},
+}
+
+func init() {
+	Command.AddCommand(cmdEntityDiff)
+	cmdEntityDiff.Flags().BoolVar(&diffExitCode, "exit-code", false, "exit with an error when the snapshots differ")
+}
@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+)
+
+var (
+	relationshipDepth int
+	relationshipTypes []string
+	graphFormat       string
+)
+
+var cmdEntityRelationships = &cobra.Command{
+	Use:   "relationships",
+	Short: "Export the graph of an entity's relationships",
+	Long: `Export the graph of an entity's relationships
+
+The relationships command walks the relationships of an entity, such as the services
+it calls, the hosts that run it or the workloads that contain it, and those of the
+entities it finds, up to --depth relationships away.  Use --type to follow only
+some kinds of relationship, such as CALLS for a service map.
+
+The graph is printed as JSON, as a Graphviz DOT graph, or as a Mermaid flowchart,
+depending on --graph-format.
+`,
+	Example: `newrelic entity relationships --guid <entityGUID> --depth 2
+newrelic entity relationships --guid <entityGUID> --graph-format dot | dot -Tsvg > graph.svg
+newrelic entity relationships --guid <entityGUID> --type CALLS --graph-format mermaid`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if relationshipDepth < 0 {
+			log.Fatal("--depth must be zero or more")
+		}
+
+		if !stringSet(graphFormats)[graphFormat] {
+			log.Fatalf("--graph-format must be one of %s", strings.Join(graphFormats, ", "))
+		}
+
+		client.WithClient(func(nrClient *newrelic.NewRelic) {
+			g, err := walkRelationships(utils.SignalCtx, &nrClient.NerdGraph, entityGUID, relationshipDepth, relationshipTypes)
+			utils.LogIfFatal(err)
+
+			switch graphFormat {
+			case graphFormatDOT:
+				utils.LogIfFatal(g.writeDOT(os.Stdout))
+			case graphFormatMermaid:
+				utils.LogIfFatal(g.writeMermaid(os.Stdout))
+			default:
+				utils.LogIfFatal(output.Print(g))
+			}
+		})
+	},
+}
+
+func init() {
+	Command.AddCommand(cmdEntityRelationships)
+	cmdEntityRelationships.Flags().StringVarP(&entityGUID, "guid", "g", "", "the GUID of the entity to start from")
+	cmdEntityRelationships.Flags().IntVar(&relationshipDepth, "depth", 1, "how many relationships away from the entity to walk")
+	cmdEntityRelationships.Flags().StringSliceVar(&relationshipTypes, "type", []string{}, "follow only these relationship types, e.g. CALLS or HOSTS")
+	cmdEntityRelationships.Flags().StringVar(&graphFormat, "graph-format", graphFormatJSON, "the graph format, one of json, dot or mermaid")
+	utils.LogIfError(cmdEntityRelationships.MarkFlagRequired("guid"))
+}
@@ -0,0 +1,18 @@
+// +build unit
+
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+)
+
+func TestEntitiesRelationships(t *testing.T) {
+	assert.Equal(t, "relationships", cmdEntityRelationships.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdEntityRelationships)
+	testcobra.CheckCobraRequiredFlags(t, cmdEntityRelationships, []string{"guid"})
+}
@@ -1,7 +1,10 @@
 package entities
 
 import (
+	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -18,61 +21,47 @@ var cmdEntitySearch = &cobra.Command{
 	Short: "Search for New Relic entities",
 	Long: `Search for New Relic entities
 
-The search command performs a search for New Relic entities.
+The search command performs a search for New Relic entities.  Results are fetched
+a page at a time until --limit entities have been found.  Use --limit 0 to return
+every matching entity, or --count to print only how many entities match.
+
+The --query flag takes an entity search query, such as
+"domain = 'APM' AND tags.team = 'payments'".  Any other filters given are added to
+it.  The --tag flag can be given more than once to match several tags.
 `,
-	Example: "newrelic entity search --name <applicationName>",
+	Example: `newrelic entity search --name <applicationName>
+newrelic entity search --query "domain = 'APM' AND tags.team = 'payments'" --sort NAME
+newrelic entity search --domain APM --tag team:payments --tag env:production --accountId 12345
+newrelic entity search --type DASHBOARD --count`,
 	Run: func(cmd *cobra.Command, args []string) {
-		client.WithClient(func(nrClient *newrelic.NewRelic) {
-			params := entities.EntitySearchQueryBuilder{}
+		if !hasEntitySearchFilter() {
+			utils.LogIfError(cmd.Help())
+			log.Fatal("one of --query, --name, --type, --alert-severity, --domain, --tag or --accountId are required")
+		}
 
-			if entityName == "" && entityType == "" && entityAlertSeverity == "" && entityDomain == "" {
-				utils.LogIfError(cmd.Help())
-				log.Fatal("one of --name, --type, --alert-severity, or --domain are required")
-			}
-
-			if entityName != "" {
-				params.Name = entityName
-			}
-
-			if entityType != "" {
-				params.Type = entities.EntitySearchQueryBuilderType(entityType)
-			}
-
-			if entityAlertSeverity != "" {
-				params.AlertSeverity = entities.EntityAlertSeverity(entityAlertSeverity)
-			}
+		params, err := entitySearchParams()
+		utils.LogIfFatal(err)
 
-			if entityDomain != "" {
-				params.Domain = entities.EntitySearchQueryBuilderDomain(entityDomain)
+		client.WithClient(func(nrClient *newrelic.NewRelic) {
+			limit := entityLimit
+			if entityCount {
+				// The count is returned with the first page
+				limit = 1
 			}
 
-			if entityTag != "" {
-				key, value, err := assembleTagValue(entityTag)
-				utils.LogIfFatal(err)
+			results, err := client.SearchEntities(utils.SignalCtx, &nrClient.NerdGraph, params, limit)
+			utils.LogIfFatal(err)
 
-				params.Tags = []entities.EntitySearchQueryBuilderTag{{Key: key, Value: value}}
+			if entityCount {
+				utils.LogIfFatal(output.Print(results.Count))
+				return
 			}
 
-			if entityReporting != "" {
-				reporting, err := strconv.ParseBool(entityReporting)
-
-				if err != nil {
-					log.Fatalf("invalid value provided for flag --reporting. Must be true or false.")
-				}
-
-				params.Reporting = reporting
+			entities := results.Entities
+			if len(entities) < results.Count {
+				log.Infof("showing %d of %d matching entities, use --limit to see more", len(entities), results.Count)
 			}
 
-			results, err := nrClient.Entities.GetEntitySearch(
-				entities.EntitySearchOptions{},
-				"",
-				params,
-				[]entities.EntitySearchSortCriteria{},
-			)
-			utils.LogIfFatal(err)
-
-			entities := results.Results.Entities
-
 			var result interface{}
 
 			if len(entityFields) > 0 {
@@ -96,6 +85,154 @@ The search command performs a search for New Relic entities.
 	},
 }
 
+// hasEntitySearchFilter reports whether any of the flags that filter an entity
+// search were given.
+func hasEntitySearchFilter() bool {
+	return entityQuery != "" || entityName != "" || entityType != "" || entityAlertSeverity != "" || entityDomain != "" ||
+		len(entityTags) > 0 || entitySearchAccountID != 0
+}
+
+// entitySearchParams returns the search described by the flags.  The search
+// uses the query builder, unless a query is given or the filters cannot be
+// expressed with the builder, in which case the filters are written as an
+// entity search query.
+func entitySearchParams() (client.EntitySearchParams, error) {
+	params := client.EntitySearchParams{}
+
+	for _, s := range entitySort {
+		criteria := entities.EntitySearchSortCriteria(strings.ToUpper(s))
+		if !isSortCriteria(criteria) {
+			return params, fmt.Errorf("invalid value provided for flag --sort: %s", s)
+		}
+
+		params.SortBy = append(params.SortBy, criteria)
+	}
+
+	tags, reporting, err := entitySearchFilters()
+	if err != nil {
+		return params, err
+	}
+
+	// The builder omits reporting when false, so only the query can express it
+	if entityQuery == "" && (reporting == nil || *reporting) {
+		params.QueryBuilder = entities.EntitySearchQueryBuilder{
+			Name:          entityName,
+			Type:          entities.EntitySearchQueryBuilderType(entityType),
+			AlertSeverity: entities.EntityAlertSeverity(entityAlertSeverity),
+			Domain:        entities.EntitySearchQueryBuilderDomain(entityDomain),
+			Tags:          tags,
+			Reporting:     reporting != nil,
+		}
+
+		return params, nil
+	}
+
+	params.Query = writeEntitySearchQuery(tags, reporting)
+
+	return params, nil
+}
+
+// entitySearchQuery returns the search described by the flags written as an
+// entity search query, for commands that record the search they ran.
+func entitySearchQuery() (string, error) {
+	tags, reporting, err := entitySearchFilters()
+	if err != nil {
+		return "", err
+	}
+
+	return writeEntitySearchQuery(tags, reporting), nil
+}
+
+// entitySearchFilters returns the tags to search for, including the account
+// ID, and the reporting status if one was given.
+func entitySearchFilters() ([]entities.EntitySearchQueryBuilderTag, *bool, error) {
+	tags, err := assembleTagValues(entityTags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var reporting *bool
+	if entityReporting != "" {
+		r, parseErr := strconv.ParseBool(entityReporting)
+		if parseErr != nil {
+			return nil, nil, fmt.Errorf("invalid value provided for flag --reporting. Must be true or false")
+		}
+
+		reporting = &r
+	}
+
+	if entitySearchAccountID != 0 {
+		tags = append(tags, entities.EntitySearchQueryBuilderTag{Key: "accountId", Value: strconv.Itoa(entitySearchAccountID)})
+	}
+
+	return tags, reporting, nil
+}
+
+// writeEntitySearchQuery combines the query and the filters given by the
+// flags into one entity search query.
+func writeEntitySearchQuery(tags []entities.EntitySearchQueryBuilderTag, reporting *bool) string {
+	conditions := []string{}
+	if entityQuery != "" {
+		conditions = append(conditions, "("+entityQuery+")")
+	}
+
+	for _, c := range []struct{ attribute, value string }{
+		{"name", entityName},
+		{"type", entityType},
+		{"alertSeverity", entityAlertSeverity},
+		{"domain", entityDomain},
+	} {
+		if c.value != "" {
+			operator := "="
+			if c.attribute == "name" {
+				operator = "LIKE"
+			}
+
+			conditions = append(conditions, fmt.Sprintf("%s %s %s", c.attribute, operator, quoteEntityQueryValue(c.value)))
+		}
+	}
+
+	if reporting != nil {
+		conditions = append(conditions, fmt.Sprintf("reporting = '%t'", *reporting))
+	}
+
+	for _, t := range tags {
+		conditions = append(conditions, fmt.Sprintf("tags.%s = %s", quoteEntityQueryKey(t.Key), quoteEntityQueryValue(t.Value)))
+	}
+
+	return strings.Join(conditions, " AND ")
+}
+
+func isSortCriteria(criteria entities.EntitySearchSortCriteria) bool {
+	switch criteria {
+	case entities.EntitySearchSortCriteriaTypes.ALERT_SEVERITY,
+		entities.EntitySearchSortCriteriaTypes.DOMAIN,
+		entities.EntitySearchSortCriteriaTypes.MOST_RELEVANT,
+		entities.EntitySearchSortCriteriaTypes.NAME,
+		entities.EntitySearchSortCriteriaTypes.REPORTING,
+		entities.EntitySearchSortCriteriaTypes.TYPE:
+		return true
+	}
+
+	return false
+}
+
+var plainEntityQueryKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// quoteEntityQueryKey quotes a tag key with backticks if it has characters
+// other than letters, digits and underscores.
+func quoteEntityQueryKey(key string) string {
+	if plainEntityQueryKey.MatchString(key) {
+		return key
+	}
+
+	return "`" + strings.ReplaceAll(key, "`", "\\`") + "`"
+}
+
+func quoteEntityQueryValue(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "\\'") + "'"
+}
+
 func mapEntities(entities []entities.EntityOutlineInterface, fields []string, fn utils.StructToMapCallback) []map[string]interface{} {
 	mappedEntities := make([]map[string]interface{}, len(entities))
 
@@ -113,6 +250,11 @@ func init() {
 	cmdEntitySearch.Flags().StringVarP(&entityAlertSeverity, "alert-severity", "a", "", "search for entities matching the given alert severity type")
 	cmdEntitySearch.Flags().StringVarP(&entityReporting, "reporting", "r", "", "search for entities based on whether or not an entity is reporting (true or false)")
 	cmdEntitySearch.Flags().StringVarP(&entityDomain, "domain", "d", "", "search for entities matching the given entity domain")
-	cmdEntitySearch.Flags().StringVar(&entityTag, "tag", "", "search for entities matching the given entity tag")
+	cmdEntitySearch.Flags().StringArrayVar(&entityTags, "tag", []string{}, "search for entities with the given key:value tag, repeatable")
+	cmdEntitySearch.Flags().StringVarP(&entityQuery, "query", "q", "", "an entity search query, such as \"domain = 'APM'\"")
+	cmdEntitySearch.Flags().IntVar(&entitySearchAccountID, "accountId", 0, "search for entities in the given account")
+	cmdEntitySearch.Flags().StringSliceVar(&entitySort, "sort", []string{}, "sort by ALERT_SEVERITY, DOMAIN, MOST_RELEVANT, NAME, REPORTING or TYPE")
+	cmdEntitySearch.Flags().BoolVar(&entityCount, "count", false, "print only the number of matching entities")
+	cmdEntitySearch.Flags().IntVar(&entityLimit, "limit", client.DefaultEntitySearchLimit, "the maximum number of entities to return, or 0 for all")
 	cmdEntitySearch.Flags().StringSliceVarP(&entityFields, "fields-filter", "f", []string{}, "filter search results to only return certain fields for each search result")
 }
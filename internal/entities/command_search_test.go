@@ -6,6 +6,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-client-go/pkg/entities"
 )
 
 func TestEntitiesSearch(t *testing.T) {
@@ -14,3 +17,115 @@ func TestEntitiesSearch(t *testing.T) {
 	assert.Equal(t, "search", command.Name())
 	assert.True(t, command.HasFlags())
 }
+
+func resetSearchFlags() {
+	entityQuery = ""
+	entityName = ""
+	entityType = ""
+	entityAlertSeverity = ""
+	entityDomain = ""
+	entityReporting = ""
+	entityTags = []string{}
+	entitySearchAccountID = 0
+	entitySort = []string{}
+}
+
+func TestEntitySearchParams_QueryBuilder(t *testing.T) {
+	defer resetSearchFlags()
+
+	entityDomain = "APM"
+	entityReporting = "true"
+	entityTags = []string{"team:payments", "env:prod"}
+	entitySearchAccountID = 12345
+	entitySort = []string{"name", "TYPE"}
+
+	params, err := entitySearchParams()
+	require.NoError(t, err)
+
+	assert.Equal(t, "", params.Query)
+	assert.Equal(t, entities.EntitySearchQueryBuilder{
+		Domain:    "APM",
+		Reporting: true,
+		Tags: []entities.EntitySearchQueryBuilderTag{
+			{Key: "team", Value: "payments"},
+			{Key: "env", Value: "prod"},
+			{Key: "accountId", Value: "12345"},
+		},
+	}, params.QueryBuilder)
+	assert.Equal(t, []entities.EntitySearchSortCriteria{"NAME", "TYPE"}, params.SortBy)
+}
+
+func TestEntitySearchParams_Query(t *testing.T) {
+	defer resetSearchFlags()
+
+	entityQuery = "domain = 'APM' OR domain = 'BROWSER'"
+	entityName = "Jane's app"
+	entityTags = []string{"aws.region:us-east-1"}
+
+	params, err := entitySearchParams()
+	require.NoError(t, err)
+
+	assert.Equal(t, "(domain = 'APM' OR domain = 'BROWSER') AND name LIKE 'Jane\\'s app' AND tags.`aws.region` = 'us-east-1'", params.Query)
+	assert.Equal(t, entities.EntitySearchQueryBuilder{}, params.QueryBuilder)
+}
+
+func TestEntitySearchParams_NotReporting(t *testing.T) {
+	defer resetSearchFlags()
+
+	entityType = "APPLICATION"
+	entityReporting = "false"
+
+	params, err := entitySearchParams()
+	require.NoError(t, err)
+
+	assert.Equal(t, "type = 'APPLICATION' AND reporting = 'false'", params.Query)
+}
+
+func TestEntitySearchParams_Invalid(t *testing.T) {
+	defer resetSearchFlags()
+
+	entitySort = []string{"SIZE"}
+	_, err := entitySearchParams()
+	assert.EqualError(t, err, "invalid value provided for flag --sort: SIZE")
+
+	entitySort = []string{}
+	entityTags = []string{"team"}
+	_, err = entitySearchParams()
+	assert.Error(t, err)
+}
+
+func TestEntitySearchQuery(t *testing.T) {
+	defer resetSearchFlags()
+
+	// Filters the builder could express are still written as a query
+	entityDomain = "APM"
+	entityReporting = "true"
+	entityTags = []string{"team:payments"}
+	entitySearchAccountID = 12345
+
+	query, err := entitySearchQuery()
+	require.NoError(t, err)
+	assert.Equal(t, "domain = 'APM' AND reporting = 'true' AND tags.team = 'payments' AND tags.accountId = '12345'", query)
+
+	resetSearchFlags()
+	entityQuery = "domain = 'APM'"
+
+	query, err = entitySearchQuery()
+	require.NoError(t, err)
+	assert.Equal(t, "(domain = 'APM')", query)
+}
+
+func TestEntitySearchTagFlag(t *testing.T) {
+	defer resetSearchFlags()
+
+	// Tag values may contain commas, so each --tag is one tag
+	require.NoError(t, cmdEntitySearch.Flags().Parse([]string{"--tag", "team:a,b", "--tag", "env:prod"}))
+	assert.Equal(t, []string{"team:a,b", "env:prod"}, entityTags)
+
+	params, err := entitySearchParams()
+	require.NoError(t, err)
+	assert.Equal(t, []entities.EntitySearchQueryBuilderTag{
+		{Key: "team", Value: "a,b"},
+		{Key: "env", Value: "prod"},
+	}, params.QueryBuilder.Tags)
+}
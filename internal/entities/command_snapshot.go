@@ -0,0 +1,96 @@
+package entities
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+)
+
+var (
+	snapshotOut   string
+	snapshotLimit int
+)
+
+var cmdEntitySnapshot = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save an inventory of entities to a file",
+	Long: `Save an inventory of entities to a file
+
+The snapshot command captures the entities matching the same search flags as the
+search command, with their tags, alert severity and reporting status, as JSON.
+The search is recorded in the snapshot as an entity search query combining the
+flags given.
+Every matching entity is captured unless --limit is given.  The snapshot is written
+to --out, or printed if no file is given.
+
+Compare two snapshots with the diff command to find the entities added or removed
+between them, and the tags, alert severities and reporting statuses that changed.
+`,
+	Example: `newrelic entity snapshot --query "domain = 'APM'" --out snap.json
+newrelic entity snapshot --domain INFRA --type HOST --tag env:production --out hosts.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !hasEntitySearchFilter() {
+			utils.LogIfError(cmd.Help())
+			log.Fatal("one of --query, --name, --type, --alert-severity, --domain, --tag or --accountId are required")
+		}
+
+		// The filters are always written as a query, so that the snapshot records
+		// exactly the search that was run
+		query, err := entitySearchQuery()
+		utils.LogIfFatal(err)
+
+		params := client.EntitySearchParams{Query: query}
+
+		client.WithClient(func(nrClient *newrelic.NewRelic) {
+			results, err := client.SearchEntities(utils.SignalCtx, &nrClient.NerdGraph, params, snapshotLimit)
+			utils.LogIfFatal(err)
+
+			if len(results.Entities) < results.Count {
+				log.Warnf("capturing %d of %d matching entities", len(results.Entities), results.Count)
+			}
+
+			guids := make([]string, len(results.Entities))
+			for i, e := range results.Entities {
+				guids[i] = string(e.GetGUID())
+			}
+
+			details, err := fetchEntityDetails(utils.SignalCtx, &nrClient.NerdGraph, guids)
+			utils.LogIfFatal(err)
+
+			snapshot := newEntitySnapshot(details, query, time.Now())
+
+			if snapshotOut == "" {
+				utils.LogIfFatal(snapshot.write(os.Stdout))
+				return
+			}
+
+			var b bytes.Buffer
+			utils.LogIfFatal(snapshot.write(&b))
+			utils.LogIfFatal(ioutil.WriteFile(snapshotOut, b.Bytes(), 0640))
+
+			log.Infof("captured %d entities in %s", len(snapshot.Entities), snapshotOut)
+		})
+	},
+}
+
+func init() {
+	Command.AddCommand(cmdEntitySnapshot)
+	cmdEntitySnapshot.Flags().StringVarP(&entityName, "name", "n", "", "capture entities matching the given name")
+	cmdEntitySnapshot.Flags().StringVarP(&entityType, "type", "t", "", "capture entities matching the given type")
+	cmdEntitySnapshot.Flags().StringVarP(&entityAlertSeverity, "alert-severity", "a", "", "capture entities matching the given alert severity type")
+	cmdEntitySnapshot.Flags().StringVarP(&entityReporting, "reporting", "r", "", "capture entities that are or are not reporting (true or false)")
+	cmdEntitySnapshot.Flags().StringVarP(&entityDomain, "domain", "d", "", "capture entities matching the given entity domain")
+	cmdEntitySnapshot.Flags().StringArrayVar(&entityTags, "tag", []string{}, "capture entities with the given key:value tag, repeatable")
+	cmdEntitySnapshot.Flags().StringVarP(&entityQuery, "query", "q", "", "an entity search query, such as \"domain = 'APM'\"")
+	cmdEntitySnapshot.Flags().IntVar(&entitySearchAccountID, "accountId", 0, "capture entities in the given account")
+	cmdEntitySnapshot.Flags().IntVar(&snapshotLimit, "limit", 0, "the maximum number of entities to capture, or 0 for all")
+	cmdEntitySnapshot.Flags().StringVarP(&snapshotOut, "out", "o", "", "the file to write the snapshot to")
+}
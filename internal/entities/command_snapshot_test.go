@@ -0,0 +1,25 @@
+// +build unit
+
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+)
+
+func TestEntitiesSnapshot(t *testing.T) {
+	assert.Equal(t, "snapshot", cmdEntitySnapshot.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdEntitySnapshot)
+	testcobra.CheckCobraRequiredFlags(t, cmdEntitySnapshot, []string{})
+}
+
+func TestEntitiesDiff(t *testing.T) {
+	assert.Equal(t, "diff", cmdEntityDiff.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdEntityDiff)
+	testcobra.CheckCobraRequiredFlags(t, cmdEntityDiff, []string{})
+}
@@ -0,0 +1,102 @@
+package entities
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+)
+
+var (
+	summarySince string
+	summarySort  string
+)
+
+var cmdEntitySummary = &cobra.Command{
+	Use:   "summary",
+	Short: "Summarize the health of one or more entities",
+	Long: `Summarize the health of one or more entities
+
+The summary command shows an entity's type, alert severity, whether it is reporting,
+its tags and its golden signals over the time window given by --since: throughput
+in requests per minute, error rate as a percentage of requests, and average latency
+in milliseconds.  Golden signals are shown for APM applications, browser
+applications and synthetic monitors.
+
+Give an entity with --guid, or summarize the entities matching the same search
+flags as the search command.  Several entities are shown as a table, sorted by
+--sort: name, severity, throughput, errorRate or latency.
+`,
+	Example: `newrelic entity summary --guid <entityGUID>
+newrelic entity summary --domain APM --tag team:payments --since "1 hour ago"
+newrelic entity summary --query "domain = 'APM' AND reporting = 'true'" --sort errorRate`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if entityGUID == "" && !hasEntitySearchFilter() {
+			utils.LogIfError(cmd.Help())
+			log.Fatal("--guid or one of --query, --name, --type, --alert-severity, --domain, --tag or --accountId are required")
+		}
+
+		if entityGUID != "" && hasEntitySearchFilter() {
+			log.Fatal("--guid cannot be combined with search flags")
+		}
+
+		if !isSummarySort(summarySort) {
+			log.Fatalf("--sort must be one of %s", strings.Join(summarySorts, ", "))
+		}
+
+		params, err := entitySearchParams()
+		utils.LogIfFatal(err)
+
+		client.WithClient(func(nrClient *newrelic.NewRelic) {
+			guids := []string{entityGUID}
+
+			if entityGUID == "" {
+				results, err := client.SearchEntities(utils.SignalCtx, &nrClient.NerdGraph, params, entityLimit)
+				utils.LogIfFatal(err)
+
+				if len(results.Entities) < results.Count {
+					log.Infof("summarizing %d of %d matching entities, use --limit to see more", len(results.Entities), results.Count)
+				}
+
+				guids = make([]string, len(results.Entities))
+				for i, e := range results.Entities {
+					guids[i] = string(e.GetGUID())
+				}
+			}
+
+			summaries, err := summarizeEntities(utils.SignalCtx, &nrClient.NerdGraph, guids, summarySince)
+			utils.LogIfFatal(err)
+			utils.LogIfFatal(sortSummaries(summaries, summarySort))
+
+			switch len(summaries) {
+			case 0:
+				log.Fatal("no entities found")
+			case 1:
+				utils.LogIfFatal(output.Print(summaries[0]))
+			default:
+				utils.LogIfFatal(output.Print(summaries))
+			}
+		})
+	},
+}
+
+func init() {
+	Command.AddCommand(cmdEntitySummary)
+	cmdEntitySummary.Flags().StringVarP(&entityGUID, "guid", "g", "", "the GUID of the entity to summarize")
+	cmdEntitySummary.Flags().StringVarP(&entityName, "name", "n", "", "summarize entities matching the given name")
+	cmdEntitySummary.Flags().StringVarP(&entityType, "type", "t", "", "summarize entities matching the given type")
+	cmdEntitySummary.Flags().StringVarP(&entityAlertSeverity, "alert-severity", "a", "", "summarize entities matching the given alert severity type")
+	cmdEntitySummary.Flags().StringVarP(&entityReporting, "reporting", "r", "", "summarize entities that are or are not reporting (true or false)")
+	cmdEntitySummary.Flags().StringVarP(&entityDomain, "domain", "d", "", "summarize entities matching the given entity domain")
+	cmdEntitySummary.Flags().StringArrayVar(&entityTags, "tag", []string{}, "summarize entities with the given key:value tag, repeatable")
+	cmdEntitySummary.Flags().StringVarP(&entityQuery, "query", "q", "", "an entity search query, such as \"domain = 'APM'\"")
+	cmdEntitySummary.Flags().IntVar(&entitySearchAccountID, "accountId", 0, "summarize entities in the given account")
+	cmdEntitySummary.Flags().IntVar(&entityLimit, "limit", client.DefaultEntitySearchLimit, "the maximum number of entities to summarize, or 0 for all")
+	cmdEntitySummary.Flags().StringVar(&summarySince, "since", "30 minutes ago", "the start of the golden signals time window, as a NRQL SINCE value")
+	cmdEntitySummary.Flags().StringVar(&summarySort, "sort", summarySortSeverity, "sort by name, severity, throughput, errorRate or latency")
+}
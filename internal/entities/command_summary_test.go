@@ -0,0 +1,18 @@
+// +build unit
+
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+)
+
+func TestEntitiesSummary(t *testing.T) {
+	assert.Equal(t, "summary", cmdEntitySummary.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdEntitySummary)
+	testcobra.CheckCobraRequiredFlags(t, cmdEntitySummary, []string{})
+}
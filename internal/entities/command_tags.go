@@ -16,7 +16,6 @@ import (
 )
 
 var (
-	entityTag  string
 	entityTags []string
 )
 
@@ -0,0 +1,219 @@
+package entities
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+	"github.com/newrelic/newrelic-client-go/pkg/entities"
+)
+
+var (
+	tagFilePath    string
+	tagDryRun      bool
+	tagConcurrency int
+	tagRate        int
+	tagAssumeYes   bool
+)
+
+var cmdTagsApply = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply the tags listed in a file to many entities",
+	Long: `Apply the tags listed in a file to many entities
+
+The apply command reads a YAML file listing entities, by GUID or by entity search
+query, and the tags they should have:
+
+  entities:
+    - guid: <entityGUID>
+      tags:
+        team: payments
+        env: [production, eu]
+    - query: "domain = 'APM' AND name LIKE 'checkout'"
+      tags:
+        team: payments
+      remove: [owner]
+
+The tag keys listed are set to exactly the values given, adding missing values and
+deleting others, and the keys under remove are deleted.  Other tags are left alone.
+When an entity is matched by several entries, later entries take precedence.
+
+The changes are printed as a plan, and are only applied once you confirm them.
+Use --assumeYes to apply them without asking, which is needed when there is no
+terminal to ask on, or --dry-run to print the plan without applying it.
+
+Entities are tagged --concurrency at a time, making at most --rate requests per
+second, and the outcome for each entity is reported.
+`,
+	Example: `newrelic entity tags apply --file tags.yaml --dry-run
+newrelic entity tags apply --file tags.yaml --assumeYes --concurrency 10 --rate 20`,
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := readTagFile(tagFilePath)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		client.WithClient(func(nrClient *newrelic.NewRelic) {
+			desired, err := resolveTagTargets(f, func(query string) ([]entities.EntityOutlineInterface, error) {
+				page, searchErr := client.SearchEntities(utils.SignalCtx, &nrClient.NerdGraph, client.EntitySearchParams{Query: query}, 0)
+				if searchErr != nil {
+					return nil, searchErr
+				}
+
+				return page.Entities, nil
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			t := &tagger{client: &nrClient.Entities, concurrency: tagConcurrency, rate: tagRate}
+			changes := t.plan(utils.SignalCtx, desired)
+
+			var planOut io.Writer = os.Stderr
+			if tagDryRun {
+				planOut = os.Stdout
+			}
+
+			writeTagPlan(planOut, changes)
+
+			if tagDryRun {
+				return
+			}
+
+			if !tagAssumeYes {
+				interactive := term.IsTerminal(int(os.Stdin.Fd()))
+				ask := func(msg string) (bool, error) { return askYesNo(os.Stdin, os.Stderr, msg) }
+
+				confirmed, confirmErr := confirmTagChanges(ask, interactive, changes)
+				if confirmErr != nil {
+					log.Fatal(confirmErr)
+				}
+
+				if !confirmed {
+					log.Info("no changes applied")
+					return
+				}
+			}
+
+			results := t.apply(utils.SignalCtx, changes)
+			utils.LogIfFatal(output.Print(results))
+
+			failed := 0
+			for _, r := range results {
+				if r.Status == tagStatusFailed {
+					failed++
+				}
+			}
+
+			if failed > 0 {
+				log.Fatalf("%d of %d entities could not be tagged", failed, len(results))
+			}
+		})
+	},
+}
+
+// resolveTagTargets returns the tags each entity listed in the file should
+// have, searching for the entities matched by queries.
+func resolveTagTargets(
+	f *tagFile,
+	search func(query string) ([]entities.EntityOutlineInterface, error),
+) (map[entities.EntityGUID]*desiredTags, error) {
+	desired := map[entities.EntityGUID]*desiredTags{}
+
+	target := func(guid entities.EntityGUID, name string) *desiredTags {
+		d, ok := desired[guid]
+		if !ok {
+			d = newDesiredTags(name)
+			desired[guid] = d
+		}
+
+		if d.name == "" {
+			d.name = name
+		}
+
+		return d
+	}
+
+	for _, e := range f.Entities {
+		if e.GUID != "" {
+			target(entities.EntityGUID(e.GUID), "").merge(e)
+			continue
+		}
+
+		found, err := search(e.Query)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(found) == 0 {
+			log.Warnf("no entities match the query %s", e.Query)
+		}
+
+		for _, entity := range found {
+			target(entity.GetGUID(), entity.GetName()).merge(e)
+		}
+	}
+
+	return desired, nil
+}
+
+// confirmTagChanges asks whether to apply the changes, without asking when
+// none are pending.  Changes are never applied unasked, so it is an error to
+// have pending changes and no terminal to ask on.
+func confirmTagChanges(ask func(msg string) (bool, error), interactive bool, changes []*tagChange) (bool, error) {
+	pending := 0
+	for _, c := range changes {
+		if c.err == nil && !c.empty() {
+			pending++
+		}
+	}
+
+	if pending == 0 {
+		return true, nil
+	}
+
+	if !interactive {
+		return false, errors.New("not applying tag changes without confirmation, use --assumeYes to apply them")
+	}
+
+	return ask(fmt.Sprintf("Apply the changes to %d entities?", pending))
+}
+
+// askYesNo writes the question and reads the answer, taking anything other
+// than yes as no.
+func askYesNo(in io.Reader, out io.Writer, msg string) (bool, error) {
+	fmt.Fprintf(out, "%s [y/N] ", msg)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func init() {
+	cmdTags.AddCommand(cmdTagsApply)
+	cmdTagsApply.Flags().StringVarP(&tagFilePath, "file", "f", "", "a YAML file listing entities and the tags they should have")
+	cmdTagsApply.Flags().BoolVar(&tagDryRun, "dry-run", false, "print the changes without applying them")
+	cmdTagsApply.Flags().BoolVarP(&tagAssumeYes, "assumeYes", "y", false, "apply the changes without asking for confirmation")
+	cmdTagsApply.Flags().IntVar(&tagConcurrency, "concurrency", defaultTagConcurrency, "the number of entities to tag at once")
+	cmdTagsApply.Flags().IntVar(&tagRate, "rate", defaultTagRate, "the most requests to make per second, or 0 for no limit")
+	utils.LogIfError(cmdTagsApply.MarkFlagRequired("file"))
+}
@@ -0,0 +1,96 @@
+// +build unit
+
+package entities
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+	"github.com/newrelic/newrelic-client-go/pkg/entities"
+)
+
+func TestEntitiesApplyTags(t *testing.T) {
+	assert.Equal(t, "apply", cmdTagsApply.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdTagsApply)
+	testcobra.CheckCobraRequiredFlags(t, cmdTagsApply, []string{"file"})
+}
+
+func TestResolveTagTargets(t *testing.T) {
+	f := &tagFile{Entities: []tagFileEntry{
+		{Query: "domain = 'APM'", Tags: map[string]tagValues{"team": {"payments"}, "env": {"production"}}},
+		{GUID: "B", Tags: map[string]tagValues{"team": {"checkout"}}, Remove: []string{"env"}},
+		{GUID: "C", Tags: map[string]tagValues{"team": {"search"}}},
+	}}
+
+	desired, err := resolveTagTargets(f, func(query string) ([]entities.EntityOutlineInterface, error) {
+		assert.Equal(t, "domain = 'APM'", query)
+
+		return []entities.EntityOutlineInterface{
+			&entities.ApmApplicationEntityOutline{GUID: "A", Name: "a"},
+			&entities.ApmApplicationEntityOutline{GUID: "B", Name: "b"},
+		}, nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, desired, 3)
+	assert.Equal(t, "a", desired["A"].name)
+	assert.Equal(t, map[string][]string{"team": {"payments"}, "env": {"production"}}, desired["A"].set)
+	assert.Equal(t, "b", desired["B"].name)
+	assert.Equal(t, map[string][]string{"team": {"checkout"}}, desired["B"].set)
+	assert.Equal(t, map[string]bool{"env": true}, desired["B"].remove)
+	assert.Equal(t, "", desired["C"].name)
+}
+
+func TestConfirmTagChanges(t *testing.T) {
+	pending := []*tagChange{
+		{guid: "A", add: []entities.TaggingTagInput{{Key: "team", Values: []string{"payments"}}}},
+		{guid: "B"},
+		{guid: "C", err: errors.New("not found")},
+	}
+
+	asked := 0
+	answer := true
+	ask := func(msg string) (bool, error) {
+		asked++
+		assert.Equal(t, "Apply the changes to 1 entities?", msg)
+		return answer, nil
+	}
+
+	confirmed, err := confirmTagChanges(ask, true, pending)
+	require.NoError(t, err)
+	assert.True(t, confirmed)
+	assert.Equal(t, 1, asked)
+
+	answer = false
+	confirmed, err = confirmTagChanges(ask, true, pending)
+	require.NoError(t, err)
+	assert.False(t, confirmed)
+
+	asked = 0
+	_, err = confirmTagChanges(ask, false, pending)
+	require.Error(t, err)
+	assert.Equal(t, 0, asked)
+
+	confirmed, err = confirmTagChanges(ask, false, pending[1:])
+	require.NoError(t, err)
+	assert.True(t, confirmed)
+	assert.Equal(t, 0, asked)
+}
+
+func TestAskYesNo(t *testing.T) {
+	for answer, expected := range map[string]bool{"y\n": true, "Yes\n": true, "n\n": false, "\n": false, "": false, "maybe\n": false} {
+		var out bytes.Buffer
+
+		confirmed, err := askYesNo(strings.NewReader(answer), &out, "Apply?")
+		require.NoError(t, err)
+		assert.Equal(t, expected, confirmed, answer)
+		assert.Equal(t, "Apply? [y/N] ", out.String())
+	}
+}
@@ -0,0 +1,108 @@
+package entities
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+	"github.com/newrelic/newrelic-client-go/pkg/entities"
+)
+
+var (
+	tagPolicyPath string
+	tagFix        bool
+)
+
+var cmdTagsAudit = &cobra.Command{
+	Use:   "audit",
+	Short: "Check the tags of entities against a policy",
+	Long: `Check the tags of entities against a policy
+
+The audit command reads a YAML policy of rules.  Each rule selects entities by
+domain, type or entity search query, and lists the tags they must have, the values
+allowed, or a pattern the values must match:
+
+  rules:
+    - name: apm-ownership
+      domain: APM
+      type: APPLICATION
+      tags:
+        team:
+          required: true
+          values: [payments, checkout, search]
+        env:
+          required: true
+          pattern: ^(production|staging|development)$
+          default: production
+          replace:
+            prod: production
+
+The entities each rule selects are searched for and their tags checked.  Each
+violation is reported, in any output format, and an entity whose tags cannot be
+fetched is reported as violating its rules.  With --fix, missing tags that have a
+default are added, and values listed under replace are replaced.  When rules fix the
+same tag of an entity differently, the tag is left alone and the conflict reported.
+The command fails if any violation is left unfixed.
+`,
+	Example: `newrelic entity tags audit --policy policy.yaml
+newrelic entity tags audit --policy policy.yaml --format text
+newrelic entity tags audit --policy policy.yaml --fix`,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := readTagPolicy(tagPolicyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		client.WithClient(func(nrClient *newrelic.NewRelic) {
+			t := &tagger{client: &nrClient.Entities, concurrency: tagConcurrency, rate: tagRate}
+
+			violations, changes, err := auditTags(utils.SignalCtx, p, t, func(query string) ([]entities.EntityOutlineInterface, error) {
+				page, searchErr := client.SearchEntities(utils.SignalCtx, &nrClient.NerdGraph, client.EntitySearchParams{Query: query}, 0)
+				if searchErr != nil {
+					return nil, searchErr
+				}
+
+				return page.Entities, nil
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if tagFix && len(changes) > 0 {
+				results := t.apply(utils.SignalCtx, changes)
+				markFixed(violations, results)
+
+				for _, r := range results {
+					if r.Status == tagStatusFailed {
+						log.Errorf("could not fix the tags of %s: %s", describeEntity(r.GUID, r.Name), r.Error)
+					}
+				}
+			}
+
+			utils.LogIfFatal(output.Print(violations))
+
+			open := 0
+			for _, v := range violations {
+				if !v.Fixed {
+					open++
+				}
+			}
+
+			if open > 0 {
+				log.Fatalf("%d of %d tag violations are unfixed", open, len(violations))
+			}
+		})
+	},
+}
+
+func init() {
+	cmdTags.AddCommand(cmdTagsAudit)
+	cmdTagsAudit.Flags().StringVarP(&tagPolicyPath, "policy", "p", "", "a YAML file of the tag rules entities must follow")
+	cmdTagsAudit.Flags().BoolVar(&tagFix, "fix", false, "add missing tags with a default and replace values listed under replace")
+	cmdTagsAudit.Flags().IntVar(&tagConcurrency, "concurrency", defaultTagConcurrency, "the number of entities to fetch or tag at once")
+	cmdTagsAudit.Flags().IntVar(&tagRate, "rate", defaultTagRate, "the most requests to make per second, or 0 for no limit")
+	utils.LogIfError(cmdTagsAudit.MarkFlagRequired("policy"))
+}
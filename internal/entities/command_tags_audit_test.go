@@ -0,0 +1,18 @@
+// +build unit
+
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+)
+
+func TestEntitiesAuditTags(t *testing.T) {
+	assert.Equal(t, "audit", cmdTagsAudit.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdTagsAudit)
+	testcobra.CheckCobraRequiredFlags(t, cmdTagsAudit, []string{"policy"})
+}
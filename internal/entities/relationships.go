@@ -0,0 +1,310 @@
+package entities
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+)
+
+const (
+	graphFormatJSON    = "json"
+	graphFormatDOT     = "dot"
+	graphFormatMermaid = "mermaid"
+
+	// entitiesPerQuery is the most entities NerdGraph returns from a single
+	// actor.entities query.
+	entitiesPerQuery = 25
+
+	// maxGraphNodes is the most entities in a graph.  Relationships to
+	// further entities are left out, and the walk stops once it is reached.
+	maxGraphNodes = 1000
+)
+
+var graphFormats = []string{graphFormatJSON, graphFormatDOT, graphFormatMermaid}
+
+// graphNode is an entity in a relationship graph.
+type graphNode struct {
+	GUID       string `json:"guid"`
+	Name       string `json:"name,omitempty"`
+	Domain     string `json:"domain,omitempty"`
+	Type       string `json:"type,omitempty"`
+	EntityType string `json:"entityType,omitempty"`
+	Depth      int    `json:"depth"`
+}
+
+// label describes the node, by name if it is known.
+func (n *graphNode) label() string {
+	name := n.Name
+	if name == "" {
+		name = n.GUID
+	}
+
+	if n.Domain == "" && n.Type == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s\n%s", name, strings.TrimSpace(n.Domain+" "+n.Type))
+}
+
+// graphEdge is a relationship between two entities, such as one calling or
+// hosting the other.
+type graphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+// entityGraph is the entities related to a starting entity, within some
+// number of relationships of it.
+type entityGraph struct {
+	Nodes []*graphNode `json:"nodes"`
+	Edges []graphEdge  `json:"edges"`
+
+	index map[string]*graphNode
+	edges map[graphEdge]bool
+}
+
+func newEntityGraph() *entityGraph {
+	return &entityGraph{
+		Nodes: []*graphNode{},
+		Edges: []graphEdge{},
+		index: map[string]*graphNode{},
+		edges: map[graphEdge]bool{},
+	}
+}
+
+// node adds an entity to the graph, filling in any details it was missing.
+// It reports whether the entity is new.
+func (g *entityGraph) node(n relationshipEntity, depth int) (*graphNode, bool) {
+	existing, ok := g.index[n.GUID]
+	if !ok {
+		existing = &graphNode{GUID: n.GUID, EntityType: n.EntityType, Depth: depth}
+		g.index[n.GUID] = existing
+		g.Nodes = append(g.Nodes, existing)
+	}
+
+	if n.Entity != nil {
+		existing.Name = n.Entity.Name
+		existing.Domain = n.Entity.Domain
+		existing.Type = n.Entity.Type
+	}
+
+	if existing.EntityType == "" {
+		existing.EntityType = n.EntityType
+	}
+
+	return existing, !ok
+}
+
+func (g *entityGraph) edge(e graphEdge) {
+	if !g.edges[e] {
+		g.edges[e] = true
+		g.Edges = append(g.Edges, e)
+	}
+}
+
+type relationshipEntity struct {
+	GUID       string `json:"guid"`
+	EntityType string `json:"entityType"`
+	Entity     *struct {
+		Name   string `json:"name"`
+		Domain string `json:"domain"`
+		Type   string `json:"type"`
+	} `json:"entity"`
+}
+
+type entityRelationship struct {
+	Type   string             `json:"type"`
+	Source relationshipEntity `json:"source"`
+	Target relationshipEntity `json:"target"`
+}
+
+type relationshipsResponse struct {
+	Actor struct {
+		Entities []struct {
+			GUID          string               `json:"guid"`
+			Name          string               `json:"name"`
+			Domain        string               `json:"domain"`
+			Type          string               `json:"type"`
+			EntityType    string               `json:"entityType"`
+			Relationships []entityRelationship `json:"relationships"`
+		} `json:"entities"`
+	} `json:"actor"`
+}
+
+const relationshipsQuery = `query($guids: [EntityGuid]!) { actor { entities(guids: $guids) {
+	guid
+	name
+	domain
+	type
+	entityType
+	relationships {
+		type
+		source { guid entityType entity { name domain type } }
+		target { guid entityType entity { name domain type } }
+	}
+} } }`
+
+// walkRelationships returns the graph of entities within depth relationships
+// of the starting entity, in either direction.  Only relationships of the
+// given types are followed, or all of them if none are given.
+func walkRelationships(ctx context.Context, q client.NerdGraphQuerier, guid string, depth int, types []string) (*entityGraph, error) {
+	g := newEntityGraph()
+	g.node(relationshipEntity{GUID: guid}, 0)
+
+	follow := map[string]bool{}
+	for _, t := range types {
+		follow[strings.ToUpper(t)] = true
+	}
+
+	frontier := []string{guid}
+
+	for level := 0; len(frontier) > 0; level++ {
+		next := []string{}
+		dropped := 0
+
+		for start := 0; start < len(frontier); start += entitiesPerQuery {
+			end := start + entitiesPerQuery
+			if end > len(frontier) {
+				end = len(frontier)
+			}
+
+			resp := relationshipsResponse{}
+			vars := map[string]interface{}{"guids": frontier[start:end]}
+
+			if err := q.QueryWithResponseAndContext(ctx, relationshipsQuery, vars, &resp); err != nil {
+				return nil, err
+			}
+
+			if level == 0 && len(resp.Actor.Entities) == 0 {
+				return nil, fmt.Errorf("no entity found with the GUID %s", guid)
+			}
+
+			for _, e := range resp.Actor.Entities {
+				if n, ok := g.index[e.GUID]; ok {
+					n.Name, n.Domain, n.Type, n.EntityType = e.Name, e.Domain, e.Type, e.EntityType
+				}
+
+				for _, r := range e.Relationships {
+					if len(follow) > 0 && !follow[r.Type] {
+						continue
+					}
+
+					added, ok := g.addRelationship(r, level, depth)
+					if !ok {
+						dropped++
+					}
+
+					next = append(next, added...)
+				}
+			}
+		}
+
+		if dropped > 0 || (len(g.Nodes) >= maxGraphNodes && len(next) > 0) {
+			log.Warnf("the graph has reached the limit of %d entities, leaving out %d relationships to further entities "+
+				"and not following the relationships of %d more", maxGraphNodes, dropped, len(next))
+			break
+		}
+
+		frontier = next
+	}
+
+	return g, nil
+}
+
+// addRelationship adds a relationship found at a level of the walk, returning
+// the GUIDs of the entities it added.  Past the depth, only relationships
+// between entities already in the graph are added.  It reports false if the
+// relationship was left out because the graph has no room for its entities.
+func (g *entityGraph) addRelationship(r entityRelationship, level int, depth int) ([]string, bool) {
+	_, hasSource := g.index[r.Source.GUID]
+	_, hasTarget := g.index[r.Target.GUID]
+
+	if level >= depth && (!hasSource || !hasTarget) {
+		return nil, true
+	}
+
+	missing := 0
+	for _, has := range []bool{hasSource, hasTarget} {
+		if !has {
+			missing++
+		}
+	}
+
+	if len(g.Nodes)+missing > maxGraphNodes {
+		return nil, false
+	}
+
+	added := []string{}
+	for _, endpoint := range []relationshipEntity{r.Source, r.Target} {
+		if _, isNew := g.node(endpoint, level+1); isNew {
+			added = append(added, endpoint.GUID)
+		}
+	}
+
+	g.edge(graphEdge{Source: r.Source.GUID, Target: r.Target.GUID, Type: r.Type})
+
+	return added, true
+}
+
+// writeDOT writes the graph in the Graphviz DOT language.
+func (g *entityGraph) writeDOT(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("digraph entities {\n  rankdir=LR;\n  node [shape=box];\n")
+
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s];\n", dotQuote(n.GUID), dotQuote(n.label()))
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(e.Source), dotQuote(e.Target), dotQuote(e.Type))
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+
+	return `"` + s + `"`
+}
+
+// writeMermaid writes the graph as a Mermaid flowchart.
+func (g *entityGraph) writeMermaid(w io.Writer) error {
+	var b strings.Builder
+
+	ids := map[string]string{}
+	b.WriteString("graph LR\n")
+
+	for i, n := range g.Nodes {
+		ids[n.GUID] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[n.GUID], mermaidEscape(n.label()))
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", ids[e.Source], mermaidEscape(e.Type), ids[e.Target])
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func mermaidEscape(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	s = strings.ReplaceAll(s, "|", "#124;")
+
+	return strings.ReplaceAll(s, "\n", "<br/>")
+}
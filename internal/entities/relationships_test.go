@@ -0,0 +1,177 @@
+// +build unit
+
+package entities
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRelationships answers relationship queries from a map of each entity's
+// relationships, as "type source target" strings.
+type mockRelationships struct {
+	relationships map[string][]string
+	queries       int
+}
+
+func (m *mockRelationships) QueryWithResponseAndContext(
+	ctx context.Context,
+	query string,
+	variables map[string]interface{},
+	respBody interface{},
+) error {
+	m.queries++
+
+	entities := []map[string]interface{}{}
+	for _, guid := range variables["guids"].([]string) {
+		rels, ok := m.relationships[guid]
+		if !ok {
+			continue
+		}
+
+		list := []map[string]interface{}{}
+		for _, r := range rels {
+			var relType, source, target string
+			if _, err := fmt.Sscan(r, &relType, &source, &target); err != nil {
+				return err
+			}
+
+			list = append(list, map[string]interface{}{
+				"type":   relType,
+				"source": map[string]interface{}{"guid": source, "entityType": "APM_APPLICATION_ENTITY"},
+				"target": map[string]interface{}{"guid": target, "entityType": "APM_APPLICATION_ENTITY"},
+			})
+		}
+
+		entities = append(entities, map[string]interface{}{
+			"guid":          guid,
+			"name":          "app " + guid,
+			"domain":        "APM",
+			"type":          "APPLICATION",
+			"relationships": list,
+		})
+	}
+
+	out, err := json.Marshal(map[string]interface{}{"actor": map[string]interface{}{"entities": entities}})
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(out, respBody)
+}
+
+func testServiceMap() *mockRelationships {
+	return &mockRelationships{relationships: map[string][]string{
+		"A": {"CALLS A B", "HOSTS H A"},
+		"B": {"CALLS A B", "CALLS B C"},
+		"C": {"CALLS B C", "CALLS C D"},
+		"D": {"CALLS C D"},
+		"H": {"HOSTS H A"},
+	}}
+}
+
+func graphGUIDs(g *entityGraph) []string {
+	guids := []string{}
+	for _, n := range g.Nodes {
+		guids = append(guids, n.GUID)
+	}
+
+	return guids
+}
+
+func TestWalkRelationshipsDepth(t *testing.T) {
+	g, err := walkRelationships(context.Background(), testServiceMap(), "A", 1, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"A", "B", "H"}, graphGUIDs(g))
+	assert.Equal(t, []graphEdge{
+		{Source: "A", Target: "B", Type: "CALLS"},
+		{Source: "H", Target: "A", Type: "HOSTS"},
+	}, g.Edges)
+	assert.Equal(t, "app B", g.index["B"].Name)
+	assert.Equal(t, 1, g.index["B"].Depth)
+
+	g, err = walkRelationships(context.Background(), testServiceMap(), "A", 2, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"A", "B", "H", "C"}, graphGUIDs(g))
+	assert.Len(t, g.Edges, 3)
+	assert.Equal(t, 2, g.index["C"].Depth)
+}
+
+func TestWalkRelationshipsTypes(t *testing.T) {
+	g, err := walkRelationships(context.Background(), testServiceMap(), "A", 5, []string{"calls"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"A", "B", "C", "D"}, graphGUIDs(g))
+	for _, e := range g.Edges {
+		assert.Equal(t, "CALLS", e.Type)
+	}
+}
+
+func TestWalkRelationshipsNotFound(t *testing.T) {
+	_, err := walkRelationships(context.Background(), testServiceMap(), "X", 1, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "X")
+}
+
+func TestWalkRelationshipsBatches(t *testing.T) {
+	m := &mockRelationships{relationships: map[string][]string{}}
+	for i := 0; i < 30; i++ {
+		r := fmt.Sprintf("CALLS root n%d", i)
+		m.relationships["root"] = append(m.relationships["root"], r)
+		m.relationships[fmt.Sprintf("n%d", i)] = []string{r}
+	}
+
+	g, err := walkRelationships(context.Background(), m, "root", 1, nil)
+	require.NoError(t, err)
+
+	assert.Len(t, g.Nodes, 31)
+	assert.Equal(t, 3, m.queries)
+}
+
+func TestWalkRelationshipsMaxNodes(t *testing.T) {
+	// One level of the walk finds far more entities than the limit
+	m := &mockRelationships{relationships: map[string][]string{}}
+	for i := 0; i < 10; i++ {
+		child := fmt.Sprintf("n%d", i)
+		r := fmt.Sprintf("CALLS root %s", child)
+		m.relationships["root"] = append(m.relationships["root"], r)
+		m.relationships[child] = []string{r}
+
+		for j := 0; j < 150; j++ {
+			m.relationships[child] = append(m.relationships[child], fmt.Sprintf("CALLS %s %s-%d", child, child, j))
+		}
+	}
+
+	g, err := walkRelationships(context.Background(), m, "root", 3, nil)
+	require.NoError(t, err)
+
+	assert.Len(t, g.Nodes, maxGraphNodes)
+	for _, e := range g.Edges {
+		assert.Contains(t, g.index, e.Source)
+		assert.Contains(t, g.index, e.Target)
+	}
+}
+
+func TestEntityGraphFormats(t *testing.T) {
+	g, err := walkRelationships(context.Background(), testServiceMap(), "A", 0, nil)
+	require.NoError(t, err)
+
+	g.index["A"].Name = `say "hi"`
+
+	var dot bytes.Buffer
+	require.NoError(t, g.writeDOT(&dot))
+	assert.Equal(t, "digraph entities {\n  rankdir=LR;\n  node [shape=box];\n"+
+		`  "A" [label="say \"hi\"\nAPM APPLICATION"];`+"\n}\n", dot.String())
+
+	var mermaid bytes.Buffer
+	require.NoError(t, g.writeMermaid(&mermaid))
+	assert.Equal(t, "graph LR\n  n0[\"say #quot;hi#quot;<br/>APM APPLICATION\"]\n", mermaid.String())
+}
@@ -0,0 +1,176 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	snapshotChangeAdded   = "added"
+	snapshotChangeRemoved = "removed"
+	snapshotChangeChanged = "changed"
+)
+
+// entitySnapshot is an inventory of entities, their tags and the attributes
+// that show how they are doing, at a point in time.
+type entitySnapshot struct {
+	TakenAt  time.Time        `json:"takenAt"`
+	Query    string           `json:"query,omitempty"`
+	Entities []snapshotEntity `json:"entities"`
+}
+
+type snapshotEntity struct {
+	GUID          string              `json:"guid"`
+	Name          string              `json:"name"`
+	AccountID     int                 `json:"accountId"`
+	Domain        string              `json:"domain"`
+	Type          string              `json:"type"`
+	AlertSeverity string              `json:"alertSeverity,omitempty"`
+	Reporting     bool                `json:"reporting"`
+	Tags          map[string][]string `json:"tags"`
+}
+
+// snapshotChange is a difference between two snapshots: an entity that was
+// added or removed, or an attribute or tag of an entity that changed.
+type snapshotChange struct {
+	GUID   string `json:"guid"`
+	Name   string `json:"name"`
+	Change string `json:"change"`
+	Field  string `json:"field,omitempty"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// newEntitySnapshot returns a snapshot of the entities, sorted by GUID so
+// that snapshots of the same entities compare cleanly.
+func newEntitySnapshot(details []entityDetails, query string, takenAt time.Time) *entitySnapshot {
+	s := &entitySnapshot{
+		TakenAt:  takenAt.UTC(),
+		Query:    query,
+		Entities: make([]snapshotEntity, len(details)),
+	}
+
+	for i, d := range details {
+		e := snapshotEntity{
+			GUID:          d.GUID,
+			Name:          d.Name,
+			AccountID:     d.AccountID,
+			Domain:        d.Domain,
+			Type:          d.Type,
+			AlertSeverity: d.AlertSeverity,
+			Reporting:     d.Reporting,
+			Tags:          map[string][]string{},
+		}
+
+		for _, t := range d.Tags {
+			values := append([]string{}, t.Values...)
+			sort.Strings(values)
+			e.Tags[t.Key] = values
+		}
+
+		s.Entities[i] = e
+	}
+
+	sort.Slice(s.Entities, func(i, j int) bool { return s.Entities[i].GUID < s.Entities[j].GUID })
+
+	return s
+}
+
+func (s *entitySnapshot) write(w io.Writer) error {
+	out, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(out, '\n'))
+
+	return err
+}
+
+func readEntitySnapshot(path string) (*entitySnapshot, error) {
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s entitySnapshot
+	if err = json.Unmarshal(out, &s); err != nil {
+		return nil, fmt.Errorf("could not read the snapshot %s: %s", path, err)
+	}
+
+	return &s, nil
+}
+
+// diffSnapshots returns the entities added and removed between two
+// snapshots, and the changes to the name, alert severity, reporting status
+// and tags of the entities in both.  Changes are ordered by entity GUID.
+func diffSnapshots(older *entitySnapshot, newer *entitySnapshot) []snapshotChange {
+	before := map[string]snapshotEntity{}
+	after := map[string]snapshotEntity{}
+	guids := []string{}
+
+	for _, e := range older.Entities {
+		before[e.GUID] = e
+		guids = append(guids, e.GUID)
+	}
+
+	for _, e := range newer.Entities {
+		after[e.GUID] = e
+		if _, ok := before[e.GUID]; !ok {
+			guids = append(guids, e.GUID)
+		}
+	}
+
+	sort.Strings(guids)
+
+	changes := []snapshotChange{}
+
+	for _, guid := range guids {
+		b, inBefore := before[guid]
+		a, inAfter := after[guid]
+
+		switch {
+		case !inBefore:
+			changes = append(changes, snapshotChange{GUID: guid, Name: a.Name, Change: snapshotChangeAdded})
+			continue
+		case !inAfter:
+			changes = append(changes, snapshotChange{GUID: guid, Name: b.Name, Change: snapshotChangeRemoved})
+			continue
+		}
+
+		changed := func(field string, from string, to string) {
+			if from != to {
+				changes = append(changes, snapshotChange{GUID: guid, Name: a.Name, Change: snapshotChangeChanged, Field: field, Old: from, New: to})
+			}
+		}
+
+		changed("name", b.Name, a.Name)
+		changed("alertSeverity", b.AlertSeverity, a.AlertSeverity)
+		changed("reporting", strconv.FormatBool(b.Reporting), strconv.FormatBool(a.Reporting))
+
+		keys := []string{}
+		for key := range b.Tags {
+			keys = append(keys, key)
+		}
+
+		for key := range a.Tags {
+			if _, ok := b.Tags[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			changed("tags."+key, strings.Join(b.Tags[key], ","), strings.Join(a.Tags[key], ","))
+		}
+	}
+
+	return changes
+}
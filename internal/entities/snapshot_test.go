@@ -0,0 +1,72 @@
+// +build unit
+
+package entities
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEntitySnapshot(t *testing.T) {
+	details, err := fetchEntityDetails(context.Background(), testSummaryQuerier(), []string{"HOST1", "APP2", "APP1"})
+	require.NoError(t, err)
+
+	takenAt := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := newEntitySnapshot(details, "domain = 'APM'", takenAt)
+
+	require.Len(t, s.Entities, 3)
+	assert.Equal(t, "APP1", s.Entities[0].GUID)
+	assert.Equal(t, "HOST1", s.Entities[2].GUID)
+	assert.Equal(t, map[string][]string{"team": {"payments"}}, s.Entities[0].Tags)
+	assert.Equal(t, map[string][]string{}, s.Entities[1].Tags)
+
+	// A snapshot reads back as it was written
+	var b bytes.Buffer
+	require.NoError(t, s.write(&b))
+
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := writeTempFile(t, dir, "snapshot.json", b.String())
+
+	read, err := readEntitySnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, s, read)
+
+	_, err = readEntitySnapshot(writeTempFile(t, dir, "bad.json", "{"))
+	require.Error(t, err)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	older := &entitySnapshot{Entities: []snapshotEntity{
+		{GUID: "A", Name: "checkout", AlertSeverity: "NOT_ALERTING", Reporting: true, Tags: map[string][]string{"team": {"payments"}}},
+		{GUID: "B", Name: "billing", Reporting: true},
+		{GUID: "C", Name: "web-1", Reporting: true, Tags: map[string][]string{"env": {"prod"}, "owner": {"ops"}}},
+	}}
+
+	newer := &entitySnapshot{Entities: []snapshotEntity{
+		{GUID: "A", Name: "checkout", AlertSeverity: "CRITICAL", Reporting: true, Tags: map[string][]string{"team": {"payments"}}},
+		{GUID: "C", Name: "web-1", Reporting: false, Tags: map[string][]string{"env": {"eu", "prod"}, "team": {"infra"}}},
+		{GUID: "D", Name: "search"},
+	}}
+
+	assert.Equal(t, []snapshotChange{
+		{GUID: "A", Name: "checkout", Change: snapshotChangeChanged, Field: "alertSeverity", Old: "NOT_ALERTING", New: "CRITICAL"},
+		{GUID: "B", Name: "billing", Change: snapshotChangeRemoved},
+		{GUID: "C", Name: "web-1", Change: snapshotChangeChanged, Field: "reporting", Old: "true", New: "false"},
+		{GUID: "C", Name: "web-1", Change: snapshotChangeChanged, Field: "tags.env", Old: "prod", New: "eu,prod"},
+		{GUID: "C", Name: "web-1", Change: snapshotChangeChanged, Field: "tags.owner", Old: "ops"},
+		{GUID: "C", Name: "web-1", Change: snapshotChangeChanged, Field: "tags.team", New: "infra"},
+		{GUID: "D", Name: "search", Change: snapshotChangeAdded},
+	}, diffSnapshots(older, newer))
+
+	assert.Empty(t, diffSnapshots(older, older))
+}
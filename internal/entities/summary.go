@@ -0,0 +1,343 @@
+package entities
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+)
+
+const (
+	// guidsPerNRQLQuery is the most entities whose golden signals are fetched
+	// with a single NRQL query.
+	guidsPerNRQLQuery = 50
+
+	summarySortName       = "name"
+	summarySortSeverity   = "severity"
+	summarySortThroughput = "throughput"
+	summarySortErrorRate  = "errorRate"
+	summarySortLatency    = "latency"
+)
+
+var summarySorts = []string{summarySortName, summarySortSeverity, summarySortThroughput, summarySortErrorRate, summarySortLatency}
+
+// goldenSignalQueries select the throughput per minute, the percentage of
+// errors and the average latency in milliseconds of entities, by entity
+// domain and type.  Entities of other types have no golden signals.
+var goldenSignalQueries = map[string]string{
+	"APM/APPLICATION": "SELECT rate(count(*), 1 minute) AS 'throughput', percentage(count(*), WHERE error IS true) AS 'errorRate', " +
+		"average(duration) * 1000 AS 'latency' FROM Transaction",
+	"BROWSER/APPLICATION": "SELECT rate(count(*), 1 minute) AS 'throughput', average(duration) * 1000 AS 'latency' FROM PageView",
+	"SYNTH/MONITOR": "SELECT rate(count(*), 1 minute) AS 'throughput', percentage(count(*), WHERE result = 'FAILED') AS 'errorRate', " +
+		"average(duration) AS 'latency' FROM SyntheticCheck",
+}
+
+// severityRank orders alert severities from the most to the least severe.
+var severityRank = map[string]int{
+	"CRITICAL":       0,
+	"WARNING":        1,
+	"NOT_ALERTING":   2,
+	"NOT_CONFIGURED": 3,
+}
+
+// entitySummary is what an entity is and how it is doing: its alert
+// severity, whether it is reporting, and its golden signals over a time
+// window.  Signals are left empty when the entity has no data for them.
+type entitySummary struct {
+	GUID          string `json:"guid"`
+	Name          string `json:"name"`
+	AccountID     int    `json:"accountId"`
+	Domain        string `json:"domain"`
+	Type          string `json:"type"`
+	AlertSeverity string `json:"alertSeverity,omitempty"`
+	Reporting     bool   `json:"reporting"`
+	// Throughput is the number of requests per minute.
+	Throughput *float64 `json:"throughput,omitempty"`
+	// ErrorRate is the percentage of requests that failed.
+	ErrorRate *float64 `json:"errorRate,omitempty"`
+	// Latency is the average duration of requests in milliseconds.
+	Latency *float64 `json:"latency,omitempty"`
+	// Tags has the values of each tag, separated by commas.
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// entityDetails are the attributes of an entity that describe what it is and
+// how it is doing.
+type entityDetails struct {
+	GUID          string `json:"guid"`
+	Name          string `json:"name"`
+	AccountID     int    `json:"accountId"`
+	Domain        string `json:"domain"`
+	Type          string `json:"type"`
+	AlertSeverity string `json:"alertSeverity"`
+	Reporting     bool   `json:"reporting"`
+	Tags          []struct {
+		Key    string   `json:"key"`
+		Values []string `json:"values"`
+	} `json:"tags"`
+}
+
+type entityDetailsResponse struct {
+	Actor struct {
+		Entities []entityDetails `json:"entities"`
+	} `json:"actor"`
+}
+
+const entityDetailsQuery = `query($guids: [EntityGuid]!) { actor { entities(guids: $guids) {
+	guid
+	name
+	accountId
+	domain
+	type
+	reporting
+	tags { key values }
+	... on AlertableEntity { alertSeverity }
+} } }`
+
+type nrqlResponse struct {
+	Actor struct {
+		Account struct {
+			NRQL struct {
+				Results []map[string]interface{} `json:"results"`
+			} `json:"nrql"`
+		} `json:"account"`
+	} `json:"actor"`
+}
+
+const nrqlQuery = `query($accountId: Int!, $query: Nrql!) { actor { account(id: $accountId) { nrql(query: $query) { results } } } }`
+
+// fetchEntityDetails returns the details of each entity found, in the order
+// given.  Entities that are not found are skipped with a warning.
+func fetchEntityDetails(ctx context.Context, q client.NerdGraphQuerier, guids []string) ([]entityDetails, error) {
+	details := []entityDetails{}
+
+	for start := 0; start < len(guids); start += entitiesPerQuery {
+		end := start + entitiesPerQuery
+		if end > len(guids) {
+			end = len(guids)
+		}
+
+		resp := entityDetailsResponse{}
+		vars := map[string]interface{}{"guids": guids[start:end]}
+
+		if err := q.QueryWithResponseAndContext(ctx, entityDetailsQuery, vars, &resp); err != nil {
+			return nil, err
+		}
+
+		details = append(details, resp.Actor.Entities...)
+	}
+
+	if len(details) < len(guids) {
+		found := map[string]bool{}
+		for _, d := range details {
+			found[d.GUID] = true
+		}
+
+		for _, guid := range guids {
+			if !found[guid] {
+				log.Warnf("no entity found with the GUID %s", guid)
+			}
+		}
+	}
+
+	return details, nil
+}
+
+// summarizeEntities returns the summary of each entity found, in the order
+// given, with golden signals since the given NRQL SINCE value.  Golden
+// signals that cannot be fetched are left empty with a warning.
+func summarizeEntities(ctx context.Context, q client.NerdGraphQuerier, guids []string, since string) ([]*entitySummary, error) {
+	details, err := fetchEntityDetails(ctx, q, guids)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*entitySummary, len(details))
+
+	for i, e := range details {
+		summaries[i] = &entitySummary{
+			GUID:          e.GUID,
+			Name:          e.Name,
+			AccountID:     e.AccountID,
+			Domain:        e.Domain,
+			Type:          e.Type,
+			AlertSeverity: e.AlertSeverity,
+			Reporting:     e.Reporting,
+			Tags:          map[string]string{},
+		}
+
+		for _, t := range e.Tags {
+			summaries[i].Tags[t.Key] = strings.Join(t.Values, ",")
+		}
+	}
+
+	// Golden signals are fetched for each account and kind of entity at once
+	type group struct {
+		accountID int
+		kind      string
+	}
+
+	groups := map[group][]*entitySummary{}
+	order := []group{}
+
+	for _, s := range summaries {
+		g := group{accountID: s.AccountID, kind: s.Domain + "/" + s.Type}
+		if _, ok := goldenSignalQueries[g.kind]; !ok {
+			continue
+		}
+
+		if _, ok := groups[g]; !ok {
+			order = append(order, g)
+		}
+
+		groups[g] = append(groups[g], s)
+	}
+
+	for _, g := range order {
+		members := groups[g]
+
+		for start := 0; start < len(members); start += guidsPerNRQLQuery {
+			end := start + guidsPerNRQLQuery
+			if end > len(members) {
+				end = len(members)
+			}
+
+			if err := fetchGoldenSignals(ctx, q, g.accountID, goldenSignalQueries[g.kind], members[start:end], since); err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+
+				log.Warnf("could not fetch the golden signals of %d %s entities in account %d: %s", end-start, g.kind, g.accountID, err)
+			}
+		}
+	}
+
+	return summaries, nil
+}
+
+// fetchGoldenSignals fills in the golden signals of entities in one account,
+// faceting the query by entity GUID.
+func fetchGoldenSignals(ctx context.Context, q client.NerdGraphQuerier, accountID int, query string, members []*entitySummary, since string) error {
+	quoted := make([]string, len(members))
+	byGUID := map[string]*entitySummary{}
+
+	for i, s := range members {
+		quoted[i] = "'" + s.GUID + "'"
+		byGUID[s.GUID] = s
+	}
+
+	nrql := fmt.Sprintf("%s WHERE entityGuid IN (%s) FACET entityGuid SINCE %s LIMIT MAX", query, strings.Join(quoted, ", "), since)
+
+	resp := nrqlResponse{}
+	vars := map[string]interface{}{"accountId": accountID, "query": nrql}
+
+	if err := q.QueryWithResponseAndContext(ctx, nrqlQuery, vars, &resp); err != nil {
+		return err
+	}
+
+	for _, r := range resp.Actor.Account.NRQL.Results {
+		guid, _ := r["entityGuid"].(string)
+
+		s, ok := byGUID[guid]
+		if !ok {
+			continue
+		}
+
+		s.Throughput = roundedValue(r["throughput"])
+		s.ErrorRate = roundedValue(r["errorRate"])
+		s.Latency = roundedValue(r["latency"])
+	}
+
+	return nil
+}
+
+// roundedValue returns a numeric result rounded to two decimal places, or
+// nil if the result is missing.
+func roundedValue(v interface{}) *float64 {
+	f, ok := v.(float64)
+	if !ok || math.IsNaN(f) {
+		return nil
+	}
+
+	f = math.Round(f*100) / 100
+
+	return &f
+}
+
+// sortSummaries sorts entities by name, by alert severity with the most
+// severe first, or by a golden signal with the highest first.  Entities
+// without the signal come last, and ties are sorted by name.
+func sortSummaries(summaries []*entitySummary, by string) error {
+	// compare returns a negative number when a comes before b, a positive
+	// number when it comes after, and zero for a tie
+	var compare func(a, b *entitySummary) int
+
+	signal := func(value func(s *entitySummary) *float64) func(a, b *entitySummary) int {
+		return func(a, b *entitySummary) int {
+			va, vb := value(a), value(b)
+
+			switch {
+			case va == nil && vb == nil:
+				return 0
+			case va == nil:
+				return 1
+			case vb == nil:
+				return -1
+			case *va > *vb:
+				return -1
+			case *va < *vb:
+				return 1
+			}
+
+			return 0
+		}
+	}
+
+	switch strings.ToLower(by) {
+	case strings.ToLower(summarySortName):
+		compare = func(a, b *entitySummary) int { return 0 }
+	case strings.ToLower(summarySortSeverity):
+		compare = func(a, b *entitySummary) int { return severityLevel(a.AlertSeverity) - severityLevel(b.AlertSeverity) }
+	case strings.ToLower(summarySortThroughput):
+		compare = signal(func(s *entitySummary) *float64 { return s.Throughput })
+	case strings.ToLower(summarySortErrorRate):
+		compare = signal(func(s *entitySummary) *float64 { return s.ErrorRate })
+	case strings.ToLower(summarySortLatency):
+		compare = signal(func(s *entitySummary) *float64 { return s.Latency })
+	default:
+		return fmt.Errorf("cannot sort by %s, use one of %s", by, strings.Join(summarySorts, ", "))
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if c := compare(summaries[i], summaries[j]); c != 0 {
+			return c < 0
+		}
+
+		return strings.ToLower(summaries[i].Name) < strings.ToLower(summaries[j].Name)
+	})
+
+	return nil
+}
+
+func isSummarySort(by string) bool {
+	for _, s := range summarySorts {
+		if strings.EqualFold(s, by) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func severityLevel(severity string) int {
+	if rank, ok := severityRank[severity]; ok {
+		return rank
+	}
+
+	return len(severityRank)
+}
@@ -0,0 +1,165 @@
+// +build unit
+
+package entities
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSummaryQuerier answers entity detail queries from a list of entities,
+// and NRQL queries with fixed results.
+type mockSummaryQuerier struct {
+	entities []map[string]interface{}
+	results  []map[string]interface{}
+	nrqlErr  error
+	nrql     []map[string]interface{}
+}
+
+func (m *mockSummaryQuerier) QueryWithResponseAndContext(
+	ctx context.Context,
+	query string,
+	variables map[string]interface{},
+	respBody interface{},
+) error {
+	var resp interface{}
+
+	if query == nrqlQuery {
+		m.nrql = append(m.nrql, variables)
+		if m.nrqlErr != nil {
+			return m.nrqlErr
+		}
+
+		resp = map[string]interface{}{"actor": map[string]interface{}{
+			"account": map[string]interface{}{"nrql": map[string]interface{}{"results": m.results}},
+		}}
+	} else {
+		wanted := stringSet(variables["guids"].([]string))
+		found := []map[string]interface{}{}
+
+		for _, e := range m.entities {
+			if wanted[e["guid"].(string)] {
+				found = append(found, e)
+			}
+		}
+
+		resp = map[string]interface{}{"actor": map[string]interface{}{"entities": found}}
+	}
+
+	out, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(out, respBody)
+}
+
+func testSummaryQuerier() *mockSummaryQuerier {
+	return &mockSummaryQuerier{
+		entities: []map[string]interface{}{
+			{
+				"guid": "APP1", "name": "checkout", "accountId": 1, "domain": "APM", "type": "APPLICATION",
+				"alertSeverity": "NOT_ALERTING", "reporting": true,
+				"tags": []map[string]interface{}{{"key": "team", "values": []string{"payments"}}},
+			},
+			{
+				"guid": "APP2", "name": "billing", "accountId": 1, "domain": "APM", "type": "APPLICATION",
+				"alertSeverity": "CRITICAL", "reporting": true,
+			},
+			{
+				"guid": "HOST1", "name": "web-1", "accountId": 1, "domain": "INFRA", "type": "HOST",
+				"alertSeverity": "WARNING", "reporting": false,
+			},
+		},
+		results: []map[string]interface{}{
+			{"entityGuid": "APP1", "throughput": 120.5, "errorRate": 0.256, "latency": 85.333},
+			{"entityGuid": "APP2", "throughput": 30.0, "errorRate": 12.5, "latency": 900.0},
+		},
+	}
+}
+
+func TestSummarizeEntities(t *testing.T) {
+	q := testSummaryQuerier()
+
+	summaries, err := summarizeEntities(context.Background(), q, []string{"APP1", "APP2", "HOST1", "MISSING"}, "30 minutes ago")
+	require.NoError(t, err)
+	require.Len(t, summaries, 3)
+
+	app := summaries[0]
+	assert.Equal(t, "checkout", app.Name)
+	assert.Equal(t, "NOT_ALERTING", app.AlertSeverity)
+	assert.Equal(t, map[string]string{"team": "payments"}, app.Tags)
+	require.NotNil(t, app.Throughput)
+	assert.Equal(t, 120.5, *app.Throughput)
+	assert.Equal(t, 0.26, *app.ErrorRate)
+	assert.Equal(t, 85.33, *app.Latency)
+
+	host := summaries[2]
+	assert.False(t, host.Reporting)
+	assert.Nil(t, host.Throughput)
+
+	// Both applications are fetched with one query, and the host not at all
+	require.Len(t, q.nrql, 1)
+	assert.Equal(t, 1, q.nrql[0]["accountId"])
+	assert.Contains(t, q.nrql[0]["query"], "FROM Transaction WHERE entityGuid IN ('APP1', 'APP2') FACET entityGuid SINCE 30 minutes ago")
+}
+
+func TestEntityDetailsQuery(t *testing.T) {
+	// alertSeverity is only a field of alertable entities, not of every entity
+	assert.Contains(t, entityDetailsQuery, "... on AlertableEntity { alertSeverity }")
+	assert.Equal(t, 1, strings.Count(entityDetailsQuery, "alertSeverity"))
+}
+
+func TestSummarizeEntitiesNRQLError(t *testing.T) {
+	q := testSummaryQuerier()
+	q.nrqlErr = errors.New("bad query")
+
+	summaries, err := summarizeEntities(context.Background(), q, []string{"APP1"}, "1 hour ago")
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Nil(t, summaries[0].Throughput)
+}
+
+func TestSortSummaries(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+
+	summaries := func() []*entitySummary {
+		return []*entitySummary{
+			{Name: "b", AlertSeverity: "NOT_ALERTING", Throughput: f(10)},
+			{Name: "a", AlertSeverity: "WARNING"},
+			{Name: "c", AlertSeverity: "CRITICAL", Throughput: f(5)},
+			{Name: "d", AlertSeverity: "NOT_ALERTING", Throughput: f(10)},
+		}
+	}
+
+	names := func(s []*entitySummary) []string {
+		n := []string{}
+		for _, e := range s {
+			n = append(n, e.Name)
+		}
+
+		return n
+	}
+
+	s := summaries()
+	require.NoError(t, sortSummaries(s, "name"))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, names(s))
+
+	s = summaries()
+	require.NoError(t, sortSummaries(s, "severity"))
+	assert.Equal(t, []string{"c", "a", "b", "d"}, names(s))
+
+	s = summaries()
+	require.NoError(t, sortSummaries(s, "THROUGHPUT"))
+	assert.Equal(t, []string{"b", "d", "c", "a"}, names(s))
+
+	assert.Error(t, sortSummaries(s, "size"))
+	assert.True(t, isSummarySort("errorrate"))
+	assert.False(t, isSummarySort("size"))
+}
@@ -0,0 +1,102 @@
+package entities
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// tagFile describes the tags entities should have.  Each entry selects
+// entities by GUID or by entity search query.
+//
+//   entities:
+//     - guid: MXxBUE18QVBQTElDQVRJT058MTIz
+//       tags:
+//         team: payments
+//         env: [production, eu]
+//     - query: "domain = 'APM' AND name LIKE 'checkout'"
+//       tags:
+//         team: payments
+//       remove: [owner]
+type tagFile struct {
+	Entities []tagFileEntry `yaml:"entities"`
+}
+
+// tagFileEntry gives the values the listed tag keys should have, replacing
+// any other values of those keys, and the tag keys to remove.  Keys that are
+// not listed are left alone.
+type tagFileEntry struct {
+	GUID   string               `yaml:"guid"`
+	Query  string               `yaml:"query"`
+	Tags   map[string]tagValues `yaml:"tags"`
+	Remove []string             `yaml:"remove"`
+}
+
+// tagValues are the values of a tag, given as a single value or a list.
+type tagValues []string
+
+func (v *tagValues) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*v = list
+		return nil
+	}
+
+	var single string
+	if err := unmarshal(&single); err != nil {
+		return err
+	}
+
+	*v = tagValues{single}
+
+	return nil
+}
+
+func readTagFile(path string) (*tagFile, error) {
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f tagFile
+	if err = yaml.UnmarshalStrict(out, &f); err != nil {
+		return nil, fmt.Errorf("could not read %s: %s", path, err)
+	}
+
+	if err = f.validate(); err != nil {
+		return nil, fmt.Errorf("could not read %s: %s", path, err)
+	}
+
+	return &f, nil
+}
+
+func (f *tagFile) validate() error {
+	if len(f.Entities) == 0 {
+		return fmt.Errorf("no entities are listed")
+	}
+
+	for i, e := range f.Entities {
+		if (e.GUID == "") == (e.Query == "") {
+			return fmt.Errorf("entry %d must have either a guid or a query", i+1)
+		}
+
+		if len(e.Tags) == 0 && len(e.Remove) == 0 {
+			return fmt.Errorf("entry %d has no tags to set or remove", i+1)
+		}
+
+		for _, key := range e.Remove {
+			if _, ok := e.Tags[key]; ok {
+				return fmt.Errorf("entry %d both sets and removes the tag %s", i+1, key)
+			}
+		}
+
+		for key, values := range e.Tags {
+			if len(values) == 0 {
+				return fmt.Errorf("entry %d has no values for the tag %s, list it under remove to delete it", i+1, key)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+//go:build unit
+// +build unit
+
+package entities
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTempFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	return path
+}
+
+func TestReadTagFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tags")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := writeTempFile(t, dir, "tags.yaml", `
+entities:
+  - guid: ABC
+    tags:
+      team: payments
+      env: [production, eu]
+  - query: "domain = 'APM'"
+    remove: [owner]
+`)
+
+	f, err := readTagFile(path)
+	require.NoError(t, err)
+	require.Len(t, f.Entities, 2)
+	assert.Equal(t, tagValues{"payments"}, f.Entities[0].Tags["team"])
+	assert.Equal(t, tagValues{"production", "eu"}, f.Entities[0].Tags["env"])
+	assert.Equal(t, "domain = 'APM'", f.Entities[1].Query)
+	assert.Equal(t, []string{"owner"}, f.Entities[1].Remove)
+}
+
+func TestReadTagFile_Invalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tags")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	invalid := map[string]string{
+		"entities: []":                                        "no entities are listed",
+		"entities:\n  - tags: {a: b}":                         "entry 1 must have either a guid or a query",
+		"entities:\n  - {guid: A, query: q, tags: {a: b}}":    "entry 1 must have either a guid or a query",
+		"entities:\n  - guid: A":                              "entry 1 has no tags to set or remove",
+		"entities:\n  - {guid: A, tags: {a: b}, remove: [a]}": "entry 1 both sets and removes the tag a",
+		"entities:\n  - {guid: A, tags: {a: []}}":             "entry 1 has no values for the tag a, list it under remove to delete it",
+	}
+
+	for content, expected := range invalid {
+		_, readErr := readTagFile(writeTempFile(t, dir, "tags.yaml", content))
+		assert.EqualError(t, readErr, "could not read "+filepath.Join(dir, "tags.yaml")+": "+expected, content)
+	}
+
+	_, err = readTagFile(writeTempFile(t, dir, "tags.yaml", "entities:\n  - {guid: A, tag: {a: b}}"))
+	assert.Error(t, err)
+}
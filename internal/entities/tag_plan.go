@@ -0,0 +1,380 @@
+package entities
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/newrelic/newrelic-client-go/pkg/entities"
+)
+
+const (
+	// defaultTagConcurrency is the default number of entities tagged at once.
+	defaultTagConcurrency = 5
+
+	// defaultTagRate is the default number of tagging requests per second.
+	defaultTagRate = 10
+
+	tagStatusUpdated   = "updated"
+	tagStatusUnchanged = "unchanged"
+	tagStatusFailed    = "failed"
+)
+
+// taggingClient reads and changes the tags on entities.
+type taggingClient interface {
+	GetTagsForEntity(guid entities.EntityGUID) ([]*entities.EntityTag, error)
+	TaggingAddTagsToEntity(guid entities.EntityGUID, tags []entities.TaggingTagInput) (*entities.TaggingMutationResult, error)
+	TaggingDeleteTagFromEntity(guid entities.EntityGUID, tagKeys []string) (*entities.TaggingMutationResult, error)
+	TaggingDeleteTagValuesFromEntity(
+		guid entities.EntityGUID,
+		tagValues []entities.TaggingTagValueInput,
+	) (*entities.TaggingMutationResult, error)
+}
+
+// desiredTags are the values tag keys should have on an entity, and the keys
+// it should not have.
+type desiredTags struct {
+	name   string
+	set    map[string][]string
+	remove map[string]bool
+}
+
+func newDesiredTags(name string) *desiredTags {
+	return &desiredTags{name: name, set: map[string][]string{}, remove: map[string]bool{}}
+}
+
+// merge adds the tags of an entry.  An entry listed later overrides the keys
+// it shares with those before it.
+func (d *desiredTags) merge(e tagFileEntry) {
+	for key, values := range e.Tags {
+		d.set[key] = values
+		delete(d.remove, key)
+	}
+
+	for _, key := range e.Remove {
+		d.remove[key] = true
+		delete(d.set, key)
+	}
+}
+
+// tagChange is what needs to change for an entity to have its desired tags.
+type tagChange struct {
+	guid         entities.EntityGUID
+	name         string
+	add          []entities.TaggingTagInput
+	deleteValues []entities.TaggingTagValueInput
+	deleteKeys   []string
+	err          error
+}
+
+func (c *tagChange) empty() bool {
+	return len(c.add) == 0 && len(c.deleteValues) == 0 && len(c.deleteKeys) == 0
+}
+
+func (c *tagChange) added() int {
+	n := 0
+	for _, t := range c.add {
+		n += len(t.Values)
+	}
+
+	return n
+}
+
+func (c *tagChange) removed() int {
+	return len(c.deleteValues) + len(c.deleteKeys)
+}
+
+// planTagChange compares an entity's tags with those it should have.  Values
+// missing from a key are added, values of the key that should not be there
+// are deleted, and keys that should be removed are deleted entirely.
+func planTagChange(guid entities.EntityGUID, current []*entities.EntityTag, desired *desiredTags) *tagChange {
+	c := &tagChange{guid: guid, name: desired.name}
+
+	existing := map[string][]string{}
+	for _, t := range current {
+		if t != nil {
+			existing[t.Key] = t.Values
+		}
+	}
+
+	keys := make([]string, 0, len(desired.set))
+	for key := range desired.set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		have := stringSet(existing[key])
+		want := stringSet(desired.set[key])
+
+		missing := []string{}
+		for _, v := range desired.set[key] {
+			if !have[v] {
+				missing = append(missing, v)
+				have[v] = true
+			}
+		}
+
+		if len(missing) > 0 {
+			c.add = append(c.add, entities.TaggingTagInput{Key: key, Values: missing})
+		}
+
+		for _, v := range existing[key] {
+			if !want[v] {
+				c.deleteValues = append(c.deleteValues, entities.TaggingTagValueInput{Key: key, Value: v})
+			}
+		}
+	}
+
+	for key := range desired.remove {
+		if _, ok := existing[key]; ok {
+			c.deleteKeys = append(c.deleteKeys, key)
+		}
+	}
+	sort.Strings(c.deleteKeys)
+
+	return c
+}
+
+// writeTagPlan writes the changes as a diff, skipping entities that need none.
+func writeTagPlan(w io.Writer, changes []*tagChange) {
+	pending := 0
+
+	for _, c := range changes {
+		if c.err != nil {
+			fmt.Fprintf(w, "! %s: %s\n", describeEntity(c.guid, c.name), c.err)
+			continue
+		}
+
+		if c.empty() {
+			continue
+		}
+
+		pending++
+		fmt.Fprintf(w, "~ %s\n", describeEntity(c.guid, c.name))
+
+		for _, t := range c.add {
+			for _, v := range t.Values {
+				fmt.Fprintf(w, "    + %s: %s\n", t.Key, v)
+			}
+		}
+
+		for _, t := range c.deleteValues {
+			fmt.Fprintf(w, "    - %s: %s\n", t.Key, t.Value)
+		}
+
+		for _, key := range c.deleteKeys {
+			fmt.Fprintf(w, "    - %s (all values)\n", key)
+		}
+	}
+
+	fmt.Fprintf(w, "%d of %d entities to change\n", pending, len(changes))
+}
+
+func describeEntity(guid entities.EntityGUID, name string) string {
+	if name == "" {
+		return string(guid)
+	}
+
+	return fmt.Sprintf("%s (%s)", guid, name)
+}
+
+// tagResult is the outcome of tagging an entity.
+type tagResult struct {
+	GUID    entities.EntityGUID `json:"guid"`
+	Name    string              `json:"name,omitempty"`
+	Status  string              `json:"status"`
+	Added   int                 `json:"added"`
+	Removed int                 `json:"removed"`
+	Error   string              `json:"error,omitempty"`
+}
+
+// tagger plans and applies tag changes on many entities, a few at a time and
+// within a rate limit.
+type tagger struct {
+	client      taggingClient
+	concurrency int
+	// rate is the most requests made per second, or no limit if zero.
+	rate int
+}
+
+// plan fetches the tags of each entity and works out what needs to change.
+// Entities whose tags cannot be fetched have the error in their change.
+func (t *tagger) plan(ctx context.Context, desired map[entities.EntityGUID]*desiredTags) []*tagChange {
+	guids := make([]entities.EntityGUID, 0, len(desired))
+	for guid := range desired {
+		guids = append(guids, guid)
+	}
+	sort.Slice(guids, func(i, j int) bool { return guids[i] < guids[j] })
+
+	current, errs := t.fetch(ctx, guids)
+	changes := make([]*tagChange, len(guids))
+
+	for i, guid := range guids {
+		if errs[i] != nil {
+			changes[i] = &tagChange{guid: guid, name: desired[guid].name, err: errs[i]}
+			continue
+		}
+
+		changes[i] = planTagChange(guid, current[i], desired[guid])
+	}
+
+	return changes
+}
+
+// fetch returns the tags of each entity, or the error fetching them.
+func (t *tagger) fetch(ctx context.Context, guids []entities.EntityGUID) ([][]*entities.EntityTag, []error) {
+	tags := make([][]*entities.EntityTag, len(guids))
+	errs := make([]error, len(guids))
+
+	t.each(ctx, len(guids), func(wait func() error, i int) {
+		if errs[i] = wait(); errs[i] != nil {
+			return
+		}
+
+		tags[i], errs[i] = t.client.GetTagsForEntity(guids[i])
+	})
+
+	return tags, errs
+}
+
+// apply makes the changes, returning the outcome for each entity.
+func (t *tagger) apply(ctx context.Context, changes []*tagChange) []tagResult {
+	results := make([]tagResult, len(changes))
+
+	t.each(ctx, len(changes), func(wait func() error, i int) {
+		c := changes[i]
+		results[i] = tagResult{GUID: c.guid, Name: c.name, Status: tagStatusUnchanged}
+
+		err := c.err
+		if err == nil && !c.empty() {
+			err = t.applyChange(wait, c)
+		}
+
+		switch {
+		case err != nil:
+			results[i].Status = tagStatusFailed
+			results[i].Error = err.Error()
+		case !c.empty():
+			results[i].Status = tagStatusUpdated
+			results[i].Added = c.added()
+			results[i].Removed = c.removed()
+		}
+	})
+
+	return results
+}
+
+func (t *tagger) applyChange(wait func() error, c *tagChange) error {
+	if len(c.add) > 0 {
+		if err := mutateTags(wait, func() (*entities.TaggingMutationResult, error) {
+			return t.client.TaggingAddTagsToEntity(c.guid, c.add)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(c.deleteValues) > 0 {
+		if err := mutateTags(wait, func() (*entities.TaggingMutationResult, error) {
+			return t.client.TaggingDeleteTagValuesFromEntity(c.guid, c.deleteValues)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(c.deleteKeys) > 0 {
+		return mutateTags(wait, func() (*entities.TaggingMutationResult, error) {
+			return t.client.TaggingDeleteTagFromEntity(c.guid, c.deleteKeys)
+		})
+	}
+
+	return nil
+}
+
+// mutateTags waits for the rate limit and makes a tagging request.  Errors
+// reported in the result are returned as an error.
+func mutateTags(wait func() error, mutate func() (*entities.TaggingMutationResult, error)) error {
+	if err := wait(); err != nil {
+		return err
+	}
+
+	result, err := mutate()
+	if err != nil {
+		return err
+	}
+
+	if result != nil && len(result.Errors) > 0 {
+		messages := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			messages[i] = e.Message
+		}
+
+		return fmt.Errorf("%s", strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+// each calls fn for each of n items, concurrency at a time.  fn calls wait
+// before each request to stay within the rate limit; wait returns an error
+// once the context is done.
+func (t *tagger) each(ctx context.Context, n int, fn func(wait func() error, i int)) {
+	concurrency := t.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var ticks <-chan time.Time
+	if t.rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(t.rate))
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	wait := func() error {
+		if ticks == nil {
+			return ctx.Err()
+		}
+
+		select {
+		case <-ticks:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				fn(wait, i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+}
+
+func stringSet(values []string) map[string]bool {
+	set := map[string]bool{}
+	for _, v := range values {
+		set[v] = true
+	}
+
+	return set
+}
@@ -0,0 +1,186 @@
+// +build unit
+
+package entities
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-client-go/pkg/entities"
+)
+
+type mockTaggingClient struct {
+	sync.Mutex
+	tags         map[entities.EntityGUID][]*entities.EntityTag
+	added        map[entities.EntityGUID][]entities.TaggingTagInput
+	deletedKeys  map[entities.EntityGUID][]string
+	deletedValue map[entities.EntityGUID][]entities.TaggingTagValueInput
+	failures     map[entities.EntityGUID]string
+}
+
+func newMockTaggingClient(tags map[entities.EntityGUID][]*entities.EntityTag) *mockTaggingClient {
+	return &mockTaggingClient{
+		tags:         tags,
+		added:        map[entities.EntityGUID][]entities.TaggingTagInput{},
+		deletedKeys:  map[entities.EntityGUID][]string{},
+		deletedValue: map[entities.EntityGUID][]entities.TaggingTagValueInput{},
+		failures:     map[entities.EntityGUID]string{},
+	}
+}
+
+func (m *mockTaggingClient) GetTagsForEntity(guid entities.EntityGUID) ([]*entities.EntityTag, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	tags, ok := m.tags[guid]
+	if !ok {
+		return nil, errors.New("entity not found")
+	}
+
+	return tags, nil
+}
+
+func (m *mockTaggingClient) result(guid entities.EntityGUID) *entities.TaggingMutationResult {
+	if msg, ok := m.failures[guid]; ok {
+		return &entities.TaggingMutationResult{Errors: []entities.TaggingMutationError{{Message: msg}}}
+	}
+
+	return &entities.TaggingMutationResult{}
+}
+
+func (m *mockTaggingClient) TaggingAddTagsToEntity(guid entities.EntityGUID, tags []entities.TaggingTagInput) (
+	*entities.TaggingMutationResult, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.added[guid] = append(m.added[guid], tags...)
+
+	return m.result(guid), nil
+}
+
+func (m *mockTaggingClient) TaggingDeleteTagFromEntity(guid entities.EntityGUID, keys []string) (*entities.TaggingMutationResult, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.deletedKeys[guid] = append(m.deletedKeys[guid], keys...)
+
+	return m.result(guid), nil
+}
+
+func (m *mockTaggingClient) TaggingDeleteTagValuesFromEntity(guid entities.EntityGUID, values []entities.TaggingTagValueInput) (
+	*entities.TaggingMutationResult, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.deletedValue[guid] = append(m.deletedValue[guid], values...)
+
+	return m.result(guid), nil
+}
+
+func desired(set map[string][]string, remove ...string) *desiredTags {
+	d := newDesiredTags("")
+	d.merge(tagFileEntry{Remove: remove})
+	for k, v := range set {
+		d.set[k] = v
+	}
+
+	return d
+}
+
+func TestPlanTagChange(t *testing.T) {
+	current := []*entities.EntityTag{
+		{Key: "team", Values: []string{"checkout"}},
+		{Key: "env", Values: []string{"production", "staging"}},
+		{Key: "owner", Values: []string{"jane"}},
+	}
+
+	c := planTagChange("A", current, desired(map[string][]string{
+		"team": {"payments"},
+		"env":  {"production"},
+		"tier": {"1"},
+	}, "owner", "missing"))
+
+	assert.Equal(t, []entities.TaggingTagInput{
+		{Key: "team", Values: []string{"payments"}},
+		{Key: "tier", Values: []string{"1"}},
+	}, c.add)
+	assert.Equal(t, []entities.TaggingTagValueInput{
+		{Key: "env", Value: "staging"},
+		{Key: "team", Value: "checkout"},
+	}, c.deleteValues)
+	assert.Equal(t, []string{"owner"}, c.deleteKeys)
+	assert.Equal(t, 2, c.added())
+	assert.Equal(t, 3, c.removed())
+
+	c = planTagChange("A", current, desired(map[string][]string{"env": {"staging", "production"}}))
+	assert.True(t, c.empty())
+}
+
+func TestWriteTagPlan(t *testing.T) {
+	var b bytes.Buffer
+
+	writeTagPlan(&b, []*tagChange{
+		{guid: "A", name: "checkout", add: []entities.TaggingTagInput{{Key: "team", Values: []string{"payments"}}}},
+		{guid: "B"},
+		{guid: "C", deleteValues: []entities.TaggingTagValueInput{{Key: "env", Value: "staging"}}, deleteKeys: []string{"owner"}},
+		{guid: "D", err: errors.New("entity not found")},
+	})
+
+	assert.Equal(t, `~ A (checkout)
+    + team: payments
+~ C
+    - env: staging
+    - owner (all values)
+! D: entity not found
+2 of 4 entities to change
+`, b.String())
+}
+
+func TestTagger(t *testing.T) {
+	client := newMockTaggingClient(map[entities.EntityGUID][]*entities.EntityTag{
+		"A": {{Key: "team", Values: []string{"checkout"}}},
+		"B": {{Key: "team", Values: []string{"payments"}}},
+		"C": {},
+	})
+	client.failures["C"] = "not allowed"
+
+	tg := &tagger{client: client, concurrency: 2}
+
+	changes := tg.plan(context.Background(), map[entities.EntityGUID]*desiredTags{
+		"A": desired(map[string][]string{"team": {"payments"}}),
+		"B": desired(map[string][]string{"team": {"payments"}}),
+		"C": desired(map[string][]string{"team": {"payments"}}),
+		"D": desired(map[string][]string{"team": {"payments"}}),
+	})
+	require.Len(t, changes, 4)
+
+	results := tg.apply(context.Background(), changes)
+
+	assert.Equal(t, []tagResult{
+		{GUID: "A", Status: tagStatusUpdated, Added: 1, Removed: 1},
+		{GUID: "B", Status: tagStatusUnchanged},
+		{GUID: "C", Status: tagStatusFailed, Error: "not allowed"},
+		{GUID: "D", Status: tagStatusFailed, Error: "entity not found"},
+	}, results)
+
+	assert.Equal(t, []entities.TaggingTagInput{{Key: "team", Values: []string{"payments"}}}, client.added["A"])
+	assert.Equal(t, []entities.TaggingTagValueInput{{Key: "team", Value: "checkout"}}, client.deletedValue["A"])
+	assert.Empty(t, client.added["B"])
+}
+
+func TestTagger_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tg := &tagger{client: newMockTaggingClient(nil), concurrency: 1, rate: 1}
+	changes := tg.plan(ctx, map[entities.EntityGUID]*desiredTags{"A": desired(nil)})
+
+	require.Len(t, changes, 1)
+	assert.Equal(t, context.Canceled, changes[0].err)
+}
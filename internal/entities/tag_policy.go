@@ -0,0 +1,430 @@
+package entities
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/newrelic/newrelic-client-go/pkg/entities"
+)
+
+// tagPolicy declares the tags entities must have.  Each rule selects
+// entities by domain, type or entity search query, and gives requirements
+// for their tags.
+//
+//   rules:
+//     - name: apm-ownership
+//       domain: APM
+//       type: APPLICATION
+//       tags:
+//         team:
+//           required: true
+//           values: [payments, checkout, search]
+//         env:
+//           required: true
+//           pattern: ^(production|staging|development)$
+//           default: production
+//           replace:
+//             prod: production
+type tagPolicy struct {
+	Rules []*tagPolicyRule `yaml:"rules"`
+}
+
+type tagPolicyRule struct {
+	Name   string                     `yaml:"name"`
+	Domain string                     `yaml:"domain"`
+	Type   string                     `yaml:"type"`
+	Query  string                     `yaml:"query"`
+	Tags   map[string]*tagRequirement `yaml:"tags"`
+}
+
+// tagRequirement is what a tag must look like.  Default is added when a
+// required tag is missing, and Replace maps values that are not allowed to
+// values that are, when fixing violations.
+type tagRequirement struct {
+	Required bool              `yaml:"required"`
+	Values   []string          `yaml:"values"`
+	Pattern  string            `yaml:"pattern"`
+	Default  string            `yaml:"default"`
+	Replace  map[string]string `yaml:"replace"`
+
+	pattern *regexp.Regexp
+}
+
+// tagViolation is a tag on an entity that does not meet a requirement.  Fix
+// describes how --fix corrects it, if it can be corrected.
+type tagViolation struct {
+	GUID    entities.EntityGUID `json:"guid"`
+	Name    string              `json:"name"`
+	Type    string              `json:"type"`
+	Rule    string              `json:"rule"`
+	Key     string              `json:"key"`
+	Value   string              `json:"value,omitempty"`
+	Problem string              `json:"problem"`
+	Fix     string              `json:"fix,omitempty"`
+	Fixed   bool                `json:"fixed"`
+}
+
+func readTagPolicy(path string) (*tagPolicy, error) {
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p tagPolicy
+	if err = yaml.UnmarshalStrict(out, &p); err != nil {
+		return nil, fmt.Errorf("could not read %s: %s", path, err)
+	}
+
+	if err = p.validate(); err != nil {
+		return nil, fmt.Errorf("could not read %s: %s", path, err)
+	}
+
+	return &p, nil
+}
+
+func (p *tagPolicy) validate() error {
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("no rules are listed")
+	}
+
+	for i, r := range p.Rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule %d", i+1)
+		}
+
+		if r.Domain == "" && r.Type == "" && r.Query == "" {
+			return fmt.Errorf("%s must select entities with a domain, type or query", r.Name)
+		}
+
+		if len(r.Tags) == 0 {
+			return fmt.Errorf("%s has no tag requirements", r.Name)
+		}
+
+		for key, req := range r.Tags {
+			if req == nil {
+				return fmt.Errorf("%s has no requirements for the tag %s", r.Name, key)
+			}
+
+			if req.Pattern != "" {
+				pattern, err := regexp.Compile(req.Pattern)
+				if err != nil {
+					return fmt.Errorf("%s has an invalid pattern for the tag %s: %s", r.Name, key, err)
+				}
+
+				req.pattern = pattern
+			}
+
+			if req.Default != "" && req.problem(req.Default) != "" {
+				return fmt.Errorf("%s has a default for the tag %s that is not allowed: %s", r.Name, key, req.Default)
+			}
+
+			for from, to := range req.Replace {
+				if req.problem(to) != "" {
+					return fmt.Errorf("%s replaces %s in the tag %s with a value that is not allowed: %s", r.Name, from, key, to)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// searchQuery returns the entity search query for the entities the rule
+// applies to.
+func (r *tagPolicyRule) searchQuery() string {
+	conditions := []string{}
+
+	if r.Query != "" {
+		conditions = append(conditions, "("+r.Query+")")
+	}
+
+	if r.Domain != "" {
+		conditions = append(conditions, "domain = "+quoteEntityQueryValue(r.Domain))
+	}
+
+	if r.Type != "" {
+		conditions = append(conditions, "type = "+quoteEntityQueryValue(r.Type))
+	}
+
+	return strings.Join(conditions, " AND ")
+}
+
+// problem returns what is wrong with a value, or an empty string if the
+// value is allowed.
+func (req *tagRequirement) problem(value string) string {
+	if len(req.Values) > 0 && !stringSet(req.Values)[value] {
+		return "value is not one of " + strings.Join(req.Values, ", ")
+	}
+
+	if req.pattern != nil && !req.pattern.MatchString(value) {
+		return "value does not match " + req.Pattern
+	}
+
+	return ""
+}
+
+// audit checks an entity's tags against the rule.  It returns the violations
+// found and, for the tags that can be fixed, the values they should have.
+func (r *tagPolicyRule) audit(entity entities.EntityOutlineInterface, tags []*entities.EntityTag) ([]tagViolation, map[string][]string) {
+	existing := map[string][]string{}
+	for _, t := range tags {
+		if t != nil {
+			existing[t.Key] = t.Values
+		}
+	}
+
+	keys := make([]string, 0, len(r.Tags))
+	for key := range r.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	violations := []tagViolation{}
+	fixes := map[string][]string{}
+
+	violation := func(key string, value string, problem string, fix string) tagViolation {
+		return tagViolation{
+			GUID:    entity.GetGUID(),
+			Name:    entity.GetName(),
+			Type:    entity.GetType(),
+			Rule:    r.Name,
+			Key:     key,
+			Value:   value,
+			Problem: problem,
+			Fix:     fix,
+		}
+	}
+
+	for _, key := range keys {
+		req := r.Tags[key]
+		values := existing[key]
+
+		if len(values) == 0 {
+			if !req.Required {
+				continue
+			}
+
+			fix := ""
+			if req.Default != "" {
+				fix = "add " + req.Default
+				fixes[key] = []string{req.Default}
+			}
+
+			violations = append(violations, violation(key, "", "required tag is missing", fix))
+			continue
+		}
+
+		fixed := []string{}
+		replaced := false
+
+		for _, v := range values {
+			problem := req.problem(v)
+			if problem == "" {
+				fixed = append(fixed, v)
+				continue
+			}
+
+			fix := ""
+			if to, ok := req.Replace[v]; ok {
+				fix = "replace with " + to
+				fixed = append(fixed, to)
+				replaced = true
+			} else {
+				fixed = append(fixed, v)
+			}
+
+			violations = append(violations, violation(key, v, problem, fix))
+		}
+
+		if replaced {
+			fixes[key] = uniqueStrings(fixed)
+		}
+	}
+
+	return violations, fixes
+}
+
+// auditTags checks the tags of the entities each rule applies to.  It returns
+// the violations found and the changes that fix those that can be fixed.
+// Entities whose tags cannot be fetched are reported as violating each rule
+// that applies to them, as they cannot be shown to follow it.
+func auditTags(
+	ctx context.Context,
+	p *tagPolicy,
+	t *tagger,
+	search func(query string) ([]entities.EntityOutlineInterface, error),
+) ([]tagViolation, []*tagChange, error) {
+	matches := make([][]entities.EntityOutlineInterface, len(p.Rules))
+	index := map[entities.EntityGUID]int{}
+	guids := []entities.EntityGUID{}
+
+	for i, r := range p.Rules {
+		found, err := search(r.searchQuery())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		matches[i] = found
+
+		for _, e := range found {
+			if _, ok := index[e.GetGUID()]; !ok {
+				index[e.GetGUID()] = len(guids)
+				guids = append(guids, e.GetGUID())
+			}
+		}
+	}
+
+	current, errs := t.fetch(ctx, guids)
+
+	violations := []tagViolation{}
+	fixes := newTagFixes()
+
+	for i, r := range p.Rules {
+		for _, e := range matches[i] {
+			n := index[e.GetGUID()]
+			if errs[n] != nil {
+				violations = append(violations, tagViolation{
+					GUID:    e.GetGUID(),
+					Name:    e.GetName(),
+					Type:    e.GetType(),
+					Rule:    r.Name,
+					Problem: "could not fetch tags: " + errs[n].Error(),
+				})
+				continue
+			}
+
+			found, fixed := r.audit(e, current[n])
+			violations = append(violations, found...)
+			violations = append(violations, fixes.add(e, r.Name, fixed)...)
+		}
+	}
+
+	// Keys that rules fix differently are left alone, so are not fixed
+	for i, v := range violations {
+		if fixes.conflicts[v.GUID][v.Key] {
+			violations[i].Fix = ""
+		}
+	}
+
+	changes := []*tagChange{}
+
+	for n, guid := range guids {
+		if d, ok := fixes.desired[guid]; ok {
+			changes = append(changes, planTagChange(guid, current[n], d))
+		}
+	}
+
+	return violations, changes, nil
+}
+
+// tagFixes collects the fixes the rules make to the tags of each entity.
+// When rules fix a key of an entity in different ways, the key is left alone.
+type tagFixes struct {
+	desired   map[entities.EntityGUID]*desiredTags
+	fixedBy   map[entities.EntityGUID]map[string]string
+	conflicts map[entities.EntityGUID]map[string]bool
+}
+
+func newTagFixes() *tagFixes {
+	return &tagFixes{
+		desired:   map[entities.EntityGUID]*desiredTags{},
+		fixedBy:   map[entities.EntityGUID]map[string]string{},
+		conflicts: map[entities.EntityGUID]map[string]bool{},
+	}
+}
+
+// add adds a rule's fixes to an entity's tags, returning a violation for each
+// key an earlier rule fixed differently.
+func (f *tagFixes) add(e entities.EntityOutlineInterface, rule string, fixes map[string][]string) []tagViolation {
+	if len(fixes) == 0 {
+		return nil
+	}
+
+	guid := e.GetGUID()
+	d, ok := f.desired[guid]
+	if !ok {
+		d = newDesiredTags(e.GetName())
+		f.desired[guid] = d
+		f.fixedBy[guid] = map[string]string{}
+		f.conflicts[guid] = map[string]bool{}
+	}
+
+	keys := make([]string, 0, len(fixes))
+	for key := range fixes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	violations := []tagViolation{}
+
+	for _, key := range keys {
+		values := fixes[key]
+
+		if f.conflicts[guid][key] {
+			continue
+		}
+
+		other, fixed := f.fixedBy[guid][key]
+		if !fixed {
+			d.set[key] = values
+			f.fixedBy[guid][key] = rule
+			continue
+		}
+
+		if strings.Join(d.set[key], "\x00") == strings.Join(values, "\x00") {
+			continue
+		}
+
+		violations = append(violations, tagViolation{
+			GUID:    guid,
+			Name:    e.GetName(),
+			Type:    e.GetType(),
+			Rule:    rule,
+			Key:     key,
+			Value:   strings.Join(values, ", "),
+			Problem: fmt.Sprintf("fix conflicts with rule %s, which sets %s", other, strings.Join(d.set[key], ", ")),
+		})
+
+		delete(d.set, key)
+		f.conflicts[guid][key] = true
+	}
+
+	return violations
+}
+
+// markFixed marks the violations that were fixed on the entities tagged
+// successfully.
+func markFixed(violations []tagViolation, results []tagResult) {
+	updated := map[entities.EntityGUID]bool{}
+	for _, r := range results {
+		if r.Status == tagStatusUpdated {
+			updated[r.GUID] = true
+		}
+	}
+
+	for i := range violations {
+		if violations[i].Fix != "" && updated[violations[i].GUID] {
+			violations[i].Fixed = true
+		}
+	}
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,190 @@
+//go:build unit
+// +build unit
+
+package entities
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-client-go/pkg/entities"
+)
+
+const testTagPolicy = `
+rules:
+  - name: apm
+    domain: APM
+    type: APPLICATION
+    tags:
+      team:
+        required: true
+        values: [payments, checkout]
+      env:
+        required: true
+        pattern: ^(production|staging)$
+        default: production
+        replace:
+          prod: production
+  - query: "name LIKE 'legacy'"
+    tags:
+      owner:
+        required: true
+`
+
+func readTestTagPolicy(t *testing.T, content string) (*tagPolicy, error) {
+	dir, err := ioutil.TempDir("", "policy")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	return readTagPolicy(writeTempFile(t, dir, "policy.yaml", content))
+}
+
+func TestReadTagPolicy(t *testing.T) {
+	p, err := readTestTagPolicy(t, testTagPolicy)
+	require.NoError(t, err)
+
+	require.Len(t, p.Rules, 2)
+	assert.Equal(t, "apm", p.Rules[0].Name)
+	assert.Equal(t, "rule 2", p.Rules[1].Name)
+	assert.Equal(t, "domain = 'APM' AND type = 'APPLICATION'", p.Rules[0].searchQuery())
+	assert.Equal(t, "(name LIKE 'legacy')", p.Rules[1].searchQuery())
+}
+
+func TestReadTagPolicy_Invalid(t *testing.T) {
+	invalid := map[string]string{
+		"rules: []": "no rules are listed",
+		"rules:\n  - tags: {team: {required: true}}":                             "rule 1 must select entities with a domain, type or query",
+		"rules:\n  - domain: APM":                                                "rule 1 has no tag requirements",
+		"rules:\n  - {domain: APM, tags: {env: {pattern: '('}}}":                 "rule 1 has an invalid pattern for the tag env",
+		"rules:\n  - {domain: APM, tags: {env: {values: [a], default: b}}}":      "rule 1 has a default for the tag env that is not allowed: b",
+		"rules:\n  - {domain: APM, tags: {env: {values: [a], replace: {c: b}}}}": "rule 1 replaces c in the tag env with a value that is not allowed: b",
+	}
+
+	for content, expected := range invalid {
+		_, err := readTestTagPolicy(t, content)
+		require.Error(t, err, content)
+		assert.Contains(t, err.Error(), ": "+expected, content)
+	}
+}
+
+func TestAuditTags(t *testing.T) {
+	p, err := readTestTagPolicy(t, testTagPolicy)
+	require.NoError(t, err)
+
+	client := newMockTaggingClient(map[entities.EntityGUID][]*entities.EntityTag{
+		"A": {{Key: "team", Values: []string{"payments"}}, {Key: "env", Values: []string{"production"}}},
+		"B": {{Key: "team", Values: []string{"billing"}}, {Key: "env", Values: []string{"prod", "staging"}}},
+		"C": {},
+	})
+
+	search := func(query string) ([]entities.EntityOutlineInterface, error) {
+		if query == "(name LIKE 'legacy')" {
+			return []entities.EntityOutlineInterface{
+				&entities.ApmApplicationEntityOutline{GUID: "C", Name: "legacy", Type: "APPLICATION"},
+			}, nil
+		}
+
+		return []entities.EntityOutlineInterface{
+			&entities.ApmApplicationEntityOutline{GUID: "A", Name: "a", Type: "APPLICATION"},
+			&entities.ApmApplicationEntityOutline{GUID: "B", Name: "b", Type: "APPLICATION"},
+			&entities.ApmApplicationEntityOutline{GUID: "C", Name: "legacy", Type: "APPLICATION"},
+			&entities.ApmApplicationEntityOutline{GUID: "D", Name: "gone", Type: "APPLICATION"},
+		}, nil
+	}
+
+	tg := &tagger{client: client, concurrency: 2}
+
+	violations, changes, err := auditTags(context.Background(), p, tg, search)
+	require.NoError(t, err)
+
+	summary := []string{}
+	for _, v := range violations {
+		summary = append(summary, string(v.GUID)+" "+v.Rule+" "+v.Key+" "+v.Value+": "+v.Problem+" ("+v.Fix+")")
+	}
+
+	assert.Equal(t, []string{
+		"B apm env prod: value does not match ^(production|staging)$ (replace with production)",
+		"B apm team billing: value is not one of payments, checkout ()",
+		"C apm env : required tag is missing (add production)",
+		"C apm team : required tag is missing ()",
+		"D apm  : could not fetch tags: entity not found ()",
+		"C rule 2 owner : required tag is missing ()",
+	}, summary)
+
+	require.Len(t, changes, 2)
+	assert.Equal(t, entities.EntityGUID("B"), changes[0].guid)
+	assert.Equal(t, []entities.TaggingTagInput{{Key: "env", Values: []string{"production"}}}, changes[0].add)
+	assert.Equal(t, []entities.TaggingTagValueInput{{Key: "env", Value: "prod"}}, changes[0].deleteValues)
+	assert.Equal(t, entities.EntityGUID("C"), changes[1].guid)
+	assert.Equal(t, []entities.TaggingTagInput{{Key: "env", Values: []string{"production"}}}, changes[1].add)
+
+	markFixed(violations, tg.apply(context.Background(), changes))
+
+	fixed := 0
+	for _, v := range violations {
+		if v.Fixed {
+			fixed++
+			assert.NotEmpty(t, v.Fix)
+		}
+	}
+	assert.Equal(t, 2, fixed)
+}
+
+func TestAuditTags_ConflictingFixes(t *testing.T) {
+	p, err := readTestTagPolicy(t, `
+rules:
+  - name: apm
+    domain: APM
+    tags:
+      env:
+        required: true
+        default: production
+      team:
+        required: true
+        default: payments
+  - name: legacy
+    query: "name LIKE 'legacy'"
+    tags:
+      env:
+        required: true
+        default: staging
+      team:
+        required: true
+        default: payments
+`)
+	require.NoError(t, err)
+
+	client := newMockTaggingClient(map[entities.EntityGUID][]*entities.EntityTag{"A": {}})
+	search := func(query string) ([]entities.EntityOutlineInterface, error) {
+		return []entities.EntityOutlineInterface{
+			&entities.ApmApplicationEntityOutline{GUID: "A", Name: "legacy", Type: "APPLICATION"},
+		}, nil
+	}
+
+	tg := &tagger{client: client, concurrency: 1}
+
+	violations, changes, err := auditTags(context.Background(), p, tg, search)
+	require.NoError(t, err)
+
+	summary := []string{}
+	for _, v := range violations {
+		summary = append(summary, v.Rule+" "+v.Key+" "+v.Value+": "+v.Problem+" ("+v.Fix+")")
+	}
+
+	// The rules agree on team, but not on env, which is left alone
+	assert.Equal(t, []string{
+		"apm env : required tag is missing ()",
+		"apm team : required tag is missing (add payments)",
+		"legacy env : required tag is missing ()",
+		"legacy team : required tag is missing (add payments)",
+		"legacy env staging: fix conflicts with rule apm, which sets production ()",
+	}, summary)
+
+	require.Len(t, changes, 1)
+	assert.Equal(t, []entities.TaggingTagInput{{Key: "team", Values: []string{"payments"}}}, changes[0].add)
+}
@@ -2,6 +2,9 @@ package install
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"strconv"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -9,13 +12,22 @@ import (
 	"github.com/newrelic/newrelic-cli/internal/client"
 	"github.com/newrelic/newrelic-cli/internal/config"
 	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+	"github.com/newrelic/newrelic-cli/internal/install/validation"
+	"github.com/newrelic/newrelic-cli/internal/utils"
 	"github.com/newrelic/newrelic-client-go/newrelic"
 )
 
 var (
 	assumeYes          bool
+	hooksFile          string
+	inputVarsFile      string
+	nrdbSimulatorAddr  string
+	nrdbSimulatorFile  string
 	recipeNames        []string
 	recipePaths        []string
+	recipeServiceURL   string
+	recipeServiceKey   bool
 	skipDiscovery      bool
 	skipIntegrations   bool
 	skipLoggingInstall bool
@@ -31,13 +43,24 @@ var Command = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		ic := InstallerContext{
 			AssumeYes:          assumeYes,
+			InputVarsFile:      inputVarsFile,
 			RecipeNames:        recipeNames,
 			RecipePaths:        recipePaths,
+			RecipeServiceURL:   recipeServiceURL,
+			SendRecipeAPIKey:   recipeServiceKey,
 			SkipDiscovery:      skipDiscovery,
 			SkipIntegrations:   skipIntegrations,
 			SkipLoggingInstall: skipLoggingInstall,
 		}
 
+		if ic.RecipeServiceURL == "" {
+			ic.RecipeServiceURL = os.Getenv("NEW_RELIC_RECIPE_SERVICE_URL")
+		}
+
+		if !ic.SendRecipeAPIKey {
+			ic.SendRecipeAPIKey, _ = strconv.ParseBool(os.Getenv("NEW_RELIC_RECIPE_SERVICE_SEND_API_KEY"))
+		}
+
 		client.WithClientAndProfile(func(nrClient *newrelic.NewRelic, profile *credentials.Profile) {
 			if trace {
 				log.SetLevel(log.TraceLevel)
@@ -52,6 +75,16 @@ var Command = &cobra.Command{
 				log.Fatal(err)
 			}
 
+			ic.Hooks, err = execution.LoadInstallHooks(hooksFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			ic.NRDBSimulator, err = startNRDBSimulator(nrdbSimulatorFile, nrdbSimulatorAddr)
+			if err != nil {
+				log.Fatal(err)
+			}
+
 			i := NewRecipeInstaller(ic, nrClient)
 
 			// Run the install.
@@ -69,9 +102,40 @@ func assertProfileIsValid(profile *credentials.Profile) error {
 	return nil
 }
 
+// startNRDBSimulator returns an NRDBSimulator seeded from the given file and
+// accepting events on the given address, or nil if neither was requested.
+func startNRDBSimulator(file string, address string) (*validation.NRDBSimulator, error) {
+	if file == "" && address == "" {
+		return nil, nil
+	}
+
+	s := validation.NewNRDBSimulator()
+
+	if file != "" {
+		if err := s.LoadFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	if address != "" {
+		go func() {
+			if err := s.ListenAndServe(utils.SignalCtx, address); err != nil {
+				log.Errorf("NRDB simulator stopped: %s", err)
+			}
+		}()
+	}
+
+	log.Warn("Validating installs against the NRDB simulator rather than NRDB.")
+
+	return s, nil
+}
+
 func init() {
 	Command.Flags().StringSliceVarP(&recipePaths, "recipePath", "c", []string{}, "the path to a recipe file to install")
 	Command.Flags().StringSliceVarP(&recipeNames, "recipe", "n", []string{}, "the name of a recipe to install")
+	Command.Flags().StringVar(&recipeServiceURL, "recipeServiceURL", "", "the recipe service URL, defaults to NEW_RELIC_RECIPE_SERVICE_URL")
+	Command.Flags().BoolVar(&recipeServiceKey, "recipeServiceSendAPIKey", false,
+		"send your API key to a --recipeServiceURL that is not on this machine, defaults to NEW_RELIC_RECIPE_SERVICE_SEND_API_KEY")
 	Command.Flags().BoolVarP(&skipDiscovery, "skipDiscovery", "d", false, "skips discovery of recommended New Relic integrations")
 	Command.Flags().BoolVarP(&skipIntegrations, "skipIntegrations", "r", false, "skips installation of recommended New Relic integrations")
 	Command.Flags().BoolVarP(&skipLoggingInstall, "skipLoggingInstall", "l", false, "skips installation of New Relic Logging")
@@ -79,4 +143,9 @@ func init() {
 	Command.Flags().BoolVar(&debug, "debug", false, "debug level logging")
 	Command.Flags().BoolVar(&trace, "trace", false, "trace level logging")
 	Command.Flags().BoolVarP(&assumeYes, "assumeYes", "y", false, "use \"yes\" for all questions during install")
+	Command.Flags().StringVar(&hooksFile, "hooksFile", "",
+		fmt.Sprintf("the path to an install hooks file, defaults to %s in the config directory", execution.DefaultInstallHooksFile))
+	Command.Flags().StringVar(&nrdbSimulatorFile, "nrdbSimulatorFile", "", "validate installs against events read from a file rather than NRDB")
+	Command.Flags().StringVar(&nrdbSimulatorAddr, "nrdbSimulatorAddress", "", "validate installs against events POSTed to this address rather than NRDB")
+	Command.Flags().StringVar(&inputVarsFile, "inputVarsFile", "", "the path to a file used to save and reuse recipe input variable values")
 }
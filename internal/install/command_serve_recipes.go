@@ -0,0 +1,68 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+)
+
+var (
+	serveRecipesDir     string
+	serveRecipesAddress string
+)
+
+var cmdServeRecipes = &cobra.Command{
+	Use:   "serve-recipes",
+	Short: "Serve local recipe files in place of the recipe service.",
+	Long: `Serve local recipe files in place of the recipe service
+
+The serve-recipes command starts a local stand-in for the recipe service that
+answers the recipe search and recommendation queries issued during an install,
+using the recipe files found in the given directory.  Point the installer at it
+with the --recipeServiceURL flag or the NEW_RELIC_RECIPE_SERVICE_URL
+environment variable to test recipes end to end without publishing them:
+
+  newrelic install serve-recipes --dir ./recipes
+  newrelic install --recipeServiceURL http://localhost:8080
+
+Your API key is never sent to a recipe service on this machine.  It is only sent
+to a recipe service elsewhere when --recipeServiceSendAPIKey is given.
+`,
+	Example: "newrelic install serve-recipes --dir ./recipes --address localhost:8080",
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := recipes.NewLocalRecipeServer(serveRecipesDir)
+		if err != nil {
+			log.Fatalf("could not load recipes from %s: %s", serveRecipesDir, err)
+		}
+
+		srv := &http.Server{
+			Addr:    serveRecipesAddress,
+			Handler: s,
+		}
+
+		go func() {
+			<-utils.SignalCtx.Done()
+			utils.LogIfError(srv.Shutdown(context.Background()))
+		}()
+
+		fmt.Printf("Serving %d recipes from %s at http://%s\n", len(s.Recipes()), serveRecipesDir, serveRecipesAddress)
+
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	Command.AddCommand(cmdServeRecipes)
+
+	cmdServeRecipes.Flags().StringVar(&serveRecipesDir, "dir", "", "the directory containing the recipe files to serve")
+	cmdServeRecipes.Flags().StringVar(&serveRecipesAddress, "address", "localhost:8080", "the address to listen on")
+	utils.LogIfError(cmdServeRecipes.MarkFlagRequired("dir"))
+}
@@ -0,0 +1,13 @@
+package discovery
+
+import (
+	"context"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+// InstallDetector determines whether a recipe is already installed on the
+// underlying host.
+type InstallDetector interface {
+	Detect(context.Context, types.Recipe) (*types.InstalledRecipe, error)
+}
@@ -0,0 +1,116 @@
+package discovery
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+// defaultInstallDetections are used for recipes that do not declare their own
+// install detection criteria.
+var defaultInstallDetections = map[string]recipes.InstallDetection{
+	"infrastructure-agent-installer": {
+		VersionCommand: "newrelic-infra -version",
+		VersionRegex:   `version: ([0-9][0-9.]*)`,
+	},
+}
+
+// LocalInstallDetector is an implementation of the InstallDetector interface
+// that inspects the local filesystem and runs the version command declared by
+// each recipe.
+type LocalInstallDetector struct{}
+
+// NewLocalInstallDetector returns a new instance of LocalInstallDetector.
+func NewLocalInstallDetector() *LocalInstallDetector {
+	d := LocalInstallDetector{}
+
+	return &d
+}
+
+// Detect uses the install detection criteria declared in the recipe to
+// determine whether it is already installed.
+func (d *LocalInstallDetector) Detect(ctx context.Context, r types.Recipe) (*types.InstalledRecipe, error) {
+	f, err := recipes.RecipeToRecipeFile(r)
+	if err != nil {
+		return nil, err
+	}
+
+	detection := f.InstallDetection
+	if detection.IsEmpty() {
+		detection = defaultInstallDetections[r.Name]
+	}
+
+	installed := &types.InstalledRecipe{
+		LatestVersion: detection.Version,
+	}
+
+	for _, pattern := range detection.Files {
+		matches, globErr := filepath.Glob(pattern)
+		if globErr != nil {
+			log.Debugf("invalid install detection pattern %s: %s", pattern, globErr)
+			continue
+		}
+
+		installed.Files = append(installed.Files, matches...)
+	}
+
+	if detection.VersionCommand != "" {
+		installed.Version = detectVersion(ctx, detection)
+	}
+
+	installed.Installed = len(installed.Files) > 0 || installed.Version != ""
+
+	log.WithFields(log.Fields{
+		"name":           r.Name,
+		"installed":      installed.Installed,
+		"version":        installed.Version,
+		"latest_version": installed.LatestVersion,
+		"files":          installed.Files,
+	}).Debug("install detection")
+
+	return installed, nil
+}
+
+// detectVersion runs the version command, treating any failure as the
+// component not being installed.
+func detectVersion(ctx context.Context, detection recipes.InstallDetection) string {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", detection.VersionCommand)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", detection.VersionCommand)
+	}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Debugf("version command %s failed: %s", detection.VersionCommand, err)
+		return ""
+	}
+
+	output := strings.TrimSpace(string(out))
+
+	if detection.VersionRegex == "" {
+		return output
+	}
+
+	re, err := regexp.Compile(detection.VersionRegex)
+	if err != nil {
+		log.Debugf("invalid version regex %s: %s", detection.VersionRegex, err)
+		return ""
+	}
+
+	matches := re.FindStringSubmatch(output)
+	if len(matches) < 2 {
+		return ""
+	}
+
+	return matches[1]
+}
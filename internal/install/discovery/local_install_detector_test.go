@@ -0,0 +1,94 @@
+// +build integration
+
+package discovery
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+func TestLocalInstallDetector_interface(t *testing.T) {
+	var d InstallDetector = NewLocalInstallDetector()
+	require.NotNil(t, d)
+}
+
+func TestLocalInstallDetector_NotInstalled(t *testing.T) {
+	r := recipeWithDetection(t, recipes.InstallDetection{
+		Files: []string{"/this/path/does/not/exist/*.yml"},
+	})
+
+	installed, err := NewLocalInstallDetector().Detect(context.Background(), r)
+	require.NoError(t, err)
+	require.False(t, installed.Installed)
+}
+
+func TestLocalInstallDetector_Files(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "mysql-config.yml")
+	require.NoError(t, ioutil.WriteFile(configFile, []byte{}, 0600))
+
+	r := recipeWithDetection(t, recipes.InstallDetection{
+		Files: []string{filepath.Join(dir, "*.yml")},
+	})
+
+	installed, err := NewLocalInstallDetector().Detect(context.Background(), r)
+	require.NoError(t, err)
+	require.True(t, installed.Installed)
+	require.Equal(t, []string{configFile}, installed.Files)
+}
+
+func TestLocalInstallDetector_VersionCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("version commands are exercised with sh")
+	}
+
+	r := recipeWithDetection(t, recipes.InstallDetection{
+		VersionCommand: "echo 'Test Agent version: 1.2.3, GoVersion: go1.14'",
+		VersionRegex:   `version: ([0-9.]+)`,
+		Version:        "1.3.0",
+	})
+
+	installed, err := NewLocalInstallDetector().Detect(context.Background(), r)
+	require.NoError(t, err)
+	require.True(t, installed.Installed)
+	require.Equal(t, "1.2.3", installed.Version)
+	require.True(t, installed.UpgradeAvailable())
+}
+
+func TestLocalInstallDetector_VersionCommandFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("version commands are exercised with sh")
+	}
+
+	r := recipeWithDetection(t, recipes.InstallDetection{
+		VersionCommand: "exit 1",
+	})
+
+	installed, err := NewLocalInstallDetector().Detect(context.Background(), r)
+	require.NoError(t, err)
+	require.False(t, installed.Installed)
+}
+
+func recipeWithDetection(t *testing.T, d recipes.InstallDetection) types.Recipe {
+	f := recipes.RecipeFile{
+		Name:             "test-recipe",
+		InstallDetection: d,
+	}
+
+	r, err := f.ToRecipe()
+	require.NoError(t, err)
+
+	return *r
+}
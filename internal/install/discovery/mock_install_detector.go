@@ -0,0 +1,32 @@
+package discovery
+
+import (
+	"context"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+// MockInstallDetector is a mock implementation of the InstallDetector
+// interface that provides method spies for testing scenarios.
+type MockInstallDetector struct {
+	DetectCallCount int
+	DetectErr       error
+	DetectVals      map[string]*types.InstalledRecipe
+}
+
+// NewMockInstallDetector creates a new instance of MockInstallDetector.
+func NewMockInstallDetector() *MockInstallDetector {
+	return &MockInstallDetector{
+		DetectVals: map[string]*types.InstalledRecipe{},
+	}
+}
+
+func (m *MockInstallDetector) Detect(ctx context.Context, r types.Recipe) (*types.InstalledRecipe, error) {
+	m.DetectCallCount++
+
+	if v, ok := m.DetectVals[r.Name]; ok {
+		return v, m.DetectErr
+	}
+
+	return &types.InstalledRecipe{}, m.DetectErr
+}
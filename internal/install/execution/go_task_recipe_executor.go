@@ -23,7 +23,11 @@ import (
 
 // GoTaskRecipeExecutor is an implementation of the recipeExecutor interface that
 // uses the go-task module to execute the steps defined in each recipe.
-type GoTaskRecipeExecutor struct{}
+type GoTaskRecipeExecutor struct {
+	// InputVarsFile is an optional path used to save confirmed input variable
+	// values and to reuse them on subsequent installs.
+	InputVarsFile string
+}
 
 // NewGoTaskRecipeExecutor returns a new instance of GoTaskRecipeExecutor.
 func NewGoTaskRecipeExecutor() *GoTaskRecipeExecutor {
@@ -56,7 +60,12 @@ func (re *GoTaskRecipeExecutor) Prepare(ctx context.Context, m types.DiscoveryMa
 		return types.RecipeVars{}, err
 	}
 
-	inputVarsResult, err := varsFromInput(f.InputVars, assumeYes)
+	manifest, err := loadRecipeVarsManifest(re.InputVarsFile)
+	if err != nil {
+		return types.RecipeVars{}, err
+	}
+
+	inputVarsResult, err := varsFromInput(f.InputVars, manifest[r.Name], assumeYes)
 	if err != nil {
 		return types.RecipeVars{}, err
 	}
@@ -64,7 +73,7 @@ func (re *GoTaskRecipeExecutor) Prepare(ctx context.Context, m types.DiscoveryMa
 	results = append(results, systemInfoResult)
 	results = append(results, profileResult)
 	results = append(results, recipeResult)
-	results = append(results, inputVarsResult)
+	results = append(results, recipeVarsToMap(inputVarsResult))
 
 	for _, result := range results {
 		for k, v := range result {
@@ -72,6 +81,31 @@ func (re *GoTaskRecipeExecutor) Prepare(ctx context.Context, m types.DiscoveryMa
 		}
 	}
 
+	// Give the user a chance to correct any value before anything is executed.
+	if !assumeYes && len(inputVarsResult) > 0 {
+		reviewVars := appendRecipeVars([]recipeVar{}, inputVarsResult)
+		reviewVars = appendRecipeVars(reviewVars, recipeVarsFromMap(profileResult, recipeVarSourceProfile, secretProfileVars))
+		reviewVars = appendRecipeVars(reviewVars, recipeVarsFromMap(systemInfoResult, recipeVarSourceSystem, nil))
+
+		reviewVars, err = reviewRecipeVars(r.Name, reviewVars)
+		if err != nil {
+			return types.RecipeVars{}, err
+		}
+
+		for _, v := range reviewVars {
+			vars[v.Name] = v.Value
+		}
+
+		// The input vars lead the reviewed list.
+		inputVarsResult = reviewVars[:len(inputVarsResult)]
+	}
+
+	if re.InputVarsFile != "" && len(inputVarsResult) > 0 {
+		if err = manifest.save(re.InputVarsFile, r.Name, inputVarsResult); err != nil {
+			return types.RecipeVars{}, fmt.Errorf("could not save input vars file %s: %s", re.InputVarsFile, err)
+		}
+	}
+
 	return vars, nil
 }
 
@@ -181,21 +215,39 @@ func varsFromRecipe(r types.Recipe) (types.RecipeVars, error) {
 	return vars, nil
 }
 
-func varsFromInput(inputVars []recipes.VariableConfig, assumeYes bool) (types.RecipeVars, error) {
-	vars := make(types.RecipeVars)
+func varsFromInput(inputVars []recipes.VariableConfig, savedVars types.RecipeVars, assumeYes bool) ([]recipeVar, error) {
+	vars := []recipeVar{}
 
 	for _, envConfig := range inputVars {
 		var err error
+		v := recipeVar{
+			Name:   envConfig.Name,
+			Secret: envConfig.Secret,
+		}
+
 		envValue := os.Getenv(envConfig.Name)
 
 		if envValue != "" {
-			vars[envConfig.Name] = envValue
+			v.Value = envValue
+			v.Source = recipeVarSourceEnv
+			vars = append(vars, v)
+			continue
+		}
+
+		if savedValue, ok := savedVars[envConfig.Name]; ok {
+			log.WithFields(log.Fields{
+				"name": envConfig.Name,
+			}).Debug("using saved value for env var")
+
+			v.Value = savedValue
+			v.Source = recipeVarSourceManifest
+			vars = append(vars, v)
 			continue
 		}
 
 		if assumeYes {
 			if envConfig.Default == "" {
-				return types.RecipeVars{}, fmt.Errorf("no default value for environment variable %s and none provided", envConfig.Name)
+				return nil, fmt.Errorf("no default value for environment variable %s and none provided", envConfig.Name)
 			}
 
 			log.WithFields(log.Fields{
@@ -204,6 +256,7 @@ func varsFromInput(inputVars []recipes.VariableConfig, assumeYes bool) (types.Re
 			}).Debug("required env var not found, using default")
 
 			envValue = envConfig.Default
+			v.Source = recipeVarSourceDefault
 		} else {
 			log.WithFields(log.Fields{
 				"name": envConfig.Name,
@@ -212,14 +265,16 @@ func varsFromInput(inputVars []recipes.VariableConfig, assumeYes bool) (types.Re
 			envValue, err = varFromPrompt(envConfig)
 			if err != nil {
 				if err == promptui.ErrInterrupt {
-					return types.RecipeVars{}, types.NewErrInterrupt()
+					return nil, types.NewErrInterrupt()
 				}
 
-				return types.RecipeVars{}, fmt.Errorf("prompt failed: %s", err)
+				return nil, fmt.Errorf("prompt failed: %s", err)
 			}
+			v.Source = recipeVarSourcePrompt
 		}
 
-		vars[envConfig.Name] = envValue
+		v.Value = envValue
+		vars = append(vars, v)
 	}
 
 	return vars, nil
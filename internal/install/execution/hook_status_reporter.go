@@ -0,0 +1,231 @@
+package execution
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+// HookEventType is the install event that triggered a hook.
+type HookEventType string
+
+const (
+	HookEventBeforeInstall    HookEventType = "beforeInstall"
+	HookEventRecipeInstalling HookEventType = "recipeInstalling"
+	HookEventRecipeInstalled  HookEventType = "recipeInstalled"
+	HookEventRecipeFailed     HookEventType = "recipeFailed"
+	HookEventInstallComplete  HookEventType = "installComplete"
+)
+
+// HookPayload is the JSON document a hook receives, either on stdin for
+// commands or as the request body for HTTP calls.
+type HookPayload struct {
+	Event HookEventType `json:"event"`
+	RecipeStatusEvent
+	Recipes []types.Recipe `json:"recipes,omitempty"`
+	Status  *InstallStatus `json:"status"`
+}
+
+// HookStatusReporter is an implementation of the StatusSubscriber interface
+// that runs user defined hooks at points during the install.
+type HookStatusReporter struct {
+	hooks      *InstallHooks
+	httpClient *http.Client
+}
+
+// NewHookStatusReporter returns a new instance of HookStatusReporter.
+func NewHookStatusReporter(hooks *InstallHooks) *HookStatusReporter {
+	r := HookStatusReporter{
+		hooks:      hooks,
+		httpClient: &http.Client{},
+	}
+
+	return &r
+}
+
+func (r HookStatusReporter) RecipeAvailable(status *InstallStatus, recipe types.Recipe) error {
+	return nil
+}
+
+func (r HookStatusReporter) RecipesAvailable(status *InstallStatus, recipes []types.Recipe) error {
+	return nil
+}
+
+// RecipesSelected runs the beforeInstall hooks, since the recipes to be
+// installed are known at this point.
+func (r HookStatusReporter) RecipesSelected(status *InstallStatus, recipes []types.Recipe) error {
+	p := HookPayload{
+		Event:   HookEventBeforeInstall,
+		Recipes: recipes,
+		Status:  status,
+	}
+
+	return r.run(r.hooks.BeforeInstall, p)
+}
+
+func (r HookStatusReporter) RecipeInstalling(status *InstallStatus, event RecipeStatusEvent) error {
+	return r.runRecipeHooks(HookEventRecipeInstalling, status, event, func(s InstallHookSet) []InstallHook {
+		return s.RecipeInstalling
+	})
+}
+
+func (r HookStatusReporter) RecipeInstalled(status *InstallStatus, event RecipeStatusEvent) error {
+	return r.runRecipeHooks(HookEventRecipeInstalled, status, event, func(s InstallHookSet) []InstallHook {
+		return s.RecipeInstalled
+	})
+}
+
+func (r HookStatusReporter) RecipeFailed(status *InstallStatus, event RecipeStatusEvent) error {
+	return r.runRecipeHooks(HookEventRecipeFailed, status, event, func(s InstallHookSet) []InstallHook {
+		return s.RecipeFailed
+	})
+}
+
+func (r HookStatusReporter) RecipeSkipped(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r HookStatusReporter) RecipeRecommended(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r HookStatusReporter) InstallComplete(status *InstallStatus) error {
+	p := HookPayload{
+		Event:  HookEventInstallComplete,
+		Status: status,
+	}
+
+	return r.run(r.hooks.InstallComplete, p)
+}
+
+// runRecipeHooks runs the global hooks for the event followed by the hooks
+// defined for the event's recipe.
+func (r HookStatusReporter) runRecipeHooks(
+	eventType HookEventType,
+	status *InstallStatus,
+	event RecipeStatusEvent,
+	hooksFor func(InstallHookSet) []InstallHook,
+) error {
+	hooks := append([]InstallHook{}, hooksFor(r.hooks.InstallHookSet)...)
+
+	if s, ok := r.hooks.Recipes[event.Recipe.Name]; ok {
+		hooks = append(hooks, hooksFor(s)...)
+	}
+
+	p := HookPayload{
+		Event:             eventType,
+		RecipeStatusEvent: event,
+		Status:            status,
+	}
+
+	return r.run(hooks, p)
+}
+
+// run executes every hook, continuing past failures so that one broken hook
+// does not prevent the others from running.
+func (r HookStatusReporter) run(hooks []InstallHook, p HookPayload) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	failed := []string{}
+	for _, h := range hooks {
+		log.WithFields(log.Fields{
+			"hook":  h.String(),
+			"event": p.Event,
+		}).Debug("running install hook")
+
+		if hookErr := r.runHook(h, p.Event, body); hookErr != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", h.String(), hookErr))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("install hooks failed for %s: %s", p.Event, strings.Join(failed, "; "))
+	}
+
+	return nil
+}
+
+func (r HookStatusReporter) runHook(h InstallHook, eventType HookEventType, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout())
+	defer cancel()
+
+	if h.Command != "" {
+		return runCommandHook(ctx, h, eventType, body)
+	}
+
+	return r.runHTTPHook(ctx, h, body)
+}
+
+func runCommandHook(ctx context.Context, h InstallHook, eventType HookEventType, body []byte) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", h.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", h.Command)
+	}
+
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("NEW_RELIC_INSTALL_HOOK_EVENT=%s", eventType))
+
+	out, err := cmd.CombinedOutput()
+
+	log.WithFields(log.Fields{
+		"hook":   h.String(),
+		"output": string(out),
+	}).Debug("install hook command finished")
+
+	if err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+func (r HookStatusReporter) runHTTPHook(ctx context.Context, h InstallHook, body []byte) error {
+	method := h.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequest(method, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+// +build integration
+
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+func TestHookStatusReporter_CommandReceivesEventOnStdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("command hooks are exercised with sh")
+	}
+
+	tmpFile, err := ioutil.TempFile("", t.Name())
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	hooks := &InstallHooks{
+		InstallHookSet: InstallHookSet{
+			RecipeFailed: []InstallHook{{Command: fmt.Sprintf("cat > %s", tmpFile.Name())}},
+		},
+	}
+
+	r := NewHookStatusReporter(hooks)
+	e := RecipeStatusEvent{Recipe: types.Recipe{Name: "testRecipe"}, Msg: "something went wrong"}
+
+	err = r.RecipeFailed(NewInstallStatus([]StatusSubscriber{}), e)
+	require.NoError(t, err)
+
+	out, err := ioutil.ReadFile(tmpFile.Name())
+	require.NoError(t, err)
+
+	var received HookPayload
+	require.NoError(t, json.Unmarshal(out, &received))
+	require.Equal(t, HookEventRecipeFailed, received.Event)
+	require.Equal(t, "something went wrong", received.Msg)
+}
@@ -0,0 +1,124 @@
+// +build unit
+
+package execution
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+func TestHookStatusReporter_interface(t *testing.T) {
+	var r StatusSubscriber = NewHookStatusReporter(&InstallHooks{})
+	require.NotNil(t, r)
+}
+
+func TestLoadInstallHooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, DefaultInstallHooksFile)
+	content := `
+beforeInstall:
+  - command: ./register-cmdb.sh
+recipes:
+  mysql-open-source-integration:
+    recipeInstalled:
+      - url: https://example.com/hook
+        timeout: 10s
+`
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	hooks, err := LoadInstallHooks(path)
+	require.NoError(t, err)
+	require.False(t, hooks.IsEmpty())
+	require.Equal(t, 1, len(hooks.BeforeInstall))
+	require.Equal(t, "https://example.com/hook", hooks.Recipes["mysql-open-source-integration"].RecipeInstalled[0].URL)
+	require.Equal(t, "10s", hooks.Recipes["mysql-open-source-integration"].RecipeInstalled[0].timeout().String())
+}
+
+func TestLoadInstallHooks_Invalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, DefaultInstallHooksFile)
+	content := `
+recipes:
+  mysql-open-source-integration:
+    installComplete:
+      - command: echo done
+`
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	_, err = LoadInstallHooks(path)
+	require.Error(t, err)
+}
+
+func TestHookStatusReporter_RecipeInstalledHTTP(t *testing.T) {
+	var received HookPayload
+	var globalCalls int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/global" {
+			globalCalls++
+			return
+		}
+
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	hooks := &InstallHooks{
+		InstallHookSet: InstallHookSet{
+			RecipeInstalled: []InstallHook{{URL: server.URL + "/global"}},
+		},
+		Recipes: map[string]InstallHookSet{
+			"testRecipe": {
+				RecipeInstalled: []InstallHook{{URL: server.URL + "/recipe"}},
+			},
+		},
+	}
+
+	r := NewHookStatusReporter(hooks)
+	s := NewInstallStatus([]StatusSubscriber{})
+	e := RecipeStatusEvent{Recipe: types.Recipe{Name: "testRecipe"}, EntityGUID: "testGUID"}
+
+	err := r.RecipeInstalled(s, e)
+	require.NoError(t, err)
+	require.Equal(t, 1, globalCalls)
+	require.Equal(t, HookEventRecipeInstalled, received.Event)
+	require.Equal(t, "testRecipe", received.Recipe.Name)
+	require.Equal(t, "testGUID", received.EntityGUID)
+
+	err = r.RecipeInstalled(s, RecipeStatusEvent{Recipe: types.Recipe{Name: "otherRecipe"}})
+	require.NoError(t, err)
+	require.Equal(t, 2, globalCalls)
+}
+
+func TestHookStatusReporter_HTTPFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	hooks := &InstallHooks{
+		InstallHookSet: InstallHookSet{
+			InstallComplete: []InstallHook{{URL: server.URL}},
+		},
+	}
+
+	r := NewHookStatusReporter(hooks)
+	err := r.InstallComplete(NewInstallStatus([]StatusSubscriber{}))
+	require.Error(t, err)
+}
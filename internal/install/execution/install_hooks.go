@@ -0,0 +1,167 @@
+package execution
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/newrelic/newrelic-cli/internal/config"
+)
+
+const (
+	// DefaultInstallHooksFile is the name of the install hooks file in the config directory.
+	DefaultInstallHooksFile = "install-hooks.yml"
+
+	defaultHookTimeout = 30 * time.Second
+)
+
+// InstallHooks holds the hooks that are run at points during an install, both
+// globally and for individual recipes.
+type InstallHooks struct {
+	InstallHookSet `yaml:",inline"`
+	Recipes        map[string]InstallHookSet `yaml:"recipes"`
+}
+
+// InstallHookSet holds the hooks for each supported install event.
+type InstallHookSet struct {
+	// BeforeInstall hooks run once the recipes to install have been selected.
+	// They are only supported globally.
+	BeforeInstall []InstallHook `yaml:"beforeInstall"`
+	// RecipeInstalling hooks run before a recipe is executed.
+	RecipeInstalling []InstallHook `yaml:"recipeInstalling"`
+	// RecipeInstalled hooks run after a recipe was installed and validated.
+	RecipeInstalled []InstallHook `yaml:"recipeInstalled"`
+	// RecipeFailed hooks run after a recipe failed to install.
+	RecipeFailed []InstallHook `yaml:"recipeFailed"`
+	// InstallComplete hooks run once the install has finished.  They are only
+	// supported globally.
+	InstallComplete []InstallHook `yaml:"installComplete"`
+}
+
+// InstallHook is a local command or an HTTP call that receives the install
+// event as JSON.  Exactly one of Command or URL must be set.  Header values
+// may reference environment variables, e.g. "Bearer $WEBHOOK_TOKEN".
+type InstallHook struct {
+	Name    string            `yaml:"name"`
+	Command string            `yaml:"command"`
+	URL     string            `yaml:"url"`
+	Method  string            `yaml:"method"`
+	Headers map[string]string `yaml:"headers"`
+	Timeout time.Duration     `yaml:"timeout"`
+}
+
+// LoadInstallHooks reads the install hooks from the given file.  When no file
+// is given the default hooks file in the config directory is used if it exists.
+func LoadInstallHooks(path string) (*InstallHooks, error) {
+	hooks := &InstallHooks{}
+
+	if path == "" {
+		path = filepath.Join(config.DefaultConfigDirectory, DefaultInstallHooksFile)
+
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return hooks, nil
+		}
+	}
+
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read install hooks file %s: %s", path, err)
+	}
+
+	if err = yaml.Unmarshal(out, hooks); err != nil {
+		return nil, fmt.Errorf("could not parse install hooks file %s: %s", path, err)
+	}
+
+	if err = hooks.validate(); err != nil {
+		return nil, fmt.Errorf("invalid install hooks file %s: %s", path, err)
+	}
+
+	log.WithFields(log.Fields{
+		"path": path,
+	}).Debug("loaded install hooks")
+
+	return hooks, nil
+}
+
+// IsEmpty returns true if no hooks have been defined.
+func (h *InstallHooks) IsEmpty() bool {
+	if !h.InstallHookSet.isEmpty() {
+		return false
+	}
+
+	for _, s := range h.Recipes {
+		if !s.isEmpty() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (h *InstallHooks) validate() error {
+	if err := h.InstallHookSet.validate(); err != nil {
+		return err
+	}
+
+	for name, s := range h.Recipes {
+		if len(s.BeforeInstall) > 0 || len(s.InstallComplete) > 0 {
+			return fmt.Errorf("recipe %s: beforeInstall and installComplete hooks are only supported globally", name)
+		}
+
+		if err := s.validate(); err != nil {
+			return fmt.Errorf("recipe %s: %s", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (s InstallHookSet) all() []InstallHook {
+	all := []InstallHook{}
+	all = append(all, s.BeforeInstall...)
+	all = append(all, s.RecipeInstalling...)
+	all = append(all, s.RecipeInstalled...)
+	all = append(all, s.RecipeFailed...)
+	all = append(all, s.InstallComplete...)
+
+	return all
+}
+
+func (s InstallHookSet) isEmpty() bool {
+	return len(s.all()) == 0
+}
+
+func (s InstallHookSet) validate() error {
+	for _, h := range s.all() {
+		if (h.Command == "") == (h.URL == "") {
+			return fmt.Errorf("hook %s must define exactly one of command or url", h.String())
+		}
+	}
+
+	return nil
+}
+
+func (h InstallHook) String() string {
+	if h.Name != "" {
+		return h.Name
+	}
+
+	if h.Command != "" {
+		return h.Command
+	}
+
+	return h.URL
+}
+
+func (h InstallHook) timeout() time.Duration {
+	if h.Timeout > 0 {
+		return h.Timeout
+	}
+
+	return defaultHookTimeout
+}
@@ -0,0 +1,254 @@
+package execution
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+// recipeVarSource describes where the value of a recipe variable came from.
+type recipeVarSource string
+
+const (
+	recipeVarSourceEnv      recipeVarSource = "env"
+	recipeVarSourceDefault  recipeVarSource = "default"
+	recipeVarSourcePrompt   recipeVarSource = "prompt"
+	recipeVarSourceProfile  recipeVarSource = "profile"
+	recipeVarSourceSystem   recipeVarSource = "system info"
+	recipeVarSourceManifest recipeVarSource = "manifest"
+	recipeVarSourceEdited   recipeVarSource = "edited"
+
+	maskedRecipeVarValue = "********"
+	confirmRecipeVars    = "Continue with these values"
+)
+
+var secretProfileVars = []string{
+	"NEW_RELIC_LICENSE_KEY",
+	"NEW_RELIC_API_KEY",
+}
+
+// recipeVar is a resolved recipe variable along with its origin.
+type recipeVar struct {
+	Name   string
+	Value  string
+	Source recipeVarSource
+	Secret bool
+}
+
+// DisplayValue returns the value of the variable, masked if it is a secret.
+func (v recipeVar) DisplayValue() string {
+	if v.Secret && v.Value != "" {
+		return maskedRecipeVarValue
+	}
+
+	return v.Value
+}
+
+// recipeVarsManifest holds confirmed input variable values keyed by recipe name.
+type recipeVarsManifest map[string]types.RecipeVars
+
+func loadRecipeVarsManifest(path string) (recipeVarsManifest, error) {
+	m := recipeVarsManifest{}
+
+	if path == "" {
+		return m, nil
+	}
+
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+
+		return nil, fmt.Errorf("could not read input vars file %s: %s", path, err)
+	}
+
+	if err = yaml.Unmarshal(out, &m); err != nil {
+		return nil, fmt.Errorf("could not parse input vars file %s: %s", path, err)
+	}
+
+	if m == nil {
+		m = recipeVarsManifest{}
+	}
+
+	return m, nil
+}
+
+// save writes the manifest to the given path.  Secret values are never
+// persisted, the user is asked for them again on the next install.
+func (m recipeVarsManifest) save(path string, recipeName string, vars []recipeVar) error {
+	saved := types.RecipeVars{}
+	for _, v := range vars {
+		if v.Secret {
+			continue
+		}
+
+		saved[v.Name] = v.Value
+	}
+
+	m[recipeName] = saved
+
+	out, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"path":   path,
+		"recipe": recipeName,
+	}).Debug("saving input vars")
+
+	return ioutil.WriteFile(path, out, 0600)
+}
+
+// recipeVarsFromMap converts vars from a single source into a list sorted by name.
+func recipeVarsFromMap(vars types.RecipeVars, source recipeVarSource, secretNames []string) []recipeVar {
+	result := []recipeVar{}
+
+	for k, v := range vars {
+		result = append(result, recipeVar{
+			Name:   k,
+			Value:  v,
+			Source: source,
+			Secret: containsString(secretNames, k),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// appendRecipeVars appends vars not already present in dst, since input vars
+// take precedence over profile and system info values of the same name.
+func appendRecipeVars(dst []recipeVar, vars []recipeVar) []recipeVar {
+	for _, v := range vars {
+		found := false
+		for _, d := range dst {
+			if d.Name == v.Name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			dst = append(dst, v)
+		}
+	}
+
+	return dst
+}
+
+func recipeVarsToMap(vars []recipeVar) types.RecipeVars {
+	result := types.RecipeVars{}
+
+	for _, v := range vars {
+		result[v.Name] = v.Value
+	}
+
+	return result
+}
+
+func formatRecipeVars(vars []recipeVar) []string {
+	nameWidth, valueWidth := 0, 0
+	for _, v := range vars {
+		if len(v.Name) > nameWidth {
+			nameWidth = len(v.Name)
+		}
+
+		if len(v.DisplayValue()) > valueWidth {
+			valueWidth = len(v.DisplayValue())
+		}
+	}
+
+	lines := make([]string, len(vars))
+	for i, v := range vars {
+		lines[i] = fmt.Sprintf("%-*s  %-*s  (%s)", nameWidth, v.Name, valueWidth, v.DisplayValue(), v.Source)
+	}
+
+	return lines
+}
+
+// reviewRecipeVars lists the resolved variables and lets the user edit any
+// of them before the recipe is executed.
+func reviewRecipeVars(recipeName string, vars []recipeVar) ([]recipeVar, error) {
+	for {
+		items := append([]string{confirmRecipeVars}, formatRecipeVars(vars)...)
+
+		prompt := promptui.Select{
+			Label: fmt.Sprintf("Review the values that will be used to install %s, select one to edit it", recipeName),
+			Items: items,
+			Size:  len(items),
+		}
+
+		idx, _, err := prompt.Run()
+		if err != nil {
+			if err == promptui.ErrInterrupt {
+				return nil, types.NewErrInterrupt()
+			}
+
+			return nil, fmt.Errorf("prompt failed: %s", err)
+		}
+
+		if idx == 0 {
+			return vars, nil
+		}
+
+		v := &vars[idx-1]
+
+		c := recipes.VariableConfig{
+			Name:   v.Name,
+			Prompt: fmt.Sprintf("new value for %s", v.Name),
+			Secret: v.Secret,
+		}
+
+		if !v.Secret {
+			c.Default = v.Value
+		}
+
+		value, err := varFromPrompt(c)
+		if err != nil {
+			if err == promptui.ErrInterrupt {
+				return nil, types.NewErrInterrupt()
+			}
+
+			return nil, fmt.Errorf("prompt failed: %s", err)
+		}
+
+		// An empty secret keeps its current value, since it was never displayed.
+		if v.Secret && value == "" {
+			continue
+		}
+
+		if value != v.Value {
+			v.Value = value
+			v.Source = recipeVarSourceEdited
+		}
+	}
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
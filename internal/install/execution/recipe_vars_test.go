@@ -0,0 +1,89 @@
+// +build unit
+
+package execution
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+func TestRecipeVar_DisplayValueMasksSecrets(t *testing.T) {
+	v := recipeVar{Name: "PASSWORD", Value: "hunter2", Secret: true}
+	require.Equal(t, maskedRecipeVarValue, v.DisplayValue())
+
+	v.Secret = false
+	require.Equal(t, "hunter2", v.DisplayValue())
+}
+
+func TestVarsFromInput_Sources(t *testing.T) {
+	os.Setenv("TEST_FROM_ENV", "envValue")
+	defer os.Unsetenv("TEST_FROM_ENV")
+
+	inputVars := []recipes.VariableConfig{
+		{Name: "TEST_FROM_ENV"},
+		{Name: "TEST_FROM_MANIFEST", Default: "defaultValue"},
+		{Name: "TEST_FROM_DEFAULT", Default: "defaultValue", Secret: true},
+	}
+
+	saved := types.RecipeVars{"TEST_FROM_MANIFEST": "savedValue"}
+
+	vars, err := varsFromInput(inputVars, saved, true)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(vars))
+
+	require.Equal(t, "envValue", vars[0].Value)
+	require.Equal(t, recipeVarSourceEnv, vars[0].Source)
+	require.Equal(t, "savedValue", vars[1].Value)
+	require.Equal(t, recipeVarSourceManifest, vars[1].Source)
+	require.Equal(t, "defaultValue", vars[2].Value)
+	require.Equal(t, recipeVarSourceDefault, vars[2].Source)
+	require.True(t, vars[2].Secret)
+}
+
+func TestAppendRecipeVars_SkipsDuplicates(t *testing.T) {
+	input := []recipeVar{{Name: "NEW_RELIC_API_KEY", Value: "input", Source: recipeVarSourcePrompt}}
+	profile := recipeVarsFromMap(types.RecipeVars{
+		"NEW_RELIC_API_KEY":    "profile",
+		"NEW_RELIC_ACCOUNT_ID": "12345",
+	}, recipeVarSourceProfile, secretProfileVars)
+
+	result := appendRecipeVars(input, profile)
+	require.Equal(t, 2, len(result))
+	require.Equal(t, "input", result[0].Value)
+	require.Equal(t, "NEW_RELIC_ACCOUNT_ID", result[1].Name)
+	require.False(t, result[1].Secret)
+}
+
+func TestRecipeVarsManifest_SaveAndLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "vars.yml")
+
+	m, err := loadRecipeVarsManifest(path)
+	require.NoError(t, err)
+	require.Empty(t, m)
+
+	vars := []recipeVar{
+		{Name: "DB_HOST", Value: "localhost"},
+		{Name: "DB_PASSWORD", Value: "hunter2", Secret: true},
+	}
+
+	err = m.save(path, "mysql-open-source-integration", vars)
+	require.NoError(t, err)
+
+	loaded, err := loadRecipeVarsManifest(path)
+	require.NoError(t, err)
+	require.Equal(t, "localhost", loaded["mysql-open-source-integration"]["DB_HOST"])
+
+	_, ok := loaded["mysql-open-source-integration"]["DB_PASSWORD"]
+	require.False(t, ok)
+}
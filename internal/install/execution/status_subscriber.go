@@ -17,7 +17,7 @@ type StatusSubscriber interface {
 
 // RecipeStatusEvent represents an event in a recipe's execution.
 type RecipeStatusEvent struct {
-	Recipe     types.Recipe
-	Msg        string
-	EntityGUID string
+	Recipe     types.Recipe `json:"recipe"`
+	Msg        string       `json:"msg,omitempty"`
+	EntityGUID string       `json:"entityGuid,omitempty"`
 }
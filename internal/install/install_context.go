@@ -1,10 +1,20 @@
 package install
 
+import (
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+	"github.com/newrelic/newrelic-cli/internal/install/validation"
+)
+
 // nolint: maligned
 type InstallerContext struct {
 	AssumeYes          bool
+	Hooks              *execution.InstallHooks
+	InputVarsFile      string
+	NRDBSimulator      *validation.NRDBSimulator
 	RecipeNames        []string
 	RecipePaths        []string
+	RecipeServiceURL   string
+	SendRecipeAPIKey   bool
 	SkipDiscovery      bool
 	SkipIntegrations   bool
 	SkipLoggingInstall bool
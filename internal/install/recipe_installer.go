@@ -3,10 +3,13 @@ package install
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/install/discovery"
 	"github.com/newrelic/newrelic-cli/internal/install/execution"
 	"github.com/newrelic/newrelic-cli/internal/install/recipes"
@@ -26,6 +29,7 @@ type RecipeInstaller struct {
 	InstallerContext
 	discoverer        discovery.Discoverer
 	fileFilterer      discovery.FileFilterer
+	installDetector   discovery.InstallDetector
 	recipeFetcher     recipes.RecipeFetcher
 	recipeExecutor    execution.RecipeExecutor
 	recipeValidator   validation.RecipeValidator
@@ -36,19 +40,35 @@ type RecipeInstaller struct {
 }
 
 func NewRecipeInstaller(ic InstallerContext, nrClient *newrelic.NewRelic) *RecipeInstaller {
-	rf := recipes.NewServiceRecipeFetcher(&nrClient.NerdGraph)
+	var ngc recipes.NerdGraphClient = &nrClient.NerdGraph
+	if ic.RecipeServiceURL != "" {
+		log.Debugf("Using recipe service at %s", ic.RecipeServiceURL)
+		ngc = recipes.NewGraphQLClient(ic.RecipeServiceURL, recipeServiceAPIKey(ic, credentials.DefaultProfile()))
+	}
+
+	rf := recipes.NewServiceRecipeFetcher(ngc)
 	pf := discovery.NewRegexProcessFilterer(rf)
 	ff := recipes.NewRecipeFileFetcher()
 	ers := []execution.StatusSubscriber{
 		execution.NewNerdStorageStatusReporter(&nrClient.NerdStorage),
 		execution.NewTerminalStatusReporter(),
 	}
+
+	if ic.Hooks != nil && !ic.Hooks.IsEmpty() {
+		ers = append(ers, execution.NewHookStatusReporter(ic.Hooks))
+	}
+
 	statusRollup := execution.NewInstallStatus(ers)
 
 	d := discovery.NewPSUtilDiscoverer(pf)
 	gff := discovery.NewGlobFileFilterer()
+	id := discovery.NewLocalInstallDetector()
 	re := execution.NewGoTaskRecipeExecutor()
+	re.InputVarsFile = ic.InputVarsFile
 	v := validation.NewPollingRecipeValidator(&nrClient.Nrdb)
+	if ic.NRDBSimulator != nil {
+		v = validation.NewSimulatedRecipeValidator(ic.NRDBSimulator)
+	}
 	p := ux.NewPromptUIPrompter()
 	// s := ux.NewSpinner()
 	s := ux.NewPlainProgress()
@@ -56,6 +76,7 @@ func NewRecipeInstaller(ic InstallerContext, nrClient *newrelic.NewRelic) *Recip
 	i := RecipeInstaller{
 		discoverer:        d,
 		fileFilterer:      gff,
+		installDetector:   id,
 		recipeFetcher:     rf,
 		recipeExecutor:    re,
 		recipeValidator:   v,
@@ -70,6 +91,32 @@ func NewRecipeInstaller(ic InstallerContext, nrClient *newrelic.NewRelic) *Recip
 	return &i
 }
 
+// recipeServiceAPIKey returns the API key to send to a custom recipe service.
+// The key is only sent when the user asks for it, and never to a service on
+// the local machine such as the one started by serve-recipes.
+func recipeServiceAPIKey(ic InstallerContext, profile *credentials.Profile) string {
+	if !ic.SendRecipeAPIKey || profile == nil {
+		return ""
+	}
+
+	u, err := url.Parse(ic.RecipeServiceURL)
+	if err != nil || isLoopbackHost(u.Hostname()) {
+		return ""
+	}
+
+	return profile.APIKey
+}
+
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
 // nolint:gocyclo
 func (i *RecipeInstaller) Install() error {
 	fmt.Printf(`
@@ -482,6 +529,10 @@ func (i *RecipeInstaller) executeAndValidate(m *types.DiscoveryManifest, r *type
 		return "", errors.New(msg)
 	}
 
+	return i.validate(m, r)
+}
+
+func (i *RecipeInstaller) validate(m *types.DiscoveryManifest, r *types.Recipe) (string, error) {
 	var entityGUID string
 	var err error
 	if r.ValidationNRQL != "" {
@@ -507,6 +558,15 @@ func (i *RecipeInstaller) executeAndValidate(m *types.DiscoveryManifest, r *type
 }
 
 func (i *RecipeInstaller) executeAndValidateWithProgress(m *types.DiscoveryManifest, r *types.Recipe) (string, error) {
+	skip, err := i.skipInstalledRecipe(r)
+	if err != nil {
+		return "", err
+	}
+
+	if skip {
+		return i.validateWithProgress(m, r)
+	}
+
 	if r.PreInstallMessage() != "" {
 		fmt.Println(r.PreInstallMessage())
 	}
@@ -534,6 +594,54 @@ func (i *RecipeInstaller) executeAndValidateWithProgress(m *types.DiscoveryManif
 	return entityGUID, nil
 }
 
+// validateWithProgress validates a recipe that is already installed, without
+// executing it again.
+func (i *RecipeInstaller) validateWithProgress(m *types.DiscoveryManifest, r *types.Recipe) (string, error) {
+	i.progressIndicator.Start(fmt.Sprintf("Validating %s", r.Name))
+	defer func() { i.progressIndicator.Stop() }()
+
+	entityGUID, err := i.validate(m, r)
+	if err != nil {
+		i.progressIndicator.Fail()
+		return "", err
+	}
+
+	i.progressIndicator.Success()
+	return entityGUID, nil
+}
+
+// skipInstalledRecipe determines whether a recipe that is already installed
+// should be left as it is.  Up to date recipes are always skipped, the user is
+// offered an upgrade for outdated ones.  When the installed version cannot be
+// determined the user is asked whether to reinstall, and the recipe is
+// reinstalled when assuming yes, as it always was before detection existed.
+func (i *RecipeInstaller) skipInstalledRecipe(r *types.Recipe) (bool, error) {
+	installed, err := i.installDetector.Detect(utils.SignalCtx, *r)
+	if err != nil {
+		log.Debugf("Could not detect whether %s is installed, detail: %s", r.Name, err)
+		return false, nil
+	}
+
+	if installed == nil || !installed.Installed {
+		return false, nil
+	}
+
+	if installed.UpToDate() {
+		fmt.Printf("%s is already installed and up to date (version %s).\n", r.Name, installed.Version)
+		return true, nil
+	}
+
+	if installed.UpgradeAvailable() {
+		msg := fmt.Sprintf("%s version %s is installed, version %s is available. Do you want to upgrade?",
+			r.Name, installed.Version, installed.LatestVersion)
+		upgrade, acceptErr := i.userAccepts(msg)
+		return !upgrade, acceptErr
+	}
+
+	reinstall, err := i.userAccepts(fmt.Sprintf("%s is already installed. Do you want to reinstall it?", r.Name))
+	return !reinstall, err
+}
+
 func (i *RecipeInstaller) userAccepts(msg string) (bool, error) {
 	if i.AssumeYes {
 		return true, nil
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/install/discovery"
 	"github.com/newrelic/newrelic-cli/internal/install/execution"
 	"github.com/newrelic/newrelic-cli/internal/install/recipes"
@@ -27,6 +28,7 @@ var (
 
 	d               = discovery.NewMockDiscoverer()
 	l               = discovery.NewMockFileFilterer()
+	id              = discovery.NewMockInstallDetector()
 	f               = recipes.NewMockRecipeFetcher()
 	e               = execution.NewMockRecipeExecutor()
 	v               = validation.NewMockRecipeValidator()
@@ -50,16 +52,39 @@ func TestNewRecipeInstaller_InstallerContextFields(t *testing.T) {
 		SkipLoggingInstall: true,
 	}
 
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 
 	require.True(t, reflect.DeepEqual(ic, i.InstallerContext))
 }
 
+func TestRecipeServiceAPIKey(t *testing.T) {
+	profile := &credentials.Profile{APIKey: "NRAK-TEST"}
+
+	cases := []struct {
+		url  string
+		send bool
+		key  string
+	}{
+		{"https://recipes.example.com/graphql", false, ""},
+		{"https://recipes.example.com/graphql", true, "NRAK-TEST"},
+		{"http://localhost:8080", true, ""},
+		{"http://127.0.0.1:8080", true, ""},
+		{"http://[::1]:8080", true, ""},
+	}
+
+	for _, c := range cases {
+		ic := InstallerContext{RecipeServiceURL: c.url, SendRecipeAPIKey: c.send}
+		assert.Equal(t, c.key, recipeServiceAPIKey(ic, profile), c.url)
+	}
+
+	assert.Equal(t, "", recipeServiceAPIKey(InstallerContext{RecipeServiceURL: "https://recipes.example.com", SendRecipeAPIKey: true}, nil))
+}
+
 func TestShouldGetRecipeFromURL(t *testing.T) {
 	ic := InstallerContext{}
 	ff = recipes.NewMockRecipeFileFetcher()
 	ff.FetchRecipeFileFunc = fetchRecipeFileFunc
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 
 	recipe, err := i.recipeFromPath("http://recipe/URL")
 	require.NoError(t, err)
@@ -71,7 +96,7 @@ func TestShouldGetRecipeFromFile(t *testing.T) {
 	ic := InstallerContext{}
 	ff = recipes.NewMockRecipeFileFetcher()
 	ff.LoadRecipeFileFunc = loadRecipeFileFunc
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 
 	recipe, err := i.recipeFromPath("file.txt")
 	require.NoError(t, err)
@@ -86,7 +111,7 @@ func TestInstall_Basic(t *testing.T) {
 		{Name: infraAgentRecipeName},
 		{Name: loggingRecipeName},
 	}
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 	err := i.Install()
 	require.NoError(t, err)
 	require.Equal(t, f.FetchRecipeNameCount[infraAgentRecipeName], 1)
@@ -97,7 +122,7 @@ func TestInstall_RecipesAvailable(t *testing.T) {
 	ic := InstallerContext{}
 	statusReporters = []execution.StatusSubscriber{execution.NewMockStatusReporter()}
 	status = execution.NewInstallStatus(statusReporters)
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 	err := i.Install()
 	require.NoError(t, err)
 	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).RecipesAvailableCallCount)
@@ -133,7 +158,7 @@ func TestInstall_RecipeInstalled(t *testing.T) {
 
 	v = validation.NewMockRecipeValidator()
 
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 	err := i.Install()
 	require.NoError(t, err)
 	require.Equal(t, 3, statusReporters[0].(*execution.MockStatusReporter).RecipeInstalledCallCount)
@@ -172,7 +197,7 @@ func TestInstall_RecipeFailed(t *testing.T) {
 	v = validation.NewMockRecipeValidator()
 	v.ValidateErr = errors.New("validationErr")
 
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 	err := i.Install()
 	require.Error(t, err)
 	require.Equal(t, 1, v.ValidateCallCount)
@@ -192,7 +217,7 @@ func TestInstall_InstallComplete(t *testing.T) {
 
 	v = validation.NewMockRecipeValidator()
 
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 	err := i.Install()
 	require.NoError(t, err)
 	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).InstallCompleteCallCount)
@@ -221,7 +246,7 @@ func TestInstall_InstallCompleteError(t *testing.T) {
 	v = validation.NewMockRecipeValidator()
 	v.ValidateErr = errors.New("test error")
 
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 	err := i.Install()
 	require.Error(t, err)
 	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).InstallCompleteCallCount)
@@ -257,7 +282,7 @@ func TestInstall_RecipeSkipped(t *testing.T) {
 		PromptMultiSelectAll: true,
 	}
 
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 	err := i.Install()
 	require.NoError(t, err)
 	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).RecipeSkippedCallCount)
@@ -285,7 +310,7 @@ func TestInstall_RecipeSkipped_skipping_everything(t *testing.T) {
 		PromptMultiSelectVal: []string{},
 	}
 
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 	err := i.Install()
 	require.NoError(t, err)
 	require.Equal(t, 3, statusReporters[0].(*execution.MockStatusReporter).RecipeSkippedCallCount)
@@ -313,7 +338,7 @@ func TestInstall_RecipeSkipped_multiselect(t *testing.T) {
 		PromptMultiSelectVal: []string{testRecipeName},
 	}
 
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 	err := i.Install()
 	require.NoError(t, err)
 	require.Equal(t, 2, statusReporters[0].(*execution.MockStatusReporter).RecipeSkippedCallCount)
@@ -356,7 +381,7 @@ func TestInstall_RecipeRecommended(t *testing.T) {
 		PromptMultiSelectVal: []string{testRecipeName},
 	}
 
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 	err := i.Install()
 	require.NoError(t, err)
 	// The infra agent is always installed
@@ -393,7 +418,7 @@ func TestInstallAdvancedMode_bounce_on_enter(t *testing.T) {
 		PromptYesNoVal: true,
 	}
 
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 	err := i.Install()
 	require.NoError(t, err)
 	require.Equal(t, 2, statusReporters[0].(*execution.MockStatusReporter).RecipeSkippedCallCount)
@@ -430,7 +455,7 @@ func TestInstall_RecipeSkipped_assumeYes(t *testing.T) {
 		// PromptMultiSelectAll: true,
 	}
 
-	i := RecipeInstaller{ic, d, l, f, e, v, ff, status, p, s}
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
 	err := i.Install()
 	require.NoError(t, err)
 	require.Equal(t, 0, statusReporters[0].(*execution.MockStatusReporter).RecipeSkippedCallCount)
@@ -438,6 +463,96 @@ func TestInstall_RecipeSkipped_assumeYes(t *testing.T) {
 	require.Equal(t, 3, statusReporters[0].(*execution.MockStatusReporter).RecipeInstalledCallCount)
 }
 
+func TestInstall_RecipeAlreadyInstalled_UpToDate(t *testing.T) {
+	ic := InstallerContext{
+		RecipeNames: []string{testRecipeName},
+	}
+	statusReporters = []execution.StatusSubscriber{execution.NewMockStatusReporter()}
+	status = execution.NewInstallStatus(statusReporters)
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{Name: testRecipeName, ValidationNRQL: "testNrql"},
+		{Name: infraAgentRecipeName},
+		{Name: loggingRecipeName},
+	}
+
+	id = discovery.NewMockInstallDetector()
+	id.DetectVals[testRecipeName] = &types.InstalledRecipe{
+		Installed:     true,
+		Version:       "1.2.0",
+		LatestVersion: "1.2.0",
+	}
+
+	v = validation.NewMockRecipeValidator()
+
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
+	err := i.Install()
+	require.NoError(t, err)
+	require.Equal(t, 1, v.ValidateCallCount)
+	require.Equal(t, 0, statusReporters[0].(*execution.MockStatusReporter).RecipeInstallingCallCount)
+	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).ReportInstalled[testRecipeName])
+}
+
+func TestInstall_RecipeAlreadyInstalled_UpgradeAccepted(t *testing.T) {
+	ic := InstallerContext{
+		RecipeNames: []string{testRecipeName},
+	}
+	statusReporters = []execution.StatusSubscriber{execution.NewMockStatusReporter()}
+	status = execution.NewInstallStatus(statusReporters)
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{Name: testRecipeName},
+		{Name: infraAgentRecipeName},
+		{Name: loggingRecipeName},
+	}
+
+	id = discovery.NewMockInstallDetector()
+	id.DetectVals[testRecipeName] = &types.InstalledRecipe{
+		Installed:     true,
+		Version:       "1.1.9",
+		LatestVersion: "1.2.0",
+	}
+
+	p = &ux.MockPrompter{
+		PromptYesNoVal: true,
+	}
+
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
+	err := i.Install()
+	require.NoError(t, err)
+	require.Equal(t, 1, p.PromptYesNoCallCount)
+	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).RecipeInstallingCallCount)
+}
+
+func TestInstall_RecipeAlreadyInstalled_AssumeYesReinstallsUnknownVersion(t *testing.T) {
+	ic := InstallerContext{
+		AssumeYes:   true,
+		RecipeNames: []string{testRecipeName},
+	}
+	statusReporters = []execution.StatusSubscriber{execution.NewMockStatusReporter()}
+	status = execution.NewInstallStatus(statusReporters)
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{Name: testRecipeName},
+		{Name: infraAgentRecipeName},
+		{Name: loggingRecipeName},
+	}
+
+	id = discovery.NewMockInstallDetector()
+	id.DetectVals[testRecipeName] = &types.InstalledRecipe{
+		Installed: true,
+		Files:     []string{"/etc/newrelic-infra/integrations.d/test-config.yml"},
+	}
+
+	p = &ux.MockPrompter{}
+
+	i := RecipeInstaller{ic, d, l, id, f, e, v, ff, status, p, s}
+	err := i.Install()
+	require.NoError(t, err)
+	require.Equal(t, 0, p.PromptYesNoCallCount)
+	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).RecipeInstallingCallCount)
+}
+
 func fetchRecipeFileFunc(recipeURL *url.URL) (*recipes.RecipeFile, error) {
 	return testRecipeFile, nil
 }
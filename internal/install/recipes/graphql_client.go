@@ -0,0 +1,106 @@
+package recipes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GraphQLClient is an implementation of the NerdGraphClient interface that
+// sends recipe queries to an arbitrary GraphQL endpoint, such as a staging
+// recipe service or the stand-in started by `newrelic install serve-recipes`.
+type GraphQLClient struct {
+	url        string
+	apiKey     string
+	httpClient *http.Client
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []graphQLError  `json:"errors,omitempty"`
+}
+
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+// NewGraphQLClient returns a new instance of GraphQLClient.  The API key is
+// sent in the Api-Key header when provided.
+func NewGraphQLClient(url string, apiKey string) *GraphQLClient {
+	c := GraphQLClient{
+		url:        url,
+		apiKey:     apiKey,
+		httpClient: &http.Client{},
+	}
+
+	return &c
+}
+
+// QueryWithResponseAndContext sends the query to the configured endpoint and
+// unmarshals the data of the response into respBody.
+func (c *GraphQLClient) QueryWithResponseAndContext(ctx context.Context, query string, variables map[string]interface{}, respBody interface{}) error {
+	body, err := json.Marshal(graphQLRequest{
+		Query:     query,
+		Variables: variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Api-Key", c.apiKey)
+	}
+
+	log.WithFields(log.Fields{
+		"url": c.url,
+	}).Trace("sending recipe service query")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	out, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status from recipe service %s: %s", resp.Status, strings.TrimSpace(string(out)))
+	}
+
+	var gqlResp graphQLResponse
+	if err = json.Unmarshal(out, &gqlResp); err != nil {
+		return fmt.Errorf("could not parse recipe service response: %s", err)
+	}
+
+	if len(gqlResp.Errors) > 0 {
+		messages := make([]string, len(gqlResp.Errors))
+		for i, e := range gqlResp.Errors {
+			messages[i] = e.Message
+		}
+
+		return fmt.Errorf("recipe service returned errors: %s", strings.Join(messages, "; "))
+	}
+
+	return json.Unmarshal(gqlResp.Data, respBody)
+}
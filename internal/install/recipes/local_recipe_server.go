@@ -0,0 +1,320 @@
+package recipes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+// LocalRecipeServer is an http.Handler that answers the recipe service
+// queries issued by the ServiceRecipeFetcher using recipe files read from the
+// local filesystem.  It is not a general purpose GraphQL server, requests are
+// routed by the recipe service field they query.
+type LocalRecipeServer struct {
+	recipes []types.OpenInstallationRecipe
+}
+
+// NewLocalRecipeServer returns a new instance of LocalRecipeServer serving
+// every recipe file found under the given directory.
+func NewLocalRecipeServer(dir string) (*LocalRecipeServer, error) {
+	recipes, err := loadRecipeDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := LocalRecipeServer{
+		recipes: recipes,
+	}
+
+	return &s, nil
+}
+
+// Recipes returns the recipes being served.
+func (s *LocalRecipeServer) Recipes() []types.OpenInstallationRecipe {
+	return s.recipes
+}
+
+func (s *LocalRecipeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req graphQLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeGraphQLError(w, fmt.Sprintf("could not parse request: %s", err))
+		return
+	}
+
+	var data interface{}
+	var err error
+
+	switch {
+	case strings.Contains(req.Query, "recommendations("):
+		data, err = s.recommendations(req.Variables)
+	case strings.Contains(req.Query, "recipeSearch("):
+		data, err = s.recipeSearch(req.Variables)
+	default:
+		err = fmt.Errorf("unsupported query, only recipe service queries are answered")
+	}
+
+	if err != nil {
+		writeGraphQLError(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if encodeErr := json.NewEncoder(w).Encode(map[string]interface{}{"data": data}); encodeErr != nil {
+		log.Error(encodeErr)
+	}
+}
+
+func (s *LocalRecipeServer) recommendations(variables map[string]interface{}) (*recommendationsQueryResult, error) {
+	var criteria recommendationsInput
+	if err := decodeCriteria(variables, &criteria); err != nil {
+		return nil, err
+	}
+
+	result := recommendationsQueryResult{}
+	result.Docs.OpenInstallation.Recommendations.Results = []types.OpenInstallationRecipe{}
+
+	for _, r := range s.recipes {
+		if !matchesInstallTarget(r, criteria.InstallTarget) || !matchesProcessDetails(r, criteria.ProcessDetails) {
+			continue
+		}
+
+		result.Docs.OpenInstallation.Recommendations.Results = append(result.Docs.OpenInstallation.Recommendations.Results, r)
+	}
+
+	log.WithFields(log.Fields{
+		"count": len(result.Docs.OpenInstallation.Recommendations.Results),
+	}).Info("answered recommendations query")
+
+	return &result, nil
+}
+
+func (s *LocalRecipeServer) recipeSearch(variables map[string]interface{}) (*recipeSearchQueryResult, error) {
+	var criteria recipeSearchInput
+	if err := decodeCriteria(variables, &criteria); err != nil {
+		return nil, err
+	}
+
+	result := recipeSearchQueryResult{}
+	result.Docs.OpenInstallation.RecipeSearch.Results = []types.OpenInstallationRecipe{}
+
+	for _, r := range s.recipes {
+		if criteria.Name != "" && criteria.Name != r.Name {
+			continue
+		}
+
+		if !matchesInstallTarget(r, criteria.InstallTarget) {
+			continue
+		}
+
+		result.Docs.OpenInstallation.RecipeSearch.Results = append(result.Docs.OpenInstallation.RecipeSearch.Results, r)
+	}
+
+	log.WithFields(log.Fields{
+		"name":  criteria.Name,
+		"count": len(result.Docs.OpenInstallation.RecipeSearch.Results),
+	}).Info("answered recipe search query")
+
+	return &result, nil
+}
+
+func decodeCriteria(variables map[string]interface{}, criteria interface{}) error {
+	c, ok := variables["criteria"]
+	if !ok {
+		return nil
+	}
+
+	out, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(out, criteria); err != nil {
+		return fmt.Errorf("invalid criteria: %s", err)
+	}
+
+	return nil
+}
+
+// matchesInstallTarget returns true if any of the recipe's install targets
+// matches the criteria.  Fields that are empty on either side always match,
+// and recipe values are treated as case insensitive regular expressions.
+func matchesInstallTarget(r types.OpenInstallationRecipe, t installTarget) bool {
+	if len(r.InstallTargets) == 0 {
+		return true
+	}
+
+	for _, rt := range r.InstallTargets {
+		if installTargetFieldMatches(string(rt.Type), t.Type) &&
+			installTargetFieldMatches(string(rt.Os), t.OS) &&
+			installTargetFieldMatches(string(rt.Platform), t.Platform) &&
+			installTargetFieldMatches(rt.PlatformVersion, t.PlatformVersion) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func installTargetFieldMatches(recipeValue string, value string) bool {
+	if recipeValue == "" || value == "" || strings.EqualFold(recipeValue, value) {
+		return true
+	}
+
+	re, err := regexp.Compile("(?i)^(?:" + recipeValue + ")$")
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(value)
+}
+
+// matchesProcessDetails returns true if one of the recipe's process match
+// patterns was reported as matching a running process.
+func matchesProcessDetails(r types.OpenInstallationRecipe, details []processDetailInput) bool {
+	for _, d := range details {
+		for _, pattern := range r.ProcessMatch {
+			if d.Name == pattern {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func writeGraphQLError(w http.ResponseWriter, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	resp := graphQLResponse{
+		Errors: []graphQLError{{Message: msg}},
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Error(err)
+	}
+}
+
+func loadRecipeDir(dir string) ([]types.OpenInstallationRecipe, error) {
+	info, statErr := os.Stat(dir)
+	if statErr != nil {
+		return nil, statErr
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	recipes := []types.OpenInstallationRecipe{}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		ext := filepath.Ext(path)
+		if info.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			return nil
+		}
+
+		out, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		f, err := NewRecipeFile(string(out))
+		if err != nil || f.Name == "" {
+			log.Warnf("Skipping %s, it is not a valid recipe file.", path)
+			return nil
+		}
+
+		r, err := f.toOpenInstallationRecipe(string(out))
+		if err != nil {
+			return fmt.Errorf("could not convert recipe %s: %s", path, err)
+		}
+
+		log.WithFields(log.Fields{
+			"name": f.Name,
+			"path": path,
+		}).Debug("loaded recipe")
+
+		recipes = append(recipes, *r)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return recipes, nil
+}
+
+func (f *RecipeFile) toOpenInstallationRecipe(contents string) (*types.OpenInstallationRecipe, error) {
+	install, err := yaml.Marshal(f.Install)
+	if err != nil {
+		return nil, err
+	}
+
+	r := types.OpenInstallationRecipe{
+		Description:  f.Description,
+		DisplayName:  f.DisplayName,
+		File:         contents,
+		ID:           f.Name,
+		Install:      string(install),
+		Keywords:     f.Keywords,
+		Name:         f.Name,
+		ProcessMatch: f.ProcessMatch,
+		Repository:   f.Repository,
+		PreInstall: types.OpenInstallationPreInstallConfiguration{
+			Prompt: f.PreInstall.Prompt,
+		},
+		ValidationNRQL: types.NRQL(f.ValidationNRQL),
+	}
+
+	for _, v := range f.InputVars {
+		r.InputVars = append(r.InputVars, types.OpenInstallationRecipeInputVariable{
+			Default: v.Default,
+			Name:    v.Name,
+			Prompt:  v.Prompt,
+			Secret:  v.Secret,
+		})
+	}
+
+	for _, t := range f.InstallTargets {
+		r.InstallTargets = append(r.InstallTargets, types.OpenInstallationRecipeInstallTarget{
+			KernelArch:      t.KernelArch,
+			KernelVersion:   t.KernelVersion,
+			Os:              types.OpenInstallationOperatingSystem(strings.ToUpper(t.OS)),
+			Platform:        types.OpenInstallationPlatform(strings.ToUpper(t.Platform)),
+			PlatformFamily:  types.OpenInstallationPlatformFamily(strings.ToUpper(t.PlatformFamily)),
+			PlatformVersion: t.PlatformVersion,
+			Type:            types.OpenInstallationTargetType(strings.ToUpper(t.Type)),
+		})
+	}
+
+	for _, l := range f.LogMatch {
+		r.LogMatch = append(r.LogMatch, types.OpenInstallationLogMatch{
+			Attributes: types.OpenInstallationAttributes{Logtype: l.Attributes.LogType},
+			File:       l.File,
+			Name:       l.Name,
+			Pattern:    l.Pattern,
+			Systemd:    l.Systemd,
+		})
+	}
+
+	return &r, nil
+}
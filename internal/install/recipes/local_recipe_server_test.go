@@ -0,0 +1,94 @@
+// +build unit
+
+package recipes
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+var testLocalRecipes = map[string]string{
+	"infra.yml": `
+name: infrastructure-agent-installer
+displayName: Infrastructure Agent
+installTargets:
+  - type: host
+    os: linux
+install:
+  version: "3"
+  tasks:
+    default:
+      cmds:
+        - echo installing
+`,
+	"integrations/mysql.yaml": `
+name: mysql-open-source-integration
+processMatch:
+  - mysqld
+installTargets:
+  - type: host
+    os: linux
+`,
+	"windows.yml": `
+name: windows-only
+processMatch:
+  - mysqld
+installTargets:
+  - type: host
+    os: windows
+`,
+	"notes.yml": `
+not: a recipe
+`,
+}
+
+func TestLocalRecipeServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for name, content := range testLocalRecipes {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	}
+
+	s, err := NewLocalRecipeServer(dir)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(s.Recipes()))
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	f := NewServiceRecipeFetcher(NewGraphQLClient(server.URL, "testKey"))
+	m := &types.DiscoveryManifest{
+		OS: "linux",
+		Processes: []types.MatchedProcess{
+			{MatchingPattern: "mysqld"},
+		},
+	}
+
+	r, err := f.FetchRecipe(context.Background(), m, "infrastructure-agent-installer")
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	require.Equal(t, "Infrastructure Agent", r.DisplayName)
+	require.Contains(t, r.File, "echo installing")
+
+	recommendations, err := f.FetchRecommendations(context.Background(), m)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(recommendations))
+	require.Equal(t, "mysql-open-source-integration", recommendations[0].Name)
+}
+
+func TestLocalRecipeServer_NotADirectory(t *testing.T) {
+	_, err := NewLocalRecipeServer(filepath.Join(os.TempDir(), "does-not-exist"))
+	require.Error(t, err)
+}
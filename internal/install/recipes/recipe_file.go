@@ -12,19 +12,20 @@ import (
 
 // RecipeFile represents a recipe file as defined in the Open Installation Library.
 type RecipeFile struct {
-	Description    string                 `yaml:"description"`
-	InputVars      []VariableConfig       `yaml:"inputVars"`
-	Install        map[string]interface{} `yaml:"install"`
-	InstallTargets []RecipeInstallTarget  `yaml:"installTargets"`
-	Keywords       []string               `yaml:"keywords"`
-	LogMatch       []types.LogMatch       `yaml:"logMatch"`
-	Name           string                 `yaml:"name"`
-	DisplayName    string                 `yaml:"displayName"`
-	PreInstall     RecipePreInstall       `yaml:"preInstall"`
-	PostInstall    RecipePostInstall      `yaml:"postInstall"`
-	ProcessMatch   []string               `yaml:"processMatch"`
-	Repository     string                 `yaml:"repository"`
-	ValidationNRQL string                 `yaml:"validationNrql"`
+	Description      string                 `yaml:"description"`
+	InputVars        []VariableConfig       `yaml:"inputVars"`
+	Install          map[string]interface{} `yaml:"install"`
+	InstallDetection InstallDetection       `yaml:"installDetection,omitempty"`
+	InstallTargets   []RecipeInstallTarget  `yaml:"installTargets"`
+	Keywords         []string               `yaml:"keywords"`
+	LogMatch         []types.LogMatch       `yaml:"logMatch"`
+	Name             string                 `yaml:"name"`
+	DisplayName      string                 `yaml:"displayName"`
+	PreInstall       RecipePreInstall       `yaml:"preInstall"`
+	PostInstall      RecipePostInstall      `yaml:"postInstall"`
+	ProcessMatch     []string               `yaml:"processMatch"`
+	Repository       string                 `yaml:"repository"`
+	ValidationNRQL   string                 `yaml:"validationNrql"`
 }
 
 type RecipePreInstall struct {
@@ -37,6 +38,25 @@ type RecipePostInstall struct {
 	Prompt string `yaml:"prompt"`
 }
 
+// InstallDetection describes how to detect that a recipe is already installed
+// on the underlying host, and which version of it.
+type InstallDetection struct {
+	// Files are glob patterns, any existing match signals the recipe is installed.
+	Files []string `yaml:"files"`
+	// VersionCommand is run to print the installed version.
+	VersionCommand string `yaml:"versionCommand"`
+	// VersionRegex extracts the version from the command output using its
+	// first capture group.  The whole trimmed output is used when omitted.
+	VersionRegex string `yaml:"versionRegex"`
+	// Version is the version the recipe installs.
+	Version string `yaml:"version"`
+}
+
+// IsEmpty returns true if no detection criteria were declared.
+func (d InstallDetection) IsEmpty() bool {
+	return len(d.Files) == 0 && d.VersionCommand == ""
+}
+
 type VariableConfig struct {
 	Name    string `yaml:"name"`
 	Prompt  string `yaml:"prompt"`
@@ -81,6 +81,7 @@ func (b *ScenarioBuilder) Basic() *RecipeInstaller {
 	i := RecipeInstaller{
 		discoverer:        d,
 		fileFilterer:      gff,
+		installDetector:   discovery.NewMockInstallDetector(),
 		recipeFetcher:     rf,
 		recipeExecutor:    re,
 		recipeValidator:   v,
@@ -117,6 +118,7 @@ func (b *ScenarioBuilder) Fail() *RecipeInstaller {
 	i := RecipeInstaller{
 		discoverer:        d,
 		fileFilterer:      gff,
+		installDetector:   discovery.NewMockInstallDetector(),
 		recipeFetcher:     rf,
 		recipeExecutor:    re,
 		recipeValidator:   v,
@@ -160,6 +162,7 @@ func (b *ScenarioBuilder) LogMatches() *RecipeInstaller {
 	i := RecipeInstaller{
 		discoverer:        d,
 		fileFilterer:      gff,
+		installDetector:   discovery.NewMockInstallDetector(),
 		recipeFetcher:     rf,
 		recipeExecutor:    re,
 		recipeValidator:   v,
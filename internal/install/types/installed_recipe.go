@@ -0,0 +1,98 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+)
+
+// InstalledRecipe describes the components of a recipe that were found to be
+// already installed on the underlying host.
+type InstalledRecipe struct {
+	// Installed is true if any of the recipe's components were detected.
+	Installed bool
+	// Version is the detected version of the installed component, if known.
+	Version string
+	// LatestVersion is the version the recipe installs, if the recipe declares one.
+	LatestVersion string
+	// Files are the existing files that signaled the recipe is installed.
+	Files []string
+}
+
+// UpToDate returns true if the installed version is at least the version the
+// recipe installs.
+func (r *InstalledRecipe) UpToDate() bool {
+	if !r.versionsKnown() {
+		return false
+	}
+
+	return CompareVersions(r.Version, r.LatestVersion) >= 0
+}
+
+// UpgradeAvailable returns true if the recipe installs a newer version than
+// the one that is installed.
+func (r *InstalledRecipe) UpgradeAvailable() bool {
+	if !r.versionsKnown() {
+		return false
+	}
+
+	return CompareVersions(r.Version, r.LatestVersion) < 0
+}
+
+func (r *InstalledRecipe) versionsKnown() bool {
+	return r.Installed && r.Version != "" && r.LatestVersion != ""
+}
+
+// CompareVersions compares two dotted version strings, returning -1, 0 or 1.
+// Numeric segments are compared numerically, anything else lexically.
+func CompareVersions(a string, b string) int {
+	split := func(v string) []string {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+		return strings.FieldsFunc(v, func(r rune) bool {
+			return r == '.' || r == '-' || r == '+' || r == '_'
+		})
+	}
+
+	as, bs := split(a), split(b)
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+
+		if c := compareVersionSegments(x, y); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+func compareVersionSegments(a string, b string) int {
+	x, xErr := strconv.Atoi(defaultVersionSegment(a))
+	y, yErr := strconv.Atoi(defaultVersionSegment(b))
+
+	if xErr == nil && yErr == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	return strings.Compare(a, b)
+}
+
+func defaultVersionSegment(s string) string {
+	if s == "" {
+		return "0"
+	}
+
+	return s
+}
@@ -0,0 +1,31 @@
+// +build unit
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	require.Equal(t, 0, CompareVersions("1.2.3", "1.2.3"))
+	require.Equal(t, 0, CompareVersions("v1.2", "1.2.0"))
+	require.Equal(t, -1, CompareVersions("1.2.3", "1.10.0"))
+	require.Equal(t, 1, CompareVersions("2.0.0", "1.99.99"))
+	require.Equal(t, -1, CompareVersions("1.2.3", "1.2.4-rc1"))
+}
+
+func TestInstalledRecipe_UpToDate(t *testing.T) {
+	r := InstalledRecipe{Installed: true, Version: "1.16.4", LatestVersion: "1.16.4"}
+	require.True(t, r.UpToDate())
+	require.False(t, r.UpgradeAvailable())
+
+	r.LatestVersion = "1.17.0"
+	require.False(t, r.UpToDate())
+	require.True(t, r.UpgradeAvailable())
+
+	r.LatestVersion = ""
+	require.False(t, r.UpToDate())
+	require.False(t, r.UpgradeAvailable())
+}
@@ -0,0 +1,220 @@
+package validation
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+// NRDBSimulator is a local stand-in for NRDB used to validate installs
+// without a New Relic account, e.g. in CI containers.  It stores the events it
+// receives, either over HTTP or from a file, and answers validation queries
+// with a simple NRQL evaluator.  It satisfies the same client interface as
+// NRDB, so it can be plugged into a PollingRecipeValidator.
+//
+// Only count queries are supported, with WHERE conditions using AND, OR, NOT,
+// comparison operators, LIKE, IN and IS NULL, and an optional FACET.  Time
+// window clauses are ignored.
+type NRDBSimulator struct {
+	mu     sync.RWMutex
+	events []map[string]interface{}
+}
+
+// NewNRDBSimulator returns a new instance of NRDBSimulator with no events.
+func NewNRDBSimulator() *NRDBSimulator {
+	return &NRDBSimulator{
+		events: []map[string]interface{}{},
+	}
+}
+
+// AddEvents stores the given events.  Events without an eventType attribute
+// are rejected since they could never be queried.
+func (s *NRDBSimulator) AddEvents(events ...map[string]interface{}) error {
+	for i, e := range events {
+		if t, ok := e["eventType"].(string); !ok || t == "" {
+			return fmt.Errorf("event %d has no eventType", i)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, events...)
+
+	log.WithFields(log.Fields{
+		"received": len(events),
+		"total":    len(s.events),
+	}).Debug("NRDB simulator stored events")
+
+	return nil
+}
+
+// Events returns a copy of the stored events.
+func (s *NRDBSimulator) Events() []map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]map[string]interface{}{}, s.events...)
+}
+
+// LoadFile stores the events read from the given file.  See ReadEvents for
+// the supported formats.
+func (s *NRDBSimulator) LoadFile(path string) error {
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	events, err := ReadEvents(bytes.NewReader(out))
+	if err != nil {
+		return fmt.Errorf("could not read events from %s: %s", path, err)
+	}
+
+	return s.AddEvents(events...)
+}
+
+// ServeHTTP accepts events POSTed in the same shape as the Event API: a JSON
+// array of events, a single event or newline delimited events, optionally
+// gzip encoded.  Any path is accepted so agents can be pointed at the
+// simulator without further configuration.
+func (s *NRDBSimulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+
+		body = gz
+	}
+
+	events, err := ReadEvents(body)
+	if err == nil {
+		err = s.AddEvents(events...)
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ListenAndServe accepts events on the given address until the context is
+// cancelled.
+func (s *NRDBSimulator) ListenAndServe(ctx context.Context, address string) error {
+	srv := &http.Server{
+		Addr:    address,
+		Handler: s,
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := srv.Shutdown(context.Background()); err != nil {
+			log.Error(err)
+		}
+	}()
+
+	log.Debugf("NRDB simulator listening on %s", address)
+
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
+
+// QueryWithContext evaluates the query against the stored events.  The
+// account ID is ignored.
+func (s *NRDBSimulator) QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) {
+	q, err := parseNRQL(string(query))
+	if err != nil {
+		return nil, fmt.Errorf("NRDB simulator could not evaluate query %q: %s", query, err)
+	}
+
+	results := q.evaluate(s.Events())
+
+	log.WithFields(log.Fields{
+		"query":   query,
+		"results": results,
+	}).Debug("NRDB simulator answered query")
+
+	return &nrdb.NRDBResultContainer{
+		Results: results,
+	}, nil
+}
+
+// ReadEvents reads events from a JSON array, a single JSON object or newline
+// delimited JSON objects.
+func ReadEvents(r io.Reader) ([]map[string]interface{}, error) {
+	br := bufio.NewReader(r)
+
+	first, err := peekNonSpace(br)
+	if err == io.EOF {
+		return []map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	events := []map[string]interface{}{}
+	d := json.NewDecoder(br)
+
+	if first == '[' {
+		if err = d.Decode(&events); err != nil {
+			return nil, err
+		}
+
+		return events, nil
+	}
+
+	for {
+		var e map[string]interface{}
+		if err = d.Decode(&e); err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, e)
+	}
+}
+
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			if _, err = br.ReadByte(); err != nil {
+				return 0, err
+			}
+		default:
+			return b[0], nil
+		}
+	}
+}
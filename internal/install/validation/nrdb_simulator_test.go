@@ -0,0 +1,136 @@
+// +build unit
+
+package validation
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+func TestReadEvents(t *testing.T) {
+	events, err := ReadEvents(strings.NewReader(`[{"eventType":"A"},{"eventType":"B"}]`))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(events))
+
+	events, err = ReadEvents(strings.NewReader("{\"eventType\":\"A\"}\n{\"eventType\":\"B\"}\n"))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(events))
+
+	events, err = ReadEvents(strings.NewReader("  "))
+	require.NoError(t, err)
+	require.Equal(t, 0, len(events))
+
+	_, err = ReadEvents(strings.NewReader("not json"))
+	require.Error(t, err)
+}
+
+func TestNRDBSimulator_LoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "events.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`[{"eventType":"SystemSample"},{"hostname":"missing-event-type"}]`), 0600))
+
+	s := NewNRDBSimulator()
+	require.Error(t, s.LoadFile(path))
+	require.Equal(t, 0, len(s.Events()))
+}
+
+func TestNRDBSimulator_ServeHTTP(t *testing.T) {
+	s := NewNRDBSimulator()
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/v1/accounts/1/events", "application/json", strings.NewReader(`{"eventType":"SystemSample"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err = gz.Write([]byte(`[{"eventType":"SystemSample"}]`))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, &buf)
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	resp, err = http.Post(server.URL, "application/json", strings.NewReader(`{"hostname":"missing-event-type"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	require.Equal(t, 2, len(s.Events()))
+}
+
+func TestNRDBSimulator_Validate(t *testing.T) {
+	// No profile is needed to validate against the simulator
+	credentials.SetDefaultProfile(credentials.Profile{})
+	s := NewNRDBSimulator()
+	v := NewSimulatedRecipeValidator(s)
+	v.maxAttempts = 5
+	v.interval = 10 * time.Millisecond
+
+	r := types.Recipe{
+		ValidationNRQL: "SELECT count(*) FROM SystemSample WHERE hostname like '{{.HOSTNAME}}%' FACET entityGuid SINCE 10 minutes ago",
+	}
+	m := types.DiscoveryManifest{
+		Hostname: "web-01",
+	}
+
+	_, err := v.Validate(getTestContext(), m, r)
+	require.Error(t, err)
+
+	require.NoError(t, s.AddEvents(map[string]interface{}{
+		"eventType":  "SystemSample",
+		"hostname":   "web-01.example.com",
+		"entityGuid": "testGUID",
+	}))
+
+	entityGUID, err := v.Validate(getTestContext(), m, r)
+	require.NoError(t, err)
+	require.Equal(t, "testGUID", entityGUID)
+}
+
+func TestNRDBSimulator_ValidateWithoutFacetAttribute(t *testing.T) {
+	credentials.SetDefaultProfile(credentials.Profile{})
+	s := NewNRDBSimulator()
+	v := NewSimulatedRecipeValidator(s)
+	v.maxAttempts = 2
+	v.interval = 10 * time.Millisecond
+
+	r := types.Recipe{
+		ValidationNRQL: "SELECT count(*) FROM SystemSample WHERE hostname like '{{.HOSTNAME}}%' FACET entityGuid SINCE 10 minutes ago",
+	}
+	m := types.DiscoveryManifest{
+		Hostname: "web-01",
+	}
+
+	// Events without the faceted attribute are not counted, as in NRDB
+	require.NoError(t, s.AddEvents(map[string]interface{}{
+		"eventType": "SystemSample",
+		"hostname":  "web-01.example.com",
+	}))
+
+	_, err := v.Validate(getTestContext(), m, r)
+	require.Error(t, err)
+}
@@ -0,0 +1,605 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/newrelic/newrelic-cli/internal/nrql/parser"
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+// nrqlQuery is the subset of a NRQL query understood by the NRDB simulator:
+// a count aggregate over one or more event types, filtered by an optional
+// WHERE clause and optionally faceted by attributes.  Time window and
+// presentation clauses such as SINCE, UNTIL, LIMIT and TIMESERIES are
+// accepted but ignored, every stored event is considered.
+type nrqlQuery struct {
+	countAttribute string
+	eventTypes     []string
+	where          nrqlCondition
+	facets         []string
+}
+
+// nrqlCondition is a node of a parsed WHERE clause.
+type nrqlCondition interface {
+	matches(event map[string]interface{}) bool
+}
+
+// nrqlParser parses the tokens of a single clause of a query.
+type nrqlParser struct {
+	clause string
+	tokens []parser.Token
+	pos    int
+}
+
+// parseNRQL parses a query into the subset of NRQL supported by the simulator.
+func parseNRQL(query string) (*nrqlQuery, error) {
+	parsed, err := parser.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	sel := parsed.Clause("SELECT")
+	from := parsed.Clause("FROM")
+	if sel == nil || from == nil {
+		return nil, fmt.Errorf("expected a SELECT and a FROM clause")
+	}
+
+	q := nrqlQuery{}
+
+	p := newNRQLParser(sel)
+	if q.countAttribute, err = p.parseCount(); err != nil {
+		return nil, err
+	}
+
+	if err = p.end(); err != nil {
+		return nil, err
+	}
+
+	p = newNRQLParser(from)
+	if q.eventTypes, err = p.parseIdentList(); err != nil {
+		return nil, err
+	}
+
+	if err = p.end(); err != nil {
+		return nil, err
+	}
+
+	if where := parsed.Clause("WHERE"); where != nil {
+		p = newNRQLParser(where)
+		if q.where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+
+		if err = p.end(); err != nil {
+			return nil, err
+		}
+	}
+
+	if facet := parsed.Clause("FACET"); facet != nil {
+		p = newNRQLParser(facet)
+		if q.facets, err = p.parseIdentList(); err != nil {
+			return nil, err
+		}
+
+		if err = p.end(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &q, nil
+}
+
+func newNRQLParser(c *parser.Clause) *nrqlParser {
+	return &nrqlParser{clause: c.Name, tokens: c.Tokens}
+}
+
+func (p *nrqlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *nrqlParser) peek() parser.Token {
+	if p.done() {
+		return parser.Token{}
+	}
+
+	return p.tokens[p.pos]
+}
+
+func (p *nrqlParser) next() parser.Token {
+	t := p.peek()
+	p.pos++
+
+	return t
+}
+
+func (p *nrqlParser) accept(keyword string) bool {
+	if t := p.peek(); t.Is(keyword) || t.IsSymbol(keyword) {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *nrqlParser) expect(keyword string) error {
+	if p.done() {
+		return fmt.Errorf("expected %s but reached the end of the %s clause", keyword, p.clause)
+	}
+
+	if !p.accept(keyword) {
+		return fmt.Errorf("expected %s but found %q", keyword, p.peek().Text)
+	}
+
+	return nil
+}
+
+// end returns an error if any tokens of the clause were not parsed.
+func (p *nrqlParser) end() error {
+	if !p.done() {
+		return fmt.Errorf("unexpected %q in %s clause", p.peek().Text, p.clause)
+	}
+
+	return nil
+}
+
+func (p *nrqlParser) ident() (string, error) {
+	t := p.next()
+	if t.Kind != parser.Word && t.Kind != parser.Identifier {
+		return "", fmt.Errorf("expected an attribute or event type but found %q", t.Text)
+	}
+
+	return t.Value(), nil
+}
+
+// parseCount parses count(*) or count(attribute), returning the counted
+// attribute or an empty string for count(*).
+func (p *nrqlParser) parseCount() (string, error) {
+	if err := p.expect("count"); err != nil {
+		return "", fmt.Errorf("only the count aggregate function is supported: %s", err)
+	}
+
+	if err := p.expect("("); err != nil {
+		return "", err
+	}
+
+	var attribute string
+	if !p.accept("*") {
+		var err error
+		if attribute, err = p.ident(); err != nil {
+			return "", err
+		}
+	}
+
+	if err := p.expect(")"); err != nil {
+		return "", err
+	}
+
+	if p.accept("AS") {
+		p.next()
+	}
+
+	return attribute, nil
+}
+
+func (p *nrqlParser) parseIdentList() ([]string, error) {
+	idents := []string{}
+
+	for {
+		i, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+
+		idents = append(idents, i)
+
+		if !p.accept(",") {
+			return idents, nil
+		}
+	}
+}
+
+func (p *nrqlParser) parseOr() (nrqlCondition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("OR") {
+		var right nrqlCondition
+		if right, err = p.parseAnd(); err != nil {
+			return nil, err
+		}
+
+		left = nrqlOr{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *nrqlParser) parseAnd() (nrqlCondition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("AND") {
+		var right nrqlCondition
+		if right, err = p.parseNot(); err != nil {
+			return nil, err
+		}
+
+		left = nrqlAnd{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *nrqlParser) parseNot() (nrqlCondition, error) {
+	if p.accept("NOT") {
+		c, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return nrqlNot{c}, nil
+	}
+
+	if p.accept("(") {
+		c, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if err = p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		return c, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *nrqlParser) parseComparison() (nrqlCondition, error) {
+	attribute, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.accept("IS") {
+		negate := p.accept("NOT")
+		if err = p.expect("NULL"); err != nil {
+			return nil, err
+		}
+
+		return nrqlIsNull{attribute: attribute, negate: negate}, nil
+	}
+
+	negate := p.accept("NOT")
+
+	switch {
+	case p.accept("LIKE"):
+		t := p.next()
+		if t.Kind != parser.String {
+			return nil, fmt.Errorf("expected a string pattern after LIKE but found %q", t.Text)
+		}
+
+		return nrqlLike{attribute: attribute, pattern: likePattern(t.Value()), negate: negate}, nil
+	case p.accept("IN"):
+		var values []interface{}
+		if values, err = p.parseValueList(); err != nil {
+			return nil, err
+		}
+
+		return nrqlIn{attribute: attribute, values: values, negate: negate}, nil
+	case negate:
+		return nil, fmt.Errorf("expected LIKE or IN after NOT but found %q", p.peek().Text)
+	}
+
+	op := p.next()
+	switch op.Text {
+	case "=", "!=", "<>", "<", ">", "<=", ">=":
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", op.Text)
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return nrqlCompare{attribute: attribute, op: op.Text, value: value}, nil
+}
+
+func (p *nrqlParser) parseValue() (interface{}, error) {
+	sign := ""
+	if p.accept("-") {
+		sign = "-"
+	}
+
+	t := p.next()
+
+	switch {
+	case t.Kind == parser.Number:
+		return strconv.ParseFloat(sign+t.Text, 64)
+	case sign != "":
+		return nil, fmt.Errorf("expected a number after - but found %q", t.Text)
+	case t.Kind == parser.String:
+		return t.Value(), nil
+	case t.Is("true"):
+		return true, nil
+	case t.Is("false"):
+		return false, nil
+	}
+
+	return nil, fmt.Errorf("expected a value but found %q", t.Text)
+}
+
+func (p *nrqlParser) parseValueList() ([]interface{}, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	values := []interface{}{}
+
+	for {
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, v)
+
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// likePattern converts a NRQL LIKE pattern into a case insensitive regular
+// expression where % matches any sequence of characters.
+func likePattern(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "%")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	return regexp.MustCompile("(?is)^" + strings.Join(parts, ".*") + "$")
+}
+
+type nrqlAnd struct {
+	left, right nrqlCondition
+}
+
+func (c nrqlAnd) matches(event map[string]interface{}) bool {
+	return c.left.matches(event) && c.right.matches(event)
+}
+
+type nrqlOr struct {
+	left, right nrqlCondition
+}
+
+func (c nrqlOr) matches(event map[string]interface{}) bool {
+	return c.left.matches(event) || c.right.matches(event)
+}
+
+type nrqlNot struct {
+	condition nrqlCondition
+}
+
+func (c nrqlNot) matches(event map[string]interface{}) bool {
+	return !c.condition.matches(event)
+}
+
+type nrqlIsNull struct {
+	attribute string
+	negate    bool
+}
+
+func (c nrqlIsNull) matches(event map[string]interface{}) bool {
+	v, ok := event[c.attribute]
+	isNull := !ok || v == nil
+
+	return isNull != c.negate
+}
+
+type nrqlLike struct {
+	attribute string
+	pattern   *regexp.Regexp
+	negate    bool
+}
+
+func (c nrqlLike) matches(event map[string]interface{}) bool {
+	v, ok := event[c.attribute]
+	if !ok || v == nil {
+		return false
+	}
+
+	return c.pattern.MatchString(fmt.Sprint(v)) != c.negate
+}
+
+type nrqlIn struct {
+	attribute string
+	values    []interface{}
+	negate    bool
+}
+
+func (c nrqlIn) matches(event map[string]interface{}) bool {
+	v, ok := event[c.attribute]
+	if !ok || v == nil {
+		return false
+	}
+
+	for _, value := range c.values {
+		if cmp, comparable := compareNRQLValues(v, value); comparable && cmp == 0 {
+			return !c.negate
+		}
+	}
+
+	return c.negate
+}
+
+type nrqlCompare struct {
+	attribute string
+	op        string
+	value     interface{}
+}
+
+func (c nrqlCompare) matches(event map[string]interface{}) bool {
+	v, ok := event[c.attribute]
+	if !ok || v == nil {
+		return false
+	}
+
+	cmp, comparable := compareNRQLValues(v, c.value)
+	if !comparable {
+		return c.op == "!=" || c.op == "<>"
+	}
+
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=", "<>":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case ">=":
+		return cmp >= 0
+	}
+
+	return false
+}
+
+// compareNRQLValues compares an event attribute with a query value, returning
+// false if the two cannot be compared.  Numbers are compared numerically,
+// anything else by its string representation.
+func compareNRQLValues(attribute interface{}, value interface{}) (int, bool) {
+	if n, ok := value.(float64); ok {
+		a, err := strconv.ParseFloat(fmt.Sprint(attribute), 64)
+		if err != nil {
+			return 0, false
+		}
+
+		switch {
+		case a < n:
+			return -1, true
+		case a > n:
+			return 1, true
+		}
+
+		return 0, true
+	}
+
+	return strings.Compare(fmt.Sprint(attribute), fmt.Sprint(value)), true
+}
+
+// evaluate runs the query against the given events, returning results shaped
+// like the ones returned by NRDB: a single result holding the count, or one
+// result per facet ordered by descending count.
+func (q *nrqlQuery) evaluate(events []map[string]interface{}) []nrdb.NRDBResult {
+	type facetCount struct {
+		values []interface{}
+		count  float64
+	}
+
+	counts := map[string]*facetCount{}
+	order := []string{}
+
+	for _, e := range events {
+		if !q.matches(e) {
+			continue
+		}
+
+		// Like NRDB, events without a value for a faceted attribute are left out
+		values := make([]interface{}, len(q.facets))
+		missing := false
+		for i, f := range q.facets {
+			values[i] = e[f]
+			missing = missing || values[i] == nil
+		}
+
+		if missing {
+			continue
+		}
+
+		key := fmt.Sprint(values...)
+		if _, ok := counts[key]; !ok {
+			counts[key] = &facetCount{values: values}
+			order = append(order, key)
+		}
+
+		counts[key].count++
+	}
+
+	if len(q.facets) == 0 {
+		var count float64
+		if c, ok := counts[""]; ok {
+			count = c.count
+		}
+
+		return []nrdb.NRDBResult{{"count": count}}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return counts[order[i]].count > counts[order[j]].count
+	})
+
+	results := []nrdb.NRDBResult{}
+	for _, key := range order {
+		c := counts[key]
+		r := nrdb.NRDBResult{"count": c.count}
+
+		for i, f := range q.facets {
+			r[f] = c.values[i]
+		}
+
+		if len(c.values) == 1 {
+			r["facet"] = c.values[0]
+		} else {
+			r["facet"] = c.values
+		}
+
+		results = append(results, r)
+	}
+
+	return results
+}
+
+func (q *nrqlQuery) matches(event map[string]interface{}) bool {
+	eventType, _ := event["eventType"].(string)
+	if !containsFold(q.eventTypes, eventType) {
+		return false
+	}
+
+	if q.countAttribute != "" {
+		if v, ok := event[q.countAttribute]; !ok || v == nil {
+			return false
+		}
+	}
+
+	return q.where == nil || q.where.matches(event)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,89 @@
+// +build unit
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testEvents = []map[string]interface{}{
+	{"eventType": "SystemSample", "hostname": "web-01.example.com", "entityGuid": "guid-1", "cpuPercent": 12.5},
+	{"eventType": "SystemSample", "hostname": "web-01.example.com", "entityGuid": "guid-1", "cpuPercent": 80.0},
+	{"eventType": "SystemSample", "hostname": "db-01.example.com", "entityGuid": "guid-2", "cpuPercent": 40.0},
+	{"eventType": "MysqlSample", "hostname": "db-01.example.com", "entityGuid": "guid-3"},
+	{"eventType": "Log", "hostname": "web-01.example.com", "entity.guids": "guid-1", "logtype": "nginx"},
+}
+
+func TestParseNRQL_Evaluate(t *testing.T) {
+	tests := []struct {
+		query string
+		count float64
+	}{
+		{"SELECT count(*) FROM SystemSample", 3},
+		{"select COUNT(*) from SystemSample, MysqlSample since 10 minutes ago", 4},
+		{"SELECT count(*) FROM SystemSample WHERE hostname LIKE 'web-01%' SINCE 10 minutes ago LIMIT 1", 2},
+		{"SELECT count(*) FROM SystemSample WHERE hostname NOT LIKE 'WEB%'", 1},
+		{"SELECT count(*) FROM SystemSample WHERE cpuPercent >= 40 AND hostname = 'web-01.example.com'", 1},
+		{"SELECT count(*) FROM SystemSample WHERE (cpuPercent > 50 OR hostname = 'db-01.example.com') AND NOT entityGuid = 'guid-3'", 2},
+		{"SELECT count(*) FROM SystemSample WHERE entityGuid IN ('guid-2', 'guid-3')", 1},
+		{"SELECT count(*) FROM SystemSample WHERE entityGuid NOT IN ('guid-2')", 2},
+		{"SELECT count(*) FROM MysqlSample WHERE cpuPercent IS NULL", 1},
+		{"SELECT count(*) FROM Log WHERE `entity.guids` IS NOT NULL AND logtype != 'syslog'", 1},
+		{"SELECT count(cpuPercent) FROM SystemSample, MysqlSample", 3},
+		{"SELECT count(*) FROM ContainerSample", 0},
+		{"SELECT count(*) FROM SystemSample WHERE cpuPercent > -1 // comment", 3},
+	}
+
+	for _, tt := range tests {
+		q, err := parseNRQL(tt.query)
+		require.NoError(t, err, tt.query)
+
+		results := q.evaluate(testEvents)
+		require.Equal(t, 1, len(results), tt.query)
+		require.Equal(t, tt.count, results[0]["count"], tt.query)
+	}
+}
+
+func TestParseNRQL_Facet(t *testing.T) {
+	q, err := parseNRQL("SELECT count(*) FROM SystemSample WHERE hostname like '%.example.com' FACET entityGuid SINCE 1 hour ago")
+	require.NoError(t, err)
+
+	results := q.evaluate(testEvents)
+	require.Equal(t, 2, len(results))
+	require.Equal(t, 2.0, results[0]["count"])
+	require.Equal(t, "guid-1", results[0]["entityGuid"])
+	require.Equal(t, "guid-1", results[0]["facet"])
+	require.Equal(t, "guid-2", results[1]["entityGuid"])
+
+	q, err = parseNRQL("SELECT count(*) FROM MysqlSample, Log FACET entityGuid")
+	require.NoError(t, err)
+
+	results = q.evaluate(testEvents)
+	require.Equal(t, 1, len(results))
+	require.Equal(t, "guid-3", results[0]["entityGuid"])
+
+	q, err = parseNRQL("SELECT count(*) FROM ContainerSample FACET entityGuid")
+	require.NoError(t, err)
+	require.Equal(t, 0, len(q.evaluate(testEvents)))
+}
+
+func TestParseNRQL_Unsupported(t *testing.T) {
+	queries := []string{
+		"",
+		"SELECT average(cpuPercent) FROM SystemSample",
+		"SELECT count(*) SystemSample",
+		"SELECT count(*) FROM SystemSample WHERE hostname",
+		"SELECT count(*) FROM SystemSample WHERE hostname = 'unterminated",
+		"SELECT count(*) FROM SystemSample WHERE hostname ~ 'web'",
+		"SELECT count(*) FROM SystemSample WHERE hostname = 'a' 'b'",
+		"SELECT count(*) FROM SystemSample FACET hostname, count(*)",
+		"SHOW EVENT TYPES",
+	}
+
+	for _, query := range queries {
+		_, err := parseNRQL(query)
+		require.Error(t, err, query)
+	}
+}
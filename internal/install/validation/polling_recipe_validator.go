@@ -29,19 +29,32 @@ type PollingRecipeValidator struct {
 	maxAttempts int
 	interval    time.Duration
 	client      nrdbClient
+	accountID   func() (int, error)
 }
 
 // NewPollingRecipeValidator returns a new instance of PollingRecipeValidator.
+// Queries are run against the account of the default profile.
 func NewPollingRecipeValidator(c nrdbClient) *PollingRecipeValidator {
 	v := PollingRecipeValidator{
 		maxAttempts: defaultMaxAttempts,
 		interval:    defaultInterval,
 		client:      c,
+		accountID:   defaultProfileAccountID,
 	}
 
 	return &v
 }
 
+// NewSimulatedRecipeValidator returns a PollingRecipeValidator that polls an
+// NRDBSimulator.  The simulator holds the events of a single host, so no
+// account is needed and the default profile is not consulted.
+func NewSimulatedRecipeValidator(s *NRDBSimulator) *PollingRecipeValidator {
+	v := NewPollingRecipeValidator(s)
+	v.accountID = func() (int, error) { return 0, nil }
+
+	return v
+}
+
 // Validate polls NRDB to assert data is being reported for the given recipe.
 func (m *PollingRecipeValidator) Validate(ctx context.Context, dm types.DiscoveryManifest, r types.Recipe) (string, error) {
 	return m.waitForData(ctx, dm, r)
@@ -102,14 +115,14 @@ func (m *PollingRecipeValidator) tryValidate(ctx context.Context, dm types.Disco
 		// optionally use a facet over entityGuid.  The standard case seems to be
 		// that all entities contain a facet of "entityGuid", and so if we find it
 		// here, we return it.
-		if entityGUID, ok := results[0]["entityGuid"]; ok {
-			return true, entityGUID.(string), nil
+		if entityGUID, ok := results[0]["entityGuid"].(string); ok {
+			return true, entityGUID, nil
 		}
 
 		// In the logs integration, the facet doesn't contain "entityGuid", but
 		// does contain, "entity.guid", so here we check for that also.
-		if entityGUID, ok := results[0]["entity.guids"]; ok {
-			return true, entityGUID.(string), nil
+		if entityGUID, ok := results[0]["entity.guids"].(string); ok {
+			return true, entityGUID, nil
 		}
 
 		return true, "", nil
@@ -139,17 +152,26 @@ func substituteHostname(dm types.DiscoveryManifest, r types.Recipe) (string, err
 }
 
 func (m *PollingRecipeValidator) executeQuery(ctx context.Context, query string) ([]nrdb.NRDBResult, error) {
-	profile := credentials.DefaultProfile()
-	if profile == nil || profile.AccountID == 0 {
-		return nil, errors.New("no account ID found in default profile")
+	accountID, err := m.accountID()
+	if err != nil {
+		return nil, err
 	}
 
 	nrql := nrdb.NRQL(query)
 
-	result, err := m.client.QueryWithContext(ctx, profile.AccountID, nrql)
+	result, err := m.client.QueryWithContext(ctx, accountID, nrql)
 	if err != nil {
 		return nil, err
 	}
 
 	return result.Results, nil
 }
+
+func defaultProfileAccountID() (int, error) {
+	profile := credentials.DefaultProfile()
+	if profile == nil || profile.AccountID == 0 {
+		return 0, errors.New("no account ID found in default profile")
+	}
+
+	return profile.AccountID, nil
+}
@@ -0,0 +1,76 @@
+package nerdgraph
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+)
+
+var cmdExplore = &cobra.Command{
+	Use:   "explore",
+	Short: "Build a NerdGraph query interactively",
+	Long: `Build a NerdGraph query interactively
+
+The explore command walks the NerdGraph schema from the actor field, asking which
+fields to select and what to pass as their arguments.  Fields are toggled on and
+off by selecting them, and fields with subfields are opened to select from those.
+Once the query is built, it can be run, and is printed for reuse in scripts.
+
+The cached schema is used if there is one, otherwise the schema is fetched and
+cached first.
+`,
+	Example: `newrelic nerdgraph explore`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			log.Fatal("the explore command needs an interactive terminal")
+		}
+
+		s, err := loadSchema(defaultSchemaPath())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if s == nil {
+			client.WithClient(func(nrClient *newrelic.NewRelic) {
+				log.Info("fetching the NerdGraph schema")
+
+				s, err = fetchSchema(utils.SignalCtx, &nrClient.NerdGraph)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				utils.LogIfError(saveSchema(defaultSchemaPath(), s))
+			})
+		}
+
+		prompter := &surveyPrompter{}
+		e := &explorer{schema: s, prompter: prompter}
+
+		query, err := e.explore()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		run, err := prompter.Confirm("Run the query?")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if run {
+			runQuery(query, map[string]interface{}{})
+		}
+
+		fmt.Println(query)
+	},
+}
+
+func init() {
+	Command.AddCommand(cmdExplore)
+}
@@ -0,0 +1,18 @@
+// +build unit
+
+package nerdgraph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+)
+
+func TestExplore(t *testing.T) {
+	assert.Equal(t, "explore", cmdExplore.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdExplore)
+	testcobra.CheckCobraRequiredFlags(t, cmdExplore, []string{})
+}
@@ -0,0 +1,147 @@
+package nerdgraph
+
+import (
+	"errors"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+)
+
+// libraryDirectory is where saved queries are kept, within the CLI
+// configuration directory.
+const libraryDirectory = "nerdgraph"
+
+var runVars []string
+
+var cmdSave = &cobra.Command{
+	Use:   "save",
+	Short: "Save a GraphQL query to the local query library",
+	Long: `Save a GraphQL query to the local query library
+
+The save command stores a GraphQL query under a name in the CLI configuration
+directory, so it can be run later with the run command.  The query is given as an
+argument after the name or read from a file with the --file flag.  Variables given
+with --variables or --variables-file are saved as the query's defaults.  Saving a
+query with an existing name replaces it.
+`,
+	Example: `newrelic nerdgraph save entity --file entity.graphql --variables '{"guid": "<GUID>"}'
+newrelic nerdgraph save user 'query { actor { user { name email } } }'`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("missing query name argument")
+		}
+
+		if len(args) == 1 && queryFile == "" {
+			return errors.New("missing graph query argument")
+		}
+
+		if len(args) > 1 && queryFile != "" {
+			return errors.New("the graph query argument cannot be used with --file")
+		}
+
+		if len(args) > 2 {
+			return errors.New("command expects at most 2 arguments")
+		}
+
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		query, err := readQuery(args[1:])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		vars, err := loadVariables()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = defaultLibrary().save(savedQuery{
+			Name:      args[0],
+			Query:     query,
+			Variables: vars,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Infof("saved query %s", args[0])
+	},
+}
+
+var cmdRun = &cobra.Command{
+	Use:   "run",
+	Short: "Run a GraphQL query from the local query library",
+	Long: `Run a GraphQL query from the local query library
+
+The run command executes a query saved with the save command.  The query's saved
+variables can be overridden with --variables-file, --variables and --var key=value,
+in increasing order of precedence.  Values given with --var are decoded as JSON
+when they are valid JSON, such as numbers and booleans, and are strings otherwise.
+`,
+	Example: `newrelic nerdgraph run entity --var guid=<GUID>`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		q, err := defaultLibrary().load(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		given, err := loadVariables()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		vars, err := parseVars(runVars)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		runQuery(q.Query, mergeVariables(q.Variables, given, vars))
+	},
+}
+
+var cmdList = &cobra.Command{
+	Use:   "list",
+	Short: "List the GraphQL queries in the local query library",
+	Long: `List the GraphQL queries in the local query library
+
+The list command prints the queries saved with the save command, along with their
+saved variables.
+`,
+	Example: `newrelic nerdgraph list`,
+	Run: func(cmd *cobra.Command, args []string) {
+		queries, err := defaultLibrary().list()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		utils.LogIfFatal(output.Print(queries))
+	},
+}
+
+func defaultLibrary() *queryLibrary {
+	return newQueryLibrary(filepath.Join(config.DefaultConfigDirectory, libraryDirectory))
+}
+
+func init() {
+	Command.AddCommand(cmdSave)
+	cmdSave.Flags().StringVarP(&queryFile, "file", "f", "", "a file containing the GraphQL query")
+	cmdSave.Flags().StringVar(&variables, "variables", "{}", "default variables for the query, represented as a JSON string")
+	cmdSave.Flags().StringVar(&variablesFile, "variables-file", "", "a JSON file of default variables for the query")
+
+	Command.AddCommand(cmdRun)
+	cmdRun.Flags().StringVar(&variables, "variables", "{}", "the variables to pass to the GraphQL query, represented as a JSON string")
+	cmdRun.Flags().StringVar(&variablesFile, "variables-file", "", "a JSON file of variables to pass to the GraphQL query")
+	cmdRun.Flags().StringArrayVar(&runVars, "var", []string{}, "a variable to pass to the GraphQL query, as key=value")
+	cmdRun.Flags().BoolVar(&skipValidation, "skip-validation", false, "send the query without checking it against the cached schema")
+	cmdRun.Flags().BoolVar(&paginateQuery, "paginate", false, "follow nextCursor to fetch every page of results and merge them")
+	cmdRun.Flags().StringVar(&cursorVariable, "cursor-variable", defaultCursorVariable, "the query variable to pass the cursor in when paginating")
+
+	Command.AddCommand(cmdList)
+}
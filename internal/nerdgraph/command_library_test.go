@@ -0,0 +1,32 @@
+// +build unit
+
+package nerdgraph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+)
+
+func TestSave(t *testing.T) {
+	assert.Equal(t, "save", cmdSave.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdSave)
+	testcobra.CheckCobraRequiredFlags(t, cmdSave, []string{})
+}
+
+func TestRun(t *testing.T) {
+	assert.Equal(t, "run", cmdRun.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdRun)
+	testcobra.CheckCobraRequiredFlags(t, cmdRun, []string{})
+}
+
+func TestList(t *testing.T) {
+	assert.Equal(t, "list", cmdList.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdList)
+	testcobra.CheckCobraRequiredFlags(t, cmdList, []string{})
+}
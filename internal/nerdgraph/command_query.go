@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"io/ioutil"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -16,7 +17,12 @@ import (
 )
 
 var (
-	variables string
+	cursorVariable string
+	paginateQuery  bool
+	queryFile      string
+	skipValidation bool
+	variables      string
+	variablesFile  string
 )
 
 var cmdQuery = &cobra.Command{
@@ -24,18 +30,33 @@ var cmdQuery = &cobra.Command{
 	Short: "Execute a raw GraphQL query request to the NerdGraph API",
 	Long: `Execute a raw GraphQL query request to the NerdGraph API
 
-The query command accepts a single argument in the form of a GraphQL query as a string.
+The query command accepts a single argument in the form of a GraphQL query as a string,
+or reads the query from a file given with the --file flag.
 This command accepts an optional flag, --variables, which should be a JSON string where the
-keys are the variables to be referenced in the GraphQL query.
+keys are the variables to be referenced in the GraphQL query.  Variables can also be read
+from a JSON file with the --variables-file flag, with --variables taking precedence.
+
+If the schema has been cached with the schema fetch command, the query is checked
+against it before it is sent.  Use --skip-validation to send it regardless.
+
+With --paginate, the query is run again for as long as the response has a nextCursor,
+passing it back in the $cursor variable (see --cursor-variable), and the results of
+each page are merged into a single response.
 `,
-	Example: `newrelic nerdgraph query 'query($guid: EntityGuid!) { actor { entity(guid: $guid) { guid name domain entityType } } }' --variables '{"guid": "<GUID>"}'`,
+	Example: `newrelic nerdgraph query 'query($guid: EntityGuid!) { actor { entity(guid: $guid) { guid name domain entityType } } }' --variables '{"guid": "<GUID>"}'
+newrelic nerdgraph query --file entity.graphql --variables-file vars.json
+newrelic nerdgraph query --paginate --file search.graphql`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		argsCount := len(args)
 
-		if argsCount < 1 {
+		if argsCount < 1 && queryFile == "" {
 			return errors.New("missing graph query argument")
 		}
 
+		if argsCount > 0 && queryFile != "" {
+			return errors.New("the graph query argument cannot be used with --file")
+		}
+
 		if argsCount > 1 {
 			return errors.New("command expects only 1 argument")
 		}
@@ -43,35 +64,73 @@ keys are the variables to be referenced in the GraphQL query.
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		client.WithClient(func(nrClient *newrelic.NewRelic) {
-			var variablesParsed map[string]interface{}
+		query, err := readQuery(args)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		variablesParsed, err := loadVariables()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		runQuery(query, variablesParsed)
+	},
+}
 
-			err := json.Unmarshal([]byte(variables), &variablesParsed)
-			if err != nil {
-				log.Fatal(err)
-			}
+// readQuery returns the query given as an argument, or read from --file.
+func readQuery(args []string) (string, error) {
+	if queryFile == "" {
+		return args[0], nil
+	}
 
-			query := args[0]
+	out, err := ioutil.ReadFile(queryFile)
+	if err != nil {
+		return "", err
+	}
 
-			result, err := nrClient.NerdGraph.Query(query, variablesParsed)
-			if err != nil {
-				log.Fatal(err)
-			}
+	return string(out), nil
+}
 
-			reqBodyBytes := new(bytes.Buffer)
+// runQuery executes a query and prints the response.
+func runQuery(query string, variablesParsed map[string]interface{}) {
+	if !skipValidation {
+		if err := validateWithCachedSchema(query); err != nil {
+			log.Fatal(err)
+		}
+	}
 
-			encoder := json.NewEncoder(reqBodyBytes)
-			err = encoder.Encode(ng.QueryResponse{
-				Actor: result.(ng.QueryResponse).Actor,
-			})
+	client.WithClient(func(nrClient *newrelic.NewRelic) {
+		if paginateQuery {
+			merged, err := paginate(utils.SignalCtx, &nrClient.NerdGraph, query, variablesParsed, cursorVariable)
 			utils.LogIfFatal(err)
+			utils.LogIfFatal(output.Print(merged))
+			return
+		}
 
-			utils.LogIfFatal(output.Print(reqBodyBytes))
+		result, err := nrClient.NerdGraph.Query(query, variablesParsed)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		reqBodyBytes := new(bytes.Buffer)
+
+		encoder := json.NewEncoder(reqBodyBytes)
+		err = encoder.Encode(ng.QueryResponse{
+			Actor: result.(ng.QueryResponse).Actor,
 		})
-	},
+		utils.LogIfFatal(err)
+
+		utils.LogIfFatal(output.Print(reqBodyBytes))
+	})
 }
 
 func init() {
 	Command.AddCommand(cmdQuery)
+	cmdQuery.Flags().StringVarP(&queryFile, "file", "f", "", "a file containing the GraphQL query")
 	cmdQuery.Flags().StringVar(&variables, "variables", "{}", "the variables to pass to the GraphQL query, represented as a JSON string")
+	cmdQuery.Flags().StringVar(&variablesFile, "variables-file", "", "a JSON file of variables to pass to the GraphQL query")
+	cmdQuery.Flags().BoolVar(&skipValidation, "skip-validation", false, "send the query without checking it against the cached schema")
+	cmdQuery.Flags().BoolVar(&paginateQuery, "paginate", false, "follow nextCursor to fetch every page of results and merge them")
+	cmdQuery.Flags().StringVar(&cursorVariable, "cursor-variable", defaultCursorVariable, "the query variable to pass the cursor in when paginating")
 }
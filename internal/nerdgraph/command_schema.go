@@ -0,0 +1,121 @@
+package nerdgraph
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+)
+
+var cmdSchema = &cobra.Command{
+	Use:   "schema",
+	Short: "Explore the NerdGraph schema",
+	Long: `Explore the NerdGraph schema
+
+The schema commands fetch the NerdGraph schema and cache it locally, and describe
+its types.  Once the schema is cached, queries run with the query and run commands
+are checked against it before they are sent.
+`,
+	Example: `newrelic nerdgraph schema fetch
+newrelic nerdgraph schema describe Actor`,
+}
+
+var cmdSchemaFetch = &cobra.Command{
+	Use:   "fetch",
+	Short: "Fetch and cache the NerdGraph schema",
+	Long: `Fetch and cache the NerdGraph schema
+
+The fetch command introspects the NerdGraph schema and caches it in the CLI
+configuration directory.  Run it again to pick up changes to the API.
+`,
+	Example: `newrelic nerdgraph schema fetch`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client.WithClient(func(nrClient *newrelic.NewRelic) {
+			s, err := fetchSchema(utils.SignalCtx, &nrClient.NerdGraph)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			path := defaultSchemaPath()
+			if err = saveSchema(path, s); err != nil {
+				log.Fatal(err)
+			}
+
+			log.Infof("cached %d types in %s", len(s.Types), path)
+		})
+	},
+}
+
+var cmdSchemaDescribe = &cobra.Command{
+	Use:   "describe",
+	Short: "Describe a type in the NerdGraph schema",
+	Long: `Describe a type in the NerdGraph schema
+
+The describe command prints a type from the cached schema, with its fields and
+their arguments, input fields, enum values or possible types.  Without a type
+name, the names of all types are listed.  The schema must have been fetched with
+the schema fetch command first.
+`,
+	Example: `newrelic nerdgraph schema describe Actor`,
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := loadSchema(defaultSchemaPath())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if s == nil {
+			log.Fatal("no schema is cached, run 'newrelic nerdgraph schema fetch' first")
+		}
+
+		if len(args) == 0 {
+			for _, name := range s.typeNames() {
+				fmt.Println(name)
+			}
+
+			return
+		}
+
+		description, err := s.describe(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Print(description)
+	},
+}
+
+// validateWithCachedSchema checks a query against the cached schema, if
+// there is one.
+func validateWithCachedSchema(query string) error {
+	s, err := loadSchema(defaultSchemaPath())
+	if err != nil {
+		return err
+	}
+
+	if s == nil {
+		log.Debug("no cached schema, skipping query validation")
+		return nil
+	}
+
+	errs := s.validateQuery(query)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	for _, e := range errs {
+		log.Error(e)
+	}
+
+	return fmt.Errorf("the query is not valid for the cached schema, use --skip-validation to send it anyway")
+}
+
+func init() {
+	Command.AddCommand(cmdSchema)
+	cmdSchema.AddCommand(cmdSchemaFetch)
+	cmdSchema.AddCommand(cmdSchemaDescribe)
+}
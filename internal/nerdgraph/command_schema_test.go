@@ -0,0 +1,32 @@
+// +build unit
+
+package nerdgraph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+)
+
+func TestSchema(t *testing.T) {
+	assert.Equal(t, "schema", cmdSchema.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdSchema)
+	testcobra.CheckCobraRequiredFlags(t, cmdSchema, []string{})
+}
+
+func TestSchemaFetch(t *testing.T) {
+	assert.Equal(t, "fetch", cmdSchemaFetch.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdSchemaFetch)
+	testcobra.CheckCobraRequiredFlags(t, cmdSchemaFetch, []string{})
+}
+
+func TestSchemaDescribe(t *testing.T) {
+	assert.Equal(t, "describe", cmdSchemaDescribe.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdSchemaDescribe)
+	testcobra.CheckCobraRequiredFlags(t, cmdSchemaDescribe, []string{})
+}
@@ -0,0 +1,320 @@
+package nerdgraph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	survey "github.com/AlecAivazis/survey/v2"
+)
+
+// explorePageSize is how many options are shown at once when picking fields.
+const explorePageSize = 20
+
+// explorePrompter asks the questions the explorer needs answered.
+type explorePrompter interface {
+	Select(message string, options []string) (int, error)
+	Input(message string, defaultValue string) (string, error)
+	Confirm(message string) (bool, error)
+}
+
+// surveyPrompter prompts in the terminal.
+type surveyPrompter struct{}
+
+func (p *surveyPrompter) Select(message string, options []string) (int, error) {
+	var selected int
+	err := survey.AskOne(&survey.Select{Message: message, Options: options}, &selected, survey.WithPageSize(explorePageSize))
+
+	return selected, err
+}
+
+func (p *surveyPrompter) Input(message string, defaultValue string) (string, error) {
+	var value string
+	err := survey.AskOne(&survey.Input{Message: message, Default: defaultValue}, &value)
+
+	return value, err
+}
+
+func (p *surveyPrompter) Confirm(message string) (bool, error) {
+	var confirmed bool
+	err := survey.AskOne(&survey.Confirm{Message: message, Default: true}, &confirmed)
+
+	return confirmed, err
+}
+
+// queryNode is a field selected in the query being built, or an inline
+// fragment on one of the possible types of an interface or union.
+type queryNode struct {
+	name     string
+	typeName string
+	fragment bool
+	args     []queryArg
+	children []*queryNode
+}
+
+type queryArg struct {
+	name  string
+	value string
+}
+
+func (n *queryNode) child(name string, fragment bool) *queryNode {
+	for _, c := range n.children {
+		if c.name == name && c.fragment == fragment {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func (n *queryNode) remove(child *queryNode) {
+	for i, c := range n.children {
+		if c == child {
+			n.children = append(n.children[:i], n.children[i+1:]...)
+			return
+		}
+	}
+}
+
+// String returns the query the node and its children select.
+func (n *queryNode) String() string {
+	var b strings.Builder
+
+	b.WriteString("{\n")
+	for _, c := range n.children {
+		c.write(&b, "  ")
+	}
+	b.WriteString("}")
+
+	return b.String()
+}
+
+func (n *queryNode) write(b *strings.Builder, indent string) {
+	b.WriteString(indent)
+
+	if n.fragment {
+		b.WriteString("... on " + n.name)
+	} else {
+		b.WriteString(n.name)
+	}
+
+	if len(n.args) > 0 {
+		args := make([]string, len(n.args))
+		for i, a := range n.args {
+			args[i] = a.name + ": " + a.value
+		}
+
+		b.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+
+	if len(n.children) > 0 {
+		b.WriteString(" {\n")
+		for _, c := range n.children {
+			c.write(b, indent+"  ")
+		}
+		b.WriteString(indent + "}")
+	}
+
+	b.WriteString("\n")
+}
+
+// explorer builds a query by walking the schema, asking which fields to
+// select and what to pass as their arguments.
+type explorer struct {
+	schema   *schema
+	prompter explorePrompter
+}
+
+const (
+	exploreDone   = "<- done"
+	exploreFinish = "<- finish and build the query"
+)
+
+// explore builds a query, starting from the actor field of the root query
+// type.
+func (e *explorer) explore() (string, error) {
+	if e.schema.QueryType == nil || e.schema.lookupType(e.schema.QueryType.Name) == nil {
+		return "", fmt.Errorf("the schema has no query type")
+	}
+
+	root := &queryNode{name: "query", typeName: e.schema.QueryType.Name}
+
+	start := root
+	if actor := e.schema.lookupType(root.typeName).field("actor"); actor != nil {
+		start = &queryNode{name: actor.Name, typeName: actor.Type.named()}
+		root.children = append(root.children, start)
+	}
+
+	if start != root {
+		if err := e.walk(root, start, []string{start.name}); err != nil {
+			return "", err
+		}
+	}
+
+	if err := e.walk(nil, root, nil); err != nil {
+		return "", err
+	}
+
+	if len(root.children) == 0 {
+		return "", fmt.Errorf("no fields were selected")
+	}
+
+	return root.String(), nil
+}
+
+// walk asks which fields to select on a node until the user is done with it.
+// Nodes left without any fields selected are removed from their parent.
+func (e *explorer) walk(parent *queryNode, n *queryNode, path []string) error {
+	t := e.schema.lookupType(n.typeName)
+	if t == nil {
+		return fmt.Errorf("unknown type %s", n.typeName)
+	}
+
+	for {
+		options := []string{exploreFinish}
+		if parent != nil {
+			options[0] = exploreDone
+		}
+
+		fields := []*field{}
+		for _, f := range t.Fields {
+			mark := "[ ]"
+			if n.child(f.Name, false) != nil {
+				mark = "[x]"
+			}
+
+			label := fmt.Sprintf("%s %s%s: %s", mark, f.Name, formatArgs(f.Args), f.Type)
+			if f.IsDeprecated {
+				label += " (deprecated)"
+			}
+
+			options = append(options, label)
+			fields = append(fields, f)
+		}
+
+		possible := []string{}
+		for _, r := range t.PossibleTypes {
+			options = append(options, "... on "+r.named())
+			possible = append(possible, r.named())
+		}
+
+		message := "Select fields on " + t.Name
+		if len(path) > 0 {
+			message = fmt.Sprintf("Select fields on %s (%s)", t.Name, strings.Join(path, " > "))
+		}
+
+		choice, err := e.prompter.Select(message, options)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case choice <= 0:
+			if parent != nil && len(n.children) == 0 {
+				parent.remove(n)
+			}
+
+			return nil
+		case choice <= len(fields):
+			if err := e.selectField(n, fields[choice-1], path); err != nil {
+				return err
+			}
+		default:
+			name := possible[choice-len(fields)-1]
+
+			fragment := n.child(name, true)
+			if fragment == nil {
+				fragment = &queryNode{name: name, typeName: name, fragment: true}
+				n.children = append(n.children, fragment)
+			}
+
+			if err := e.walk(n, fragment, append(path, "... on "+name)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// selectField toggles a field without subfields, or walks into a field that
+// has them, asking for its arguments the first time.
+func (e *explorer) selectField(n *queryNode, f *field, path []string) error {
+	existing := n.child(f.Name, false)
+
+	t := e.schema.lookupType(f.Type.named())
+	leaf := t == nil || (t.Kind != kindObject && t.Kind != kindInterface && t.Kind != kindUnion)
+
+	if leaf && existing != nil {
+		n.remove(existing)
+		return nil
+	}
+
+	if existing == nil {
+		args, err := e.askArgs(f)
+		if err != nil {
+			return err
+		}
+
+		existing = &queryNode{name: f.Name, typeName: f.Type.named(), args: args}
+		n.children = append(n.children, existing)
+	}
+
+	if leaf {
+		return nil
+	}
+
+	return e.walk(n, existing, append(path, f.Name))
+}
+
+// askArgs asks for the value of each of a field's arguments.  Optional
+// arguments left empty are not passed.
+func (e *explorer) askArgs(f *field) ([]queryArg, error) {
+	args := []queryArg{}
+
+	for _, a := range f.Args {
+		message := fmt.Sprintf("%s.%s (%s)", f.Name, a.Name, a.Type)
+		if !a.Type.required() {
+			message += ", leave empty to skip"
+		}
+
+		for {
+			value, err := e.prompter.Input(message, "")
+			if err != nil {
+				return nil, err
+			}
+
+			value = strings.TrimSpace(value)
+			if value == "" && a.Type.required() && a.DefaultValue == nil {
+				continue
+			}
+
+			if value != "" {
+				args = append(args, queryArg{name: a.Name, value: e.literal(value, a.Type)})
+			}
+
+			break
+		}
+	}
+
+	return args, nil
+}
+
+// literal returns a value entered for an argument as a GraphQL literal,
+// quoting it if the argument is a string-like scalar.  Lists and input
+// objects are expected to be entered as literals already.
+func (e *explorer) literal(value string, r *typeRef) string {
+	if strings.HasPrefix(value, "\"") || strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{") {
+		return value
+	}
+
+	t := e.schema.lookupType(r.named())
+	if t == nil || t.Kind != kindScalar {
+		return value
+	}
+
+	switch t.Name {
+	case "Int", "Float", "Boolean":
+		return value
+	}
+
+	return strconv.Quote(value)
+}
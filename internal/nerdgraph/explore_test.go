@@ -0,0 +1,96 @@
+//go:build unit
+// +build unit
+
+package nerdgraph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type scriptedPrompter struct {
+	selections []int
+	inputs     []string
+	messages   []string
+}
+
+func (p *scriptedPrompter) Select(message string, options []string) (int, error) {
+	p.messages = append(p.messages, message)
+
+	choice := p.selections[0]
+	p.selections = p.selections[1:]
+
+	return choice, nil
+}
+
+func (p *scriptedPrompter) Input(message string, defaultValue string) (string, error) {
+	p.messages = append(p.messages, message)
+
+	value := p.inputs[0]
+	p.inputs = p.inputs[1:]
+
+	return value, nil
+}
+
+func (p *scriptedPrompter) Confirm(message string) (bool, error) {
+	return true, nil
+}
+
+func TestExplorer(t *testing.T) {
+	s := testSchema(t)
+	p := &scriptedPrompter{
+		selections: []int{
+			1,       // actor > entity
+			2,       // entity > name
+			3, 3, 0, // entity > ... on ApmApplicationEntity > language
+			0,             // done with entity
+			2, 2, 1, 1, 0, // actor > user > email, name toggled on and off
+			3, 0, // actor > accounts, left empty
+			0, 0, // done with actor, finish
+		},
+		inputs: []string{"", "abc", ""},
+	}
+
+	query, err := (&explorer{schema: s, prompter: p}).explore()
+	require.NoError(t, err)
+
+	assert.Equal(t, `{
+  actor {
+    entity(guid: "abc") {
+      name
+      ... on ApmApplicationEntity {
+        language
+      }
+    }
+    user {
+      email
+    }
+  }
+}`, query)
+	assert.Empty(t, s.validateQuery(query))
+
+	assert.Empty(t, p.selections)
+	assert.Empty(t, p.inputs)
+	assert.Contains(t, p.messages, "Select fields on ApmApplicationEntity (actor > entity > ... on ApmApplicationEntity)")
+	assert.Contains(t, p.messages, "entity.guid (EntityGuid!)")
+	assert.Contains(t, p.messages, "accounts.scope (Scope), leave empty to skip")
+}
+
+func TestExplorer_NothingSelected(t *testing.T) {
+	p := &scriptedPrompter{selections: []int{0, 0}}
+
+	_, err := (&explorer{schema: testSchema(t), prompter: p}).explore()
+	assert.EqualError(t, err, "no fields were selected")
+}
+
+func TestExplorer_Literal(t *testing.T) {
+	e := &explorer{schema: testSchema(t)}
+
+	assert.Equal(t, `"abc"`, e.literal("abc", &typeRef{Kind: kindScalar, Name: "EntityGuid"}))
+	assert.Equal(t, `"abc"`, e.literal(`"abc"`, &typeRef{Kind: kindScalar, Name: "String"}))
+	assert.Equal(t, "12", e.literal("12", &typeRef{Kind: kindScalar, Name: "Int"}))
+	assert.Equal(t, "GLOBAL", e.literal("GLOBAL", &typeRef{Kind: kindEnum, Name: "Scope"}))
+	assert.Equal(t, `[{key: "a"}]`, e.literal(`[{key: "a"}]`, &typeRef{Kind: kindList}))
+}
@@ -0,0 +1,589 @@
+package nerdgraph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The GraphQL parser below reads just enough of a document to validate it
+// against the schema: operations, their variables, fields, arguments and
+// fragments.  Argument values are checked for syntax and for the variables
+// they reference, but not for their types.
+
+type gqlTokenKind int
+
+const (
+	gqlEOF gqlTokenKind = iota
+	gqlPunct
+	gqlName
+	gqlNumber
+	gqlString
+)
+
+type gqlToken struct {
+	kind gqlTokenKind
+	text string
+	pos  int
+}
+
+// gqlError is an error at a position in a GraphQL document.
+type gqlError struct {
+	line int
+	col  int
+	msg  string
+}
+
+func (e *gqlError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.line, e.col, e.msg)
+}
+
+type gqlDocument struct {
+	operations []*gqlOperation
+	fragments  map[string]*gqlFragment
+}
+
+type gqlOperation struct {
+	kind       string
+	name       string
+	variables  []*gqlVariable
+	selections []*gqlSelection
+	pos        int
+}
+
+type gqlVariable struct {
+	name     string
+	typeName string
+	pos      int
+}
+
+type gqlFragment struct {
+	name          string
+	typeCondition string
+	selections    []*gqlSelection
+	pos           int
+}
+
+// gqlSelection is a field, a fragment spread or an inline fragment.
+type gqlSelection struct {
+	field      string
+	args       []*gqlArgument
+	selections []*gqlSelection
+	// hasSelections distinguishes an empty selection set from none
+	hasSelections bool
+
+	spread        string
+	inline        bool
+	typeCondition string
+
+	pos int
+}
+
+type gqlArgument struct {
+	name      string
+	variables []string
+	pos       int
+}
+
+type gqlParser struct {
+	source string
+	tokens []gqlToken
+	i      int
+}
+
+// parseGraphQL parses a GraphQL document.
+func parseGraphQL(source string) (*gqlDocument, error) {
+	p := &gqlParser{source: source}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+
+	doc := &gqlDocument{fragments: map[string]*gqlFragment{}}
+
+	for p.peek().kind != gqlEOF {
+		t := p.peek()
+
+		switch {
+		case t.kind == gqlPunct && t.text == "{":
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+
+			doc.operations = append(doc.operations, &gqlOperation{kind: "query", selections: selections, pos: t.pos})
+		case t.kind == gqlName && (t.text == "query" || t.text == "mutation" || t.text == "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+
+			doc.operations = append(doc.operations, op)
+		case t.kind == gqlName && t.text == "fragment":
+			f, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+
+			if doc.fragments[f.name] != nil {
+				return nil, p.errorAt(f.pos, "fragment %s is defined more than once", f.name)
+			}
+
+			doc.fragments[f.name] = f
+		default:
+			return nil, p.errorAt(t.pos, "unexpected %s, expected an operation or fragment", p.describe(t))
+		}
+	}
+
+	if len(doc.operations) == 0 {
+		return nil, p.errorAt(0, "no operation found")
+	}
+
+	return doc, nil
+}
+
+func (p *gqlParser) tokenize() error {
+	s := p.source
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ',':
+			i++
+		case c == '#':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(s[i:], "..."):
+			p.tokens = append(p.tokens, gqlToken{gqlPunct, "...", i})
+			i += 3
+		case strings.ContainsRune("!$():=@[]{}|&", rune(c)):
+			p.tokens = append(p.tokens, gqlToken{gqlPunct, string(c), i})
+			i++
+		case strings.HasPrefix(s[i:], `"""`):
+			end := strings.Index(s[i+3:], `"""`)
+			if end < 0 {
+				return p.errorAt(i, "unterminated string")
+			}
+
+			p.tokens = append(p.tokens, gqlToken{gqlString, s[i : i+end+6], i})
+			i += end + 6
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' && s[end] != '\n' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+
+			if end >= len(s) || s[end] != '"' {
+				return p.errorAt(i, "unterminated string")
+			}
+
+			p.tokens = append(p.tokens, gqlToken{gqlString, s[i : end+1], i})
+			i = end + 1
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(s) && strings.ContainsRune("0123456789.eE+-", rune(s[end])) {
+				end++
+			}
+
+			p.tokens = append(p.tokens, gqlToken{gqlNumber, s[i:end], i})
+			i = end
+		case isNameStart(c):
+			end := i + 1
+			for end < len(s) && (isNameStart(s[end]) || (s[end] >= '0' && s[end] <= '9')) {
+				end++
+			}
+
+			p.tokens = append(p.tokens, gqlToken{gqlName, s[i:end], i})
+			i = end
+		default:
+			return p.errorAt(i, "unexpected character %q", c)
+		}
+	}
+
+	p.tokens = append(p.tokens, gqlToken{gqlEOF, "", len(s)})
+
+	return nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func (p *gqlParser) peek() gqlToken {
+	return p.tokens[p.i]
+}
+
+func (p *gqlParser) next() gqlToken {
+	t := p.tokens[p.i]
+	if t.kind != gqlEOF {
+		p.i++
+	}
+
+	return t
+}
+
+// accept consumes the next token if it is the given punctuation.
+func (p *gqlParser) accept(punct string) bool {
+	if t := p.peek(); t.kind == gqlPunct && t.text == punct {
+		p.i++
+		return true
+	}
+
+	return false
+}
+
+func (p *gqlParser) expect(punct string) error {
+	if !p.accept(punct) {
+		t := p.peek()
+		return p.errorAt(t.pos, "expected '%s', found %s", punct, p.describe(t))
+	}
+
+	return nil
+}
+
+func (p *gqlParser) name() (gqlToken, error) {
+	t := p.next()
+	if t.kind != gqlName {
+		return t, p.errorAt(t.pos, "expected a name, found %s", p.describe(t))
+	}
+
+	return t, nil
+}
+
+func (p *gqlParser) describe(t gqlToken) string {
+	if t.kind == gqlEOF {
+		return "the end of the document"
+	}
+
+	return fmt.Sprintf("'%s'", t.text)
+}
+
+func (p *gqlParser) errorAt(pos int, format string, args ...interface{}) error {
+	line := strings.Count(p.source[:pos], "\n") + 1
+	col := pos - strings.LastIndex(p.source[:pos], "\n")
+
+	return &gqlError{line: line, col: col, msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *gqlParser) operation() (*gqlOperation, error) {
+	kind := p.next()
+	op := &gqlOperation{kind: kind.text, pos: kind.pos}
+
+	if p.peek().kind == gqlName {
+		op.name = p.next().text
+	}
+
+	if p.accept("(") {
+		for !p.accept(")") {
+			v, err := p.variableDefinition()
+			if err != nil {
+				return nil, err
+			}
+
+			op.variables = append(op.variables, v)
+		}
+	}
+
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	op.selections = selections
+
+	return op, nil
+}
+
+func (p *gqlParser) variableDefinition() (*gqlVariable, error) {
+	t := p.peek()
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = p.expect(":"); err != nil {
+		return nil, err
+	}
+
+	typeName, err := p.typeReference()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.accept("=") {
+		if _, err = p.value(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = p.directives(); err != nil {
+		return nil, err
+	}
+
+	return &gqlVariable{name: name.text, typeName: typeName, pos: t.pos}, nil
+}
+
+// typeReference reads a type such as "[String!]!", returning the named type.
+func (p *gqlParser) typeReference() (string, error) {
+	var typeName string
+
+	if p.accept("[") {
+		inner, err := p.typeReference()
+		if err != nil {
+			return "", err
+		}
+
+		if err = p.expect("]"); err != nil {
+			return "", err
+		}
+
+		typeName = inner
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return "", err
+		}
+
+		typeName = name.text
+	}
+
+	p.accept("!")
+
+	return typeName, nil
+}
+
+func (p *gqlParser) fragment() (*gqlFragment, error) {
+	start := p.next()
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+
+	on, err := p.name()
+	if err != nil || on.text != "on" {
+		return nil, p.errorAt(on.pos, "expected 'on' after the fragment name")
+	}
+
+	typeCondition, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = p.directives(); err != nil {
+		return nil, err
+	}
+
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	return &gqlFragment{name: name.text, typeCondition: typeCondition.text, selections: selections, pos: start.pos}, nil
+}
+
+func (p *gqlParser) selectionSet() ([]*gqlSelection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	selections := []*gqlSelection{}
+
+	for !p.accept("}") {
+		if p.peek().kind == gqlEOF {
+			return nil, p.errorAt(p.peek().pos, "expected '}', found the end of the document")
+		}
+
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+
+		selections = append(selections, s)
+	}
+
+	if len(selections) == 0 {
+		return nil, p.errorAt(p.tokens[p.i-1].pos, "empty selection set")
+	}
+
+	return selections, nil
+}
+
+func (p *gqlParser) selection() (*gqlSelection, error) {
+	start := p.peek()
+
+	if p.accept("...") {
+		s := &gqlSelection{pos: start.pos}
+
+		if t := p.peek(); t.kind == gqlName && t.text != "on" {
+			s.spread = p.next().text
+			return s, p.directives()
+		}
+
+		s.inline = true
+		if t := p.peek(); t.kind == gqlName && t.text == "on" {
+			p.next()
+
+			typeCondition, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+
+			s.typeCondition = typeCondition.text
+		}
+
+		if err := p.directives(); err != nil {
+			return nil, err
+		}
+
+		selections, err := p.selectionSet()
+		if err != nil {
+			return nil, err
+		}
+
+		s.selections = selections
+		s.hasSelections = true
+
+		return s, nil
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &gqlSelection{field: name.text, pos: name.pos}
+
+	// An alias precedes the field name
+	if p.accept(":") {
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+
+		s.field = name.text
+		s.pos = name.pos
+	}
+
+	if p.accept("(") {
+		for !p.accept(")") {
+			arg, argErr := p.argument()
+			if argErr != nil {
+				return nil, argErr
+			}
+
+			s.args = append(s.args, arg)
+		}
+	}
+
+	if err = p.directives(); err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind == gqlPunct && t.text == "{" {
+		if s.selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+
+		s.hasSelections = true
+	}
+
+	return s, nil
+}
+
+func (p *gqlParser) argument() (*gqlArgument, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = p.expect(":"); err != nil {
+		return nil, err
+	}
+
+	vars, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+
+	return &gqlArgument{name: name.text, variables: vars, pos: name.pos}, nil
+}
+
+// value reads a value, returning the names of the variables it references.
+func (p *gqlParser) value() ([]string, error) {
+	t := p.next()
+
+	switch {
+	case t.kind == gqlPunct && t.text == "$":
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{name.text}, nil
+	case t.kind == gqlPunct && t.text == "[":
+		vars := []string{}
+		for !p.accept("]") {
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+
+			vars = append(vars, v...)
+		}
+
+		return vars, nil
+	case t.kind == gqlPunct && t.text == "{":
+		vars := []string{}
+		for !p.accept("}") {
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+
+			vars = append(vars, v...)
+		}
+
+		return vars, nil
+	case t.kind == gqlName || t.kind == gqlNumber || t.kind == gqlString:
+		return nil, nil
+	}
+
+	return nil, p.errorAt(t.pos, "expected a value, found %s", p.describe(t))
+}
+
+func (p *gqlParser) directives() error {
+	for p.accept("@") {
+		if _, err := p.name(); err != nil {
+			return err
+		}
+
+		if p.accept("(") {
+			for !p.accept(")") {
+				if _, err := p.argument(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
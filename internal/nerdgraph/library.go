@@ -0,0 +1,136 @@
+package nerdgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	queryExt     = ".graphql"
+	variablesExt = ".json"
+)
+
+var queryNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// savedQuery is a GraphQL query kept in the query library, along with the
+// default values of its variables.
+type savedQuery struct {
+	Name      string                 `json:"name"`
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// queryLibrary stores saved queries in a directory, each as a .graphql file
+// with its variables alongside in a .json file.
+type queryLibrary struct {
+	dir string
+}
+
+func newQueryLibrary(dir string) *queryLibrary {
+	return &queryLibrary{dir: dir}
+}
+
+func (l *queryLibrary) path(name string, ext string) string {
+	return filepath.Join(l.dir, name+ext)
+}
+
+// save stores a query, replacing any saved with the same name.
+func (l *queryLibrary) save(q savedQuery) error {
+	if !queryNamePattern.MatchString(q.Name) {
+		return fmt.Errorf("invalid query name %q, use letters, numbers, '.', '-' and '_'", q.Name)
+	}
+
+	if strings.TrimSpace(q.Query) == "" {
+		return fmt.Errorf("the query is empty")
+	}
+
+	if err := os.MkdirAll(l.dir, 0750); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(l.path(q.Name, queryExt), []byte(q.Query), 0640); err != nil {
+		return err
+	}
+
+	varsPath := l.path(q.Name, variablesExt)
+	if len(q.Variables) == 0 {
+		if err := os.Remove(varsPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	out, err := json.MarshalIndent(q.Variables, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(varsPath, out, 0640)
+}
+
+// load returns the named query.
+func (l *queryLibrary) load(name string) (*savedQuery, error) {
+	if !queryNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid query name %q", name)
+	}
+
+	out, err := ioutil.ReadFile(l.path(name, queryExt))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no saved query named %s", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	q := savedQuery{
+		Name:  name,
+		Query: string(out),
+	}
+
+	if _, err = os.Stat(l.path(name, variablesExt)); err == nil {
+		if q.Variables, err = readVariablesFile(l.path(name, variablesExt)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &q, nil
+}
+
+// list returns the saved queries, sorted by name.
+func (l *queryLibrary) list() ([]savedQuery, error) {
+	files, err := ioutil.ReadDir(l.dir)
+	if os.IsNotExist(err) {
+		return []savedQuery{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, f := range files {
+		if !f.IsDir() && filepath.Ext(f.Name()) == queryExt {
+			names = append(names, strings.TrimSuffix(f.Name(), queryExt))
+		}
+	}
+
+	sort.Strings(names)
+
+	queries := make([]savedQuery, 0, len(names))
+	for _, n := range names {
+		q, loadErr := l.load(n)
+		if loadErr != nil {
+			return nil, loadErr
+		}
+
+		queries = append(queries, *q)
+	}
+
+	return queries, nil
+}
@@ -0,0 +1,82 @@
+// +build unit
+
+package nerdgraph
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryLibrary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nerdgraph")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l := newQueryLibrary(filepath.Join(dir, "queries"))
+
+	queries, err := l.list()
+	require.NoError(t, err)
+	assert.Empty(t, queries)
+
+	entity := savedQuery{
+		Name:      "entity",
+		Query:     "query($guid: EntityGuid!) { actor { entity(guid: $guid) { name } } }",
+		Variables: map[string]interface{}{"guid": "abc"},
+	}
+	require.NoError(t, l.save(entity))
+	require.NoError(t, l.save(savedQuery{Name: "user", Query: "{ actor { user { name } } }"}))
+
+	q, err := l.load("entity")
+	require.NoError(t, err)
+	assert.Equal(t, entity, *q)
+
+	queries, err = l.list()
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+	assert.Equal(t, "entity", queries[0].Name)
+	assert.Equal(t, "user", queries[1].Name)
+	assert.Nil(t, queries[1].Variables)
+
+	// Saving again without variables removes the old ones
+	require.NoError(t, l.save(savedQuery{Name: "entity", Query: entity.Query}))
+	q, err = l.load("entity")
+	require.NoError(t, err)
+	assert.Nil(t, q.Variables)
+
+	_, err = l.load("missing")
+	require.Error(t, err)
+
+	require.Error(t, l.save(savedQuery{Name: "../escape", Query: "{}"}))
+	require.Error(t, l.save(savedQuery{Name: "empty", Query: " "}))
+}
+
+func TestParseVars(t *testing.T) {
+	vars, err := parseVars([]string{"guid=MXxBUE18", "accountId=12345", "enabled=true", "tags=[\"a\"]", "query=a=b"})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"guid":      "MXxBUE18",
+		"accountId": float64(12345),
+		"enabled":   true,
+		"tags":      []interface{}{"a"},
+		"query":     "a=b",
+	}, vars)
+
+	_, err = parseVars([]string{"guid"})
+	require.Error(t, err)
+}
+
+func TestMergeVariables(t *testing.T) {
+	merged := mergeVariables(
+		map[string]interface{}{"a": 1, "b": 1},
+		map[string]interface{}{"b": 2, "c": 2},
+		map[string]interface{}{"c": 3},
+	)
+
+	assert.Equal(t, map[string]interface{}{"a": 1, "b": 2, "c": 3}, merged)
+}
@@ -0,0 +1,148 @@
+package nerdgraph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+)
+
+// cursorField is the field NerdGraph returns the cursor of the next page of
+// results in.
+const cursorField = "nextCursor"
+
+// defaultCursorVariable is the query variable the cursor is passed back in.
+const defaultCursorVariable = "cursor"
+
+// paginate runs a query until its results are exhausted, passing the
+// nextCursor of each response back in the cursor variable, and returns the
+// responses merged, with the result lists concatenated.  The response must
+// contain a single nextCursor field.
+func paginate(ctx context.Context, q client.NerdGraphQuerier, query string, vars map[string]interface{}, cursorVariable string) (
+	map[string]interface{}, error) {
+	if err := checkCursorVariable(query, cursorVariable); err != nil {
+		return nil, err
+	}
+
+	pageVars := map[string]interface{}{}
+	for k, v := range vars {
+		pageVars[k] = v
+	}
+
+	var merged map[string]interface{}
+	seen := map[string]bool{}
+
+	for {
+		resp := map[string]interface{}{}
+		if err := q.QueryWithResponseAndContext(ctx, query, pageVars, &resp); err != nil {
+			return nil, err
+		}
+
+		merged = mergePages(merged, resp).(map[string]interface{})
+
+		cursors := findCursors(resp, "")
+		if len(cursors) == 0 {
+			return merged, nil
+		}
+
+		if len(cursors) > 1 {
+			paths := make([]string, 0, len(cursors))
+			for path := range cursors {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+
+			return nil, fmt.Errorf("the response has more than one %s, at %s, and only one can be followed", cursorField, strings.Join(paths, " and "))
+		}
+
+		for _, cursor := range cursors {
+			if seen[cursor] {
+				return nil, fmt.Errorf("the response returned the cursor %s twice", cursor)
+			}
+
+			seen[cursor] = true
+			pageVars[cursorVariable] = cursor
+		}
+	}
+}
+
+// checkCursorVariable checks that the query declares the cursor variable, so
+// that passing it back has an effect.
+func checkCursorVariable(query string, cursorVariable string) error {
+	doc, err := parseGraphQL(query)
+	if err != nil {
+		return err
+	}
+
+	for _, op := range doc.operations {
+		for _, v := range op.variables {
+			if v.name == cursorVariable {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("the query must declare a $%s variable and pass it as the cursor argument to paginate", cursorVariable)
+}
+
+// findCursors returns the non-empty nextCursor values in a response, by
+// their path.
+func findCursors(value interface{}, path string) map[string]string {
+	cursors := map[string]string{}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+
+			if cursor, ok := child.(string); ok && k == cursorField {
+				if cursor != "" {
+					cursors[childPath] = cursor
+				}
+				continue
+			}
+
+			for p, c := range findCursors(child, childPath) {
+				cursors[p] = c
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			for p, c := range findCursors(child, fmt.Sprintf("%s[%d]", path, i)) {
+				cursors[p] = c
+			}
+		}
+	}
+
+	return cursors
+}
+
+// mergePages merges a page of results into those before it.  Lists are
+// concatenated, objects merged field by field, and other values are taken
+// from the latest page.
+func mergePages(merged interface{}, page interface{}) interface{} {
+	switch p := page.(type) {
+	case map[string]interface{}:
+		m, ok := merged.(map[string]interface{})
+		if !ok || m == nil {
+			m = map[string]interface{}{}
+		}
+
+		for k, v := range p {
+			m[k] = mergePages(m[k], v)
+		}
+
+		return m
+	case []interface{}:
+		if m, ok := merged.([]interface{}); ok {
+			return append(m, p...)
+		}
+	}
+
+	return page
+}
@@ -0,0 +1,70 @@
+// +build unit
+
+package nerdgraph
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQuerier struct {
+	pages []string
+	vars  []map[string]interface{}
+}
+
+func (m *mockQuerier) QueryWithResponseAndContext(ctx context.Context, query string, vars map[string]interface{}, respBody interface{}) error {
+	copied := map[string]interface{}{}
+	for k, v := range vars {
+		copied[k] = v
+	}
+
+	page := m.pages[len(m.vars)]
+	m.vars = append(m.vars, copied)
+
+	return json.Unmarshal([]byte(page), respBody)
+}
+
+const paginatedQuery = `query($cursor: String) { actor { entitySearch(query: "domain = 'APM'") {
+	count results(cursor: $cursor) { entities { name } nextCursor } } } }`
+
+func TestPaginate(t *testing.T) {
+	m := &mockQuerier{pages: []string{
+		`{"actor": {"entitySearch": {"count": 3, "results": {"entities": [{"name": "a"}, {"name": "b"}], "nextCursor": "c1"}}}}`,
+		`{"actor": {"entitySearch": {"count": 3, "results": {"entities": [{"name": "c"}], "nextCursor": null}}}}`,
+	}}
+
+	merged, err := paginate(context.Background(), m, paginatedQuery, map[string]interface{}{"other": 1}, "cursor")
+	require.NoError(t, err)
+
+	out, err := json.Marshal(merged)
+	require.NoError(t, err)
+
+	expected := `{"actor": {"entitySearch": {"count": 3, "results": {"entities": [{"name": "a"}, {"name": "b"}, {"name": "c"}], "nextCursor": null}}}}`
+	assert.JSONEq(t, expected, string(out))
+
+	require.Len(t, m.vars, 2)
+	assert.Equal(t, map[string]interface{}{"other": 1}, m.vars[0])
+	assert.Equal(t, map[string]interface{}{"other": 1, "cursor": "c1"}, m.vars[1])
+}
+
+func TestPaginate_Errors(t *testing.T) {
+	_, err := paginate(context.Background(), &mockQuerier{}, `{ actor { user { name } } }`, nil, "cursor")
+	assert.EqualError(t, err, "the query must declare a $cursor variable and pass it as the cursor argument to paginate")
+
+	m := &mockQuerier{pages: []string{
+		`{"a": {"nextCursor": "x"}, "b": {"nextCursor": "y"}}`,
+	}}
+	_, err = paginate(context.Background(), m, paginatedQuery, nil, "cursor")
+	assert.EqualError(t, err, "the response has more than one nextCursor, at a.nextCursor and b.nextCursor, and only one can be followed")
+
+	m = &mockQuerier{pages: []string{
+		`{"a": {"items": [1], "nextCursor": "x"}}`,
+		`{"a": {"items": [2], "nextCursor": "x"}}`,
+	}}
+	_, err = paginate(context.Background(), m, paginatedQuery, nil, "cursor")
+	assert.EqualError(t, err, "the response returned the cursor x twice")
+}
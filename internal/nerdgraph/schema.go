@@ -0,0 +1,362 @@
+package nerdgraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/newrelic/newrelic-cli/internal/config"
+	ng "github.com/newrelic/newrelic-client-go/pkg/nerdgraph"
+)
+
+// schemaFile is where the introspected schema is cached, within the CLI
+// configuration directory.
+const schemaFile = "nerdgraph-schema.json"
+
+// introspectionQuery fetches the schema's types, their fields and arguments.
+const introspectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    types {
+      kind
+      name
+      description
+      fields(includeDeprecated: true) {
+        name
+        description
+        args { ...InputValue }
+        type { ...TypeRef }
+        isDeprecated
+        deprecationReason
+      }
+      inputFields { ...InputValue }
+      interfaces { ...TypeRef }
+      enumValues(includeDeprecated: true) {
+        name
+        description
+        isDeprecated
+        deprecationReason
+      }
+      possibleTypes { ...TypeRef }
+    }
+  }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } }
+}`
+
+// Kinds of GraphQL types.
+const (
+	kindScalar      = "SCALAR"
+	kindObject      = "OBJECT"
+	kindInterface   = "INTERFACE"
+	kindUnion       = "UNION"
+	kindEnum        = "ENUM"
+	kindInputObject = "INPUT_OBJECT"
+	kindList        = "LIST"
+	kindNonNull     = "NON_NULL"
+)
+
+// schema is the introspected NerdGraph schema.
+type schema struct {
+	QueryType    *typeRef      `json:"queryType"`
+	MutationType *typeRef      `json:"mutationType"`
+	Types        []*schemaType `json:"types"`
+
+	byName map[string]*schemaType
+}
+
+type schemaType struct {
+	Kind          string        `json:"kind"`
+	Name          string        `json:"name"`
+	Description   string        `json:"description,omitempty"`
+	Fields        []*field      `json:"fields,omitempty"`
+	InputFields   []*inputValue `json:"inputFields,omitempty"`
+	Interfaces    []*typeRef    `json:"interfaces,omitempty"`
+	EnumValues    []*enumValue  `json:"enumValues,omitempty"`
+	PossibleTypes []*typeRef    `json:"possibleTypes,omitempty"`
+}
+
+type field struct {
+	Name              string        `json:"name"`
+	Description       string        `json:"description,omitempty"`
+	Args              []*inputValue `json:"args,omitempty"`
+	Type              *typeRef      `json:"type"`
+	IsDeprecated      bool          `json:"isDeprecated,omitempty"`
+	DeprecationReason string        `json:"deprecationReason,omitempty"`
+}
+
+type inputValue struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description,omitempty"`
+	Type         *typeRef `json:"type"`
+	DefaultValue *string  `json:"defaultValue,omitempty"`
+}
+
+type enumValue struct {
+	Name              string `json:"name"`
+	Description       string `json:"description,omitempty"`
+	IsDeprecated      bool   `json:"isDeprecated,omitempty"`
+	DeprecationReason string `json:"deprecationReason,omitempty"`
+}
+
+// typeRef is a reference to a named type, possibly wrapped in lists and
+// non-null markers.
+type typeRef struct {
+	Kind   string   `json:"kind,omitempty"`
+	Name   string   `json:"name,omitempty"`
+	OfType *typeRef `json:"ofType,omitempty"`
+}
+
+// String returns the reference in GraphQL notation, e.g. "[String!]!".
+func (r *typeRef) String() string {
+	if r == nil {
+		return ""
+	}
+
+	switch r.Kind {
+	case kindNonNull:
+		return r.OfType.String() + "!"
+	case kindList:
+		return "[" + r.OfType.String() + "]"
+	}
+
+	return r.Name
+}
+
+// named returns the name of the referenced type, without any wrapping.
+func (r *typeRef) named() string {
+	for r != nil && r.OfType != nil {
+		r = r.OfType
+	}
+
+	if r == nil {
+		return ""
+	}
+
+	return r.Name
+}
+
+func (r *typeRef) required() bool {
+	return r != nil && r.Kind == kindNonNull
+}
+
+func (s *schema) index() {
+	s.byName = map[string]*schemaType{}
+	for _, t := range s.Types {
+		s.byName[t.Name] = t
+	}
+}
+
+// lookupType returns the named type, or nil if there is no such type.
+func (s *schema) lookupType(name string) *schemaType {
+	if s.byName == nil {
+		s.index()
+	}
+
+	return s.byName[name]
+}
+
+// typeNames returns the names of the schema's types, sorted.
+func (s *schema) typeNames() []string {
+	names := []string{}
+	for _, t := range s.Types {
+		if !strings.HasPrefix(t.Name, "__") {
+			names = append(names, t.Name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (t *schemaType) field(name string) *field {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+func (f *field) arg(name string) *inputValue {
+	for _, a := range f.Args {
+		if a.Name == name {
+			return a
+		}
+	}
+
+	return nil
+}
+
+// defaultSchemaPath returns where the schema is cached.
+func defaultSchemaPath() string {
+	return filepath.Join(config.DefaultConfigDirectory, schemaFile)
+}
+
+// fetchSchema introspects the NerdGraph schema.
+func fetchSchema(ctx context.Context, n *ng.NerdGraph) (*schema, error) {
+	resp := struct {
+		Schema *schema `json:"__schema"`
+	}{}
+
+	if err := n.QueryWithResponseAndContext(ctx, introspectionQuery, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Schema == nil {
+		return nil, fmt.Errorf("the introspection query returned no schema")
+	}
+
+	return resp.Schema, nil
+}
+
+// loadSchema reads a cached schema.  It returns nil if nothing is cached.
+func loadSchema(path string) (*schema, error) {
+	out, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s schema
+	if err = json.Unmarshal(out, &s); err != nil {
+		return nil, fmt.Errorf("could not read the cached schema %s: %s", path, err)
+	}
+
+	s.index()
+
+	return &s, nil
+}
+
+// saveSchema caches a schema.
+func saveSchema(path string, s *schema) error {
+	out, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, out, 0640)
+}
+
+// describe returns a description of a type, with its fields and their
+// arguments, input fields, enum values or possible types.
+func (s *schema) describe(name string) (string, error) {
+	t := s.lookupType(name)
+	if t == nil {
+		return "", fmt.Errorf("no type named %s", name)
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s (%s)\n", t.Name, t.Kind)
+	writeDescription(&b, t.Description, "  ")
+
+	if len(t.Interfaces) > 0 {
+		names := make([]string, len(t.Interfaces))
+		for i, r := range t.Interfaces {
+			names[i] = r.named()
+		}
+
+		fmt.Fprintf(&b, "\nImplements: %s\n", strings.Join(names, ", "))
+	}
+
+	if len(t.Fields) > 0 {
+		b.WriteString("\nFields:\n")
+		for _, f := range t.Fields {
+			fmt.Fprintf(&b, "  %s%s: %s", f.Name, formatArgs(f.Args), f.Type)
+			if f.IsDeprecated {
+				fmt.Fprintf(&b, " (deprecated: %s)", f.DeprecationReason)
+			}
+			b.WriteString("\n")
+			writeDescription(&b, f.Description, "      ")
+		}
+	}
+
+	if len(t.InputFields) > 0 {
+		b.WriteString("\nInput fields:\n")
+		for _, f := range t.InputFields {
+			fmt.Fprintf(&b, "  %s\n", formatInputValue(f))
+			writeDescription(&b, f.Description, "      ")
+		}
+	}
+
+	if len(t.EnumValues) > 0 {
+		b.WriteString("\nValues:\n")
+		for _, v := range t.EnumValues {
+			fmt.Fprintf(&b, "  %s\n", v.Name)
+			writeDescription(&b, v.Description, "      ")
+		}
+	}
+
+	if len(t.PossibleTypes) > 0 {
+		b.WriteString("\nPossible types:\n")
+		for _, r := range t.PossibleTypes {
+			fmt.Fprintf(&b, "  %s\n", r.named())
+		}
+	}
+
+	return b.String(), nil
+}
+
+func formatArgs(args []*inputValue) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	parts := make([]string, len(args))
+	for i, a := range args {
+		parts[i] = formatInputValue(a)
+	}
+
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func formatInputValue(v *inputValue) string {
+	s := v.Name + ": " + v.Type.String()
+	if v.DefaultValue != nil {
+		s += " = " + *v.DefaultValue
+	}
+
+	return s
+}
+
+// writeDescription writes the first paragraph of a description, indented.
+func writeDescription(b *strings.Builder, description string, indent string) {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return
+	}
+
+	if i := strings.Index(description, "\n\n"); i >= 0 {
+		description = description[:i]
+	}
+
+	for _, line := range strings.Split(description, "\n") {
+		b.WriteString(indent + strings.TrimSpace(line) + "\n")
+	}
+}
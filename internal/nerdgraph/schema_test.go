@@ -0,0 +1,158 @@
+// +build unit
+
+package nerdgraph
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchemaJSON = `{
+  "queryType": {"name": "Query"},
+  "mutationType": {"name": "Mutation"},
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "actor", "type": {"kind": "OBJECT", "name": "Actor"}}
+    ]},
+    {"kind": "OBJECT", "name": "Mutation", "fields": [
+      {"name": "tagEntity", "args": [
+        {"name": "guid", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "EntityGuid"}}},
+        {"name": "tags", "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "INPUT_OBJECT", "name": "TagInput"}}}}
+      ], "type": {"kind": "SCALAR", "name": "String"}}
+    ]},
+    {"kind": "OBJECT", "name": "Actor", "description": "The user making the request.\n\nMore details.", "fields": [
+      {"name": "entity", "description": "Fetch an entity.", "args": [
+        {"name": "guid", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "EntityGuid"}}}
+      ], "type": {"kind": "INTERFACE", "name": "Entity"}},
+      {"name": "user", "type": {"kind": "OBJECT", "name": "User"}},
+      {"name": "accounts", "args": [
+        {"name": "scope", "type": {"kind": "ENUM", "name": "Scope"}, "defaultValue": "IN_REGION"}
+      ], "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "Account"}}, "isDeprecated": true, "deprecationReason": "Use organization"}
+    ]},
+    {"kind": "INTERFACE", "name": "Entity", "fields": [
+      {"name": "guid", "type": {"kind": "SCALAR", "name": "EntityGuid"}},
+      {"name": "name", "type": {"kind": "SCALAR", "name": "String"}}
+    ], "possibleTypes": [{"kind": "OBJECT", "name": "ApmApplicationEntity"}]},
+    {"kind": "OBJECT", "name": "ApmApplicationEntity", "fields": [
+      {"name": "guid", "type": {"kind": "SCALAR", "name": "EntityGuid"}},
+      {"name": "name", "type": {"kind": "SCALAR", "name": "String"}},
+      {"name": "language", "type": {"kind": "SCALAR", "name": "String"}}
+    ], "interfaces": [{"kind": "INTERFACE", "name": "Entity"}]},
+    {"kind": "OBJECT", "name": "User", "fields": [
+      {"name": "name", "type": {"kind": "SCALAR", "name": "String"}},
+      {"name": "email", "type": {"kind": "SCALAR", "name": "String"}}
+    ]},
+    {"kind": "OBJECT", "name": "Account", "fields": [
+      {"name": "id", "type": {"kind": "SCALAR", "name": "Int"}}
+    ]},
+    {"kind": "INPUT_OBJECT", "name": "TagInput", "inputFields": [
+      {"name": "key", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}}
+    ]},
+    {"kind": "ENUM", "name": "Scope", "enumValues": [{"name": "GLOBAL"}, {"name": "IN_REGION"}]},
+    {"kind": "SCALAR", "name": "EntityGuid"},
+    {"kind": "SCALAR", "name": "String"},
+    {"kind": "SCALAR", "name": "Int"},
+    {"kind": "OBJECT", "name": "__Schema"}
+  ]
+}`
+
+func testSchema(t *testing.T) *schema {
+	var s schema
+	require.NoError(t, json.Unmarshal([]byte(testSchemaJSON), &s))
+
+	return &s
+}
+
+func TestSchema_SaveAndLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nerdgraph")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, schemaFile)
+
+	s, err := loadSchema(path)
+	require.NoError(t, err)
+	assert.Nil(t, s)
+
+	require.NoError(t, saveSchema(path, testSchema(t)))
+
+	s, err = loadSchema(path)
+	require.NoError(t, err)
+	require.NotNil(t, s.lookupType("Actor"))
+	assert.Equal(t, "Entity", s.lookupType("Actor").field("entity").Type.named())
+}
+
+func TestSchema_Describe(t *testing.T) {
+	s := testSchema(t)
+
+	description, err := s.describe("Actor")
+	require.NoError(t, err)
+	assert.Equal(t, `Actor (OBJECT)
+  The user making the request.
+
+Fields:
+  entity(guid: EntityGuid!): Entity
+      Fetch an entity.
+  user: User
+  accounts(scope: Scope = IN_REGION): [Account] (deprecated: Use organization)
+`, description)
+
+	description, err = s.describe("Scope")
+	require.NoError(t, err)
+	assert.Contains(t, description, "Values:\n  GLOBAL\n  IN_REGION\n")
+
+	_, err = s.describe("Nope")
+	require.Error(t, err)
+
+	assert.NotContains(t, s.typeNames(), "__Schema")
+}
+
+func TestSchema_ValidateQuery(t *testing.T) {
+	s := testSchema(t)
+
+	valid := []string{
+		`{ actor { user { name email } } }`,
+		`query($guid: EntityGuid!) { actor { entity(guid: $guid) { guid ... on ApmApplicationEntity { language } } } }`,
+		`query Q { actor { me: user { ...UserFields } } } fragment UserFields on User { name, __typename }`,
+		`mutation($tags: [TagInput!]!) { tagEntity(guid: "abc", tags: $tags) }`,
+		`{ actor { accounts { id } } } # comment`,
+	}
+
+	for _, q := range valid {
+		assert.Empty(t, s.validateQuery(q), q)
+	}
+
+	invalid := map[string]string{
+		`{ actor { usr { name } } }`:                            "1:11: Actor has no field usr, did you mean user?",
+		`{ actor { entity { name } } }`:                         "1:11: Actor.entity requires the argument guid: EntityGuid!",
+		`{ actor { entity(guid: $guid) { name } } }`:            "1:18: variable $guid is not declared",
+		`{ actor { entity(guid: "a", id: 1) { name } } }`:       "1:29: Actor.entity has no argument id",
+		`{ actor { user } }`:                                    "1:11: Actor.user is a User and needs a selection of its fields",
+		`{ actor { user { name { first } } } }`:                 "1:18: User.name is a String and cannot have a selection",
+		`{ actor { user { ...Missing } } }`:                     "1:18: unknown fragment Missing",
+		`query($u: User) { actor { user { name } } }`:           "1:7: variable $u must be an input type, User is an object",
+		`{ actor { entity(guid: "a") { ... on Nope { x } } } }`: "1:31: unknown type Nope",
+		`subscription { actor { user { name } } }`:              "1:1: subscription operations are not supported",
+		`{ actor { user { name }`:                               "1:24: expected '}', found the end of the document",
+		"{ actor {\n  user(id: ) { name } } }":                  "2:12: expected a value, found ')'",
+	}
+
+	for q, expected := range invalid {
+		errs := s.validateQuery(q)
+		require.Len(t, errs, 1, q)
+		assert.EqualError(t, errs[0], expected, q)
+	}
+}
+
+func TestParseGraphQL_FragmentCycle(t *testing.T) {
+	s := testSchema(t)
+
+	errs := s.validateQuery(`{ actor { user { ...A } } } fragment A on User { name ...B } fragment B on User { ...A }`)
+	assert.Empty(t, errs)
+}
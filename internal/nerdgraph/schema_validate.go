@@ -0,0 +1,227 @@
+package nerdgraph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// validateQuery checks a GraphQL document against the schema, returning the
+// problems found.  Syntax errors are returned alone.
+func (s *schema) validateQuery(source string) []error {
+	p := &gqlParser{source: source}
+
+	doc, err := parseGraphQL(source)
+	if err != nil {
+		return []error{err}
+	}
+
+	v := &validator{schema: s, parser: p, doc: doc}
+
+	for _, op := range doc.operations {
+		v.operation(op)
+	}
+
+	return v.errors
+}
+
+type validator struct {
+	schema *schema
+	parser *gqlParser
+	doc    *gqlDocument
+	errors []error
+
+	declared map[string]bool
+}
+
+func (v *validator) addError(pos int, format string, args ...interface{}) {
+	v.errors = append(v.errors, v.parser.errorAt(pos, format, args...))
+}
+
+func (v *validator) operation(op *gqlOperation) {
+	var root *typeRef
+	switch op.kind {
+	case "query":
+		root = v.schema.QueryType
+	case "mutation":
+		root = v.schema.MutationType
+	}
+
+	if root == nil {
+		v.addError(op.pos, "%s operations are not supported", op.kind)
+		return
+	}
+
+	v.declared = map[string]bool{}
+	for _, variable := range op.variables {
+		v.declared[variable.name] = true
+
+		t := v.schema.lookupType(variable.typeName)
+		if t == nil {
+			v.addError(variable.pos, "unknown type %s for variable $%s", variable.typeName, variable.name)
+		} else if t.Kind != kindScalar && t.Kind != kindEnum && t.Kind != kindInputObject {
+			v.addError(variable.pos, "variable $%s must be an input type, %s is an %s", variable.name, t.Name, strings.ToLower(t.Kind))
+		}
+	}
+
+	v.selections(op.selections, v.schema.lookupType(root.Name), map[string]bool{})
+}
+
+// selections checks selections made on a type.  Fragments already being
+// checked are skipped, to guard against cycles.
+func (v *validator) selections(selections []*gqlSelection, parent *schemaType, fragments map[string]bool) {
+	if parent == nil {
+		return
+	}
+
+	for _, sel := range selections {
+		switch {
+		case sel.spread != "":
+			f := v.doc.fragments[sel.spread]
+			if f == nil {
+				v.addError(sel.pos, "unknown fragment %s", sel.spread)
+				continue
+			}
+
+			if fragments[f.name] {
+				continue
+			}
+
+			fragments[f.name] = true
+			v.selections(f.selections, v.typeCondition(f.typeCondition, parent, f.pos), fragments)
+			delete(fragments, f.name)
+		case sel.inline:
+			v.selections(sel.selections, v.typeCondition(sel.typeCondition, parent, sel.pos), fragments)
+		default:
+			v.field(sel, parent, fragments)
+		}
+	}
+}
+
+// typeCondition returns the type a fragment applies to, or the parent type if
+// there is no condition.
+func (v *validator) typeCondition(name string, parent *schemaType, pos int) *schemaType {
+	if name == "" {
+		return parent
+	}
+
+	t := v.schema.lookupType(name)
+	if t == nil {
+		v.addError(pos, "unknown type %s", name)
+	}
+
+	return t
+}
+
+func (v *validator) field(sel *gqlSelection, parent *schemaType, fragments map[string]bool) {
+	if sel.field == "__typename" {
+		return
+	}
+
+	f := parent.field(sel.field)
+	if f == nil {
+		msg := fmt.Sprintf("%s has no field %s", parent.Name, sel.field)
+		if suggestions := suggestFields(parent, sel.field); len(suggestions) > 0 {
+			msg += fmt.Sprintf(", did you mean %s?", strings.Join(suggestions, " or "))
+		}
+
+		v.addError(sel.pos, "%s", msg)
+		return
+	}
+
+	given := map[string]bool{}
+	for _, arg := range sel.args {
+		given[arg.name] = true
+
+		if f.arg(arg.name) == nil {
+			v.addError(arg.pos, "%s.%s has no argument %s", parent.Name, f.Name, arg.name)
+		}
+
+		for _, name := range arg.variables {
+			if !v.declared[name] {
+				v.addError(arg.pos, "variable $%s is not declared", name)
+			}
+		}
+	}
+
+	for _, a := range f.Args {
+		if a.Type.required() && a.DefaultValue == nil && !given[a.Name] {
+			v.addError(sel.pos, "%s.%s requires the argument %s: %s", parent.Name, f.Name, a.Name, a.Type)
+		}
+	}
+
+	t := v.schema.lookupType(f.Type.named())
+	if t == nil {
+		return
+	}
+
+	composite := t.Kind == kindObject || t.Kind == kindInterface || t.Kind == kindUnion
+
+	switch {
+	case composite && !sel.hasSelections:
+		v.addError(sel.pos, "%s.%s is a %s and needs a selection of its fields", parent.Name, f.Name, t.Name)
+	case !composite && sel.hasSelections:
+		v.addError(sel.pos, "%s.%s is a %s and cannot have a selection", parent.Name, f.Name, t.Name)
+	case composite:
+		v.selections(sel.selections, t, fragments)
+	}
+}
+
+// suggestFields returns the fields of a type with names similar to the given
+// one: containing it, contained in it, or at most two edits away.
+func suggestFields(t *schemaType, name string) []string {
+	lower := strings.ToLower(name)
+	suggestions := []string{}
+
+	for _, f := range t.Fields {
+		candidate := strings.ToLower(f.Name)
+		if strings.Contains(candidate, lower) || strings.Contains(lower, candidate) || editDistance(candidate, lower) <= 2 {
+			suggestions = append(suggestions, f.Name)
+		}
+	}
+
+	sort.Strings(suggestions)
+
+	if len(suggestions) > 3 {
+		suggestions = suggestions[:3]
+	}
+
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,78 @@
+package nerdgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// readVariablesFile reads GraphQL variables from a JSON file.
+func readVariablesFile(path string) (map[string]interface{}, error) {
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := map[string]interface{}{}
+	if err = json.Unmarshal(out, &vars); err != nil {
+		return nil, fmt.Errorf("could not read variables from %s: %s", path, err)
+	}
+
+	return vars, nil
+}
+
+// parseVars parses variables given as key=value pairs.  Values that are valid
+// JSON, such as numbers, booleans and lists, are decoded so they have the
+// type the query expects, and anything else is a string.
+func parseVars(pairs []string) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+
+	for _, p := range pairs {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected key=value", p)
+		}
+
+		var value interface{}
+		if err := json.Unmarshal([]byte(kv[1]), &value); err != nil {
+			value = kv[1]
+		}
+
+		vars[strings.TrimSpace(kv[0])] = value
+	}
+
+	return vars, nil
+}
+
+// mergeVariables merges sets of variables, later sets taking precedence.
+func mergeVariables(sets ...map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+
+	for _, s := range sets {
+		for k, v := range s {
+			merged[k] = v
+		}
+	}
+
+	return merged
+}
+
+// loadVariables returns the variables given with --variables-file and
+// --variables, the latter taking precedence.
+func loadVariables() (map[string]interface{}, error) {
+	fromFile := map[string]interface{}{}
+	if variablesFile != "" {
+		var err error
+		if fromFile, err = readVariablesFile(variablesFile); err != nil {
+			return nil, err
+		}
+	}
+
+	inline := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(variables), &inline); err != nil {
+		return nil, fmt.Errorf("invalid --variables: %s", err)
+	}
+
+	return mergeVariables(fromFile, inline), nil
+}
@@ -0,0 +1,624 @@
+package nrql
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+// The chart types supported by the --chart flag.
+const (
+	chartAuto      = "auto"
+	chartSparkline = "sparkline"
+	chartLine      = "line"
+	chartBar       = "bar"
+)
+
+const (
+	beginTimeKey = "beginTimeSeconds"
+	endTimeKey   = "endTimeSeconds"
+	facetKey     = "facet"
+
+	lineChartHeight = 12
+	maxLabelWidth   = 30
+)
+
+var (
+	sparkRunes   = []rune("▁▂▃▄▅▆▇█")
+	barRunes     = []rune("▏▎▍▌▋▊▉█")
+	seriesGlyphs = []string{"●", "■", "▲", "◆", "✚", "○", "□", "△"}
+)
+
+var chartTypes = []string{chartAuto, chartSparkline, chartLine, chartBar}
+
+// chartSeries is a named set of values, one per timeseries bucket or facet.
+type chartSeries struct {
+	name   string
+	values []float64
+}
+
+// chartData is a query result reshaped for charting.  For timeseries the
+// buckets hold the start of each time window, and every series has a value
+// for each bucket, NaN where the series has no data.  Otherwise labels hold
+// the facet, or the aggregate name, of each value, and facetNames the
+// attributes faceted by.
+type chartData struct {
+	timeseries bool
+	buckets    []time.Time
+	facetNames []string
+	labels     []string
+	series     []chartSeries
+}
+
+// renderChart renders the query results as a chart no wider than the given
+// width.  The auto chart type draws a line chart for TIMESERIES queries and a
+// bar chart for anything else, such as FACET queries.
+func renderChart(result *nrdb.NRDBResultContainer, chartType string, width int) (string, error) {
+	d, err := newChartData(result)
+	if err != nil {
+		return "", err
+	}
+
+	if chartType == chartAuto {
+		chartType = chartBar
+		if d.timeseries {
+			chartType = chartLine
+		}
+	}
+
+	switch chartType {
+	case chartSparkline:
+		if !d.timeseries {
+			return "", fmt.Errorf("sparkline charts require a TIMESERIES query")
+		}
+
+		return renderSparklines(d, width), nil
+	case chartLine:
+		if !d.timeseries {
+			return "", fmt.Errorf("line charts require a TIMESERIES query")
+		}
+
+		return renderLineChart(d, width), nil
+	case chartBar:
+		if d.timeseries {
+			return "", fmt.Errorf("bar charts are not supported for TIMESERIES queries")
+		}
+
+		return renderBarChart(d, width), nil
+	}
+
+	return "", fmt.Errorf("unknown chart type %s, must be one of %s", chartType, strings.Join(chartTypes, ", "))
+}
+
+func newChartData(result *nrdb.NRDBResultContainer) (*chartData, error) {
+	if result == nil || len(result.Results) == 0 {
+		return nil, fmt.Errorf("the query returned no results to chart")
+	}
+
+	facets := map[string]bool{facetKey: true}
+	for _, f := range result.Metadata.Facets {
+		facets[f] = true
+	}
+
+	_, timeseries := result.Results[0][beginTimeKey]
+	d := &chartData{timeseries: timeseries}
+
+	if timeseries {
+		d.addTimeseries(result.Results, facets)
+	} else {
+		d.addFacets(result.Results, facets, result.Metadata.Facets)
+	}
+
+	if len(d.series) == 0 {
+		return nil, fmt.Errorf("the query returned no numeric values to chart")
+	}
+
+	return d, nil
+}
+
+func (d *chartData) addTimeseries(results []nrdb.NRDBResult, facets map[string]bool) {
+	bucketIndex := map[float64]int{}
+	begins := []float64{}
+
+	for _, r := range results {
+		b, ok := toFloat(r[beginTimeKey])
+		if !ok {
+			continue
+		}
+
+		if _, seen := bucketIndex[b]; !seen {
+			bucketIndex[b] = 0
+			begins = append(begins, b)
+		}
+	}
+
+	sort.Float64s(begins)
+	for i, b := range begins {
+		bucketIndex[b] = i
+		d.buckets = append(d.buckets, time.Unix(int64(b), 0))
+	}
+
+	seriesIndex := map[string]int{}
+	for _, r := range results {
+		b, ok := toFloat(r[beginTimeKey])
+		if !ok {
+			continue
+		}
+
+		facet := facetLabel(r)
+		for _, v := range numericValues(r, facets) {
+			name := v.name
+			if facet != "" {
+				name = fmt.Sprintf("%s (%s)", v.name, facet)
+			}
+
+			i, ok := seriesIndex[name]
+			if !ok {
+				i = len(d.series)
+				seriesIndex[name] = i
+				d.series = append(d.series, chartSeries{name: name, values: nanValues(len(d.buckets))})
+			}
+
+			d.series[i].values[bucketIndex[b]] = v.value
+		}
+	}
+}
+
+func (d *chartData) addFacets(results []nrdb.NRDBResult, facets map[string]bool, facetNames []string) {
+	seriesIndex := map[string]int{}
+
+	faceted := false
+	for _, r := range results {
+		if facetLabel(r) != "" {
+			faceted = true
+		}
+	}
+
+	// Without facets each aggregate of the single result becomes a bar
+	if !faceted {
+		s := chartSeries{name: "value"}
+		for _, v := range numericValues(results[0], facets) {
+			d.labels = append(d.labels, v.name)
+			s.values = append(s.values, v.value)
+		}
+
+		if len(s.values) > 0 {
+			d.series = append(d.series, s)
+		}
+
+		return
+	}
+
+	d.facetNames = facetNames
+	if len(d.facetNames) == 0 {
+		d.facetNames = []string{facetKey}
+	}
+
+	for row, r := range results {
+		d.labels = append(d.labels, facetLabel(r))
+
+		for _, v := range numericValues(r, facets) {
+			i, ok := seriesIndex[v.name]
+			if !ok {
+				i = len(d.series)
+				seriesIndex[v.name] = i
+				d.series = append(d.series, chartSeries{name: v.name, values: nanValues(len(results))})
+			}
+
+			d.series[i].values[row] = v.value
+		}
+	}
+}
+
+type namedValue struct {
+	name  string
+	value float64
+}
+
+// numericValues returns the aggregate values of a result row, ordered by
+// name.  Nested values, such as those returned by percentile(), are named
+// with the nested key appended, e.g. "percentile.duration.95".
+func numericValues(r nrdb.NRDBResult, facets map[string]bool) []namedValue {
+	values := []namedValue{}
+
+	for k, v := range r {
+		if k == beginTimeKey || k == endTimeKey || facets[k] {
+			continue
+		}
+
+		if f, ok := toFloat(v); ok {
+			values = append(values, namedValue{name: k, value: f})
+			continue
+		}
+
+		if nested, ok := v.(map[string]interface{}); ok {
+			for nk, nv := range nested {
+				if f, ok := toFloat(nv); ok {
+					values = append(values, namedValue{name: k + "." + nk, value: f})
+				}
+			}
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].name < values[j].name
+	})
+
+	return values
+}
+
+func facetLabel(r nrdb.NRDBResult) string {
+	switch f := r[facetKey].(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		parts := make([]string, len(f))
+		for i, p := range f {
+			parts[i] = fmt.Sprint(p)
+		}
+
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(f)
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+
+	return 0, false
+}
+
+func nanValues(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = math.NaN()
+	}
+
+	return values
+}
+
+// renderSparklines draws one sparkline per series, followed by its minimum,
+// maximum and latest values.
+func renderSparklines(d *chartData, width int) string {
+	var sb strings.Builder
+
+	labelWidth := seriesLabelWidth(d.series)
+	sparkWidth := width - labelWidth - 40
+	if sparkWidth < 10 {
+		sparkWidth = 10
+	}
+
+	sb.WriteString(timeRangeLabel(d.buckets) + "\n")
+
+	for _, s := range d.series {
+		values := resample(s.values, sparkWidth)
+		lo, hi := valueRange(values, false)
+
+		var spark strings.Builder
+		for _, v := range values {
+			if math.IsNaN(v) {
+				spark.WriteRune(' ')
+				continue
+			}
+
+			spark.WriteRune(sparkRunes[scale(v, lo, hi, len(sparkRunes))])
+		}
+
+		fmt.Fprintf(&sb, "%s  %s  min %s  max %s  last %s\n",
+			padLabel(s.name, labelWidth), spark.String(),
+			formatValue(lo), formatValue(hi), formatValue(lastValue(s.values)))
+	}
+
+	return sb.String()
+}
+
+// renderLineChart draws every series on a shared grid with a labelled y axis,
+// the time range on the x axis and a legend.
+func renderLineChart(d *chartData, width int) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, s := range d.series {
+		l, h := valueRange(s.values, true)
+		lo = math.Min(lo, l)
+		hi = math.Max(hi, h)
+	}
+
+	yLabels := []string{formatValue(hi), formatValue((hi + lo) / 2), formatValue(lo)}
+	yWidth := 0
+	for _, l := range yLabels {
+		if len(l) > yWidth {
+			yWidth = len(l)
+		}
+	}
+
+	plotWidth := width - yWidth - 2
+	if plotWidth < 10 {
+		plotWidth = 10
+	}
+
+	// Each bucket gets the same number of columns, so short series are
+	// stretched and long ones resampled to fit.
+	points := len(d.buckets)
+	step := 1
+	if points > 0 && points < plotWidth {
+		step = plotWidth / points
+	}
+	if points > plotWidth {
+		points = plotWidth
+	}
+
+	grid := make([][]string, lineChartHeight)
+	for r := range grid {
+		grid[r] = make([]string, points*step)
+		for c := range grid[r] {
+			grid[r][c] = " "
+		}
+	}
+
+	for i, s := range d.series {
+		glyph := seriesGlyphs[i%len(seriesGlyphs)]
+
+		for p, v := range resample(s.values, points) {
+			if math.IsNaN(v) {
+				continue
+			}
+
+			row := lineChartHeight - 1 - scale(v, lo, hi, lineChartHeight)
+			for c := p * step; c < (p+1)*step; c++ {
+				grid[row][c] = glyph
+			}
+		}
+	}
+
+	var sb strings.Builder
+	for r, cells := range grid {
+		label := ""
+		switch r {
+		case 0:
+			label = yLabels[0]
+		case lineChartHeight / 2:
+			label = yLabels[1]
+		case lineChartHeight - 1:
+			label = yLabels[2]
+		}
+
+		fmt.Fprintf(&sb, "%*s │%s\n", yWidth, label, strings.Join(cells, ""))
+	}
+
+	fmt.Fprintf(&sb, "%*s └%s\n", yWidth, "", strings.Repeat("─", points*step))
+
+	if len(d.buckets) > 0 {
+		start := formatTime(d.buckets[0], d.buckets)
+		end := formatTime(d.buckets[len(d.buckets)-1], d.buckets)
+		gap := points*step - len(start) - len(end)
+		if gap < 1 {
+			gap = 1
+		}
+
+		fmt.Fprintf(&sb, "%*s  %s%s%s\n", yWidth, "", start, strings.Repeat(" ", gap), end)
+	}
+
+	sb.WriteString("\n")
+	for i, s := range d.series {
+		fmt.Fprintf(&sb, "  %s %s\n", seriesGlyphs[i%len(seriesGlyphs)], s.name)
+	}
+
+	return sb.String()
+}
+
+// renderBarChart draws a horizontal bar for each facet, one chart per
+// aggregate, scaled to the largest value.
+func renderBarChart(d *chartData, width int) string {
+	var sb strings.Builder
+
+	labelWidth := 0
+	for _, l := range d.labels {
+		if w := text.RuneCount(l); w > labelWidth {
+			labelWidth = w
+		}
+	}
+	if labelWidth > maxLabelWidth {
+		labelWidth = maxLabelWidth
+	}
+
+	for i, s := range d.series {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		if d.facetNames != nil {
+			fmt.Fprintf(&sb, "%s by %s\n", s.name, strings.Join(d.facetNames, ", "))
+		}
+
+		_, hi := valueRange(s.values, true)
+
+		valueWidth := 0
+		for _, v := range s.values {
+			if w := len(formatValue(v)); w > valueWidth {
+				valueWidth = w
+			}
+		}
+
+		barWidth := width - labelWidth - valueWidth - 4
+		if barWidth < 10 {
+			barWidth = 10
+		}
+
+		for row, v := range s.values {
+			fmt.Fprintf(&sb, "%s │%s %s\n", padLabel(d.labels[row], labelWidth), bar(v, hi, barWidth), formatValue(v))
+		}
+
+		hiLabel := formatValue(hi)
+		fmt.Fprintf(&sb, "%s └%s\n", strings.Repeat(" ", labelWidth), strings.Repeat("─", barWidth))
+		fmt.Fprintf(&sb, "%s  0%s%s\n", strings.Repeat(" ", labelWidth), strings.Repeat(" ", barWidth-len(hiLabel)-1), hiLabel)
+	}
+
+	return sb.String()
+}
+
+// bar returns a bar of up to width characters for the value, using partial
+// blocks for the remainder.
+func bar(v float64, hi float64, width int) string {
+	if math.IsNaN(v) || v <= 0 || hi <= 0 {
+		return ""
+	}
+
+	eighths := int(math.Round(v / hi * float64(width*8)))
+	full := eighths / 8
+
+	b := strings.Repeat(string(barRunes[len(barRunes)-1]), full)
+	if rem := eighths % 8; rem > 0 {
+		b += string(barRunes[rem-1])
+	}
+
+	return b
+}
+
+// resample averages the values into at most n buckets, ignoring NaNs.
+func resample(values []float64, n int) []float64 {
+	if len(values) <= n || n <= 0 {
+		return values
+	}
+
+	out := make([]float64, n)
+	for i := range out {
+		from := i * len(values) / n
+		to := (i + 1) * len(values) / n
+
+		sum, count := 0.0, 0
+		for _, v := range values[from:to] {
+			if !math.IsNaN(v) {
+				sum += v
+				count++
+			}
+		}
+
+		out[i] = math.NaN()
+		if count > 0 {
+			out[i] = sum / float64(count)
+		}
+	}
+
+	return out
+}
+
+// valueRange returns the smallest and largest values, ignoring NaNs.  When
+// fromZero is set the range always includes zero, so charts are not
+// exaggerated.
+func valueRange(values []float64, fromZero bool) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	if fromZero {
+		lo, hi = 0, 0
+	}
+
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+
+	if math.IsInf(lo, 0) {
+		return 0, 0
+	}
+
+	return lo, hi
+}
+
+// scale maps the value onto one of n steps between lo and hi.
+func scale(v float64, lo float64, hi float64, n int) int {
+	if hi <= lo {
+		return 0
+	}
+
+	i := int(math.Round((v - lo) / (hi - lo) * float64(n-1)))
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+
+	return i
+}
+
+func lastValue(values []float64) float64 {
+	for i := len(values) - 1; i >= 0; i-- {
+		if !math.IsNaN(values[i]) {
+			return values[i]
+		}
+	}
+
+	return math.NaN()
+}
+
+// formatValue formats a value compactly, e.g. 1.5k or 2.25M.
+func formatValue(v float64) string {
+	if math.IsNaN(v) {
+		return "-"
+	}
+
+	suffix := ""
+	switch abs := math.Abs(v); {
+	case abs >= 1e9:
+		v, suffix = v/1e9, "B"
+	case abs >= 1e6:
+		v, suffix = v/1e6, "M"
+	case abs >= 1e4:
+		v, suffix = v/1e3, "k"
+	}
+
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64) + suffix
+}
+
+func formatTime(t time.Time, buckets []time.Time) string {
+	if len(buckets) > 1 && buckets[len(buckets)-1].Sub(buckets[0]) >= 24*time.Hour {
+		return t.Format("Jan 2 15:04")
+	}
+
+	return t.Format("15:04")
+}
+
+func timeRangeLabel(buckets []time.Time) string {
+	if len(buckets) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s to %s", formatTime(buckets[0], buckets), formatTime(buckets[len(buckets)-1], buckets))
+}
+
+func seriesLabelWidth(series []chartSeries) int {
+	w := 0
+	for _, s := range series {
+		if l := text.RuneCount(s.name); l > w {
+			w = l
+		}
+	}
+
+	if w > maxLabelWidth {
+		w = maxLabelWidth
+	}
+
+	return w
+}
+
+func padLabel(label string, width int) string {
+	label = text.Snip(label, width, "…")
+	return label + strings.Repeat(" ", width-text.RuneCount(label))
+}
@@ -0,0 +1,86 @@
+// +build unit
+
+package nrql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+func testTimeseriesResult() *nrdb.NRDBResultContainer {
+	results := []nrdb.NRDBResult{}
+	for i := 0; i < 6; i++ {
+		begin := float64(1612345200 + i*60)
+		results = append(results,
+			nrdb.NRDBResult{"beginTimeSeconds": begin, "endTimeSeconds": begin + 60, "facet": "web", "appName": "web", "count": float64(i * 10)},
+			nrdb.NRDBResult{"beginTimeSeconds": begin, "endTimeSeconds": begin + 60, "facet": "api", "appName": "api", "count": float64(50 - i*10)},
+		)
+	}
+
+	return &nrdb.NRDBResultContainer{
+		Results:  results,
+		Metadata: nrdb.NRDBMetadata{Facets: []string{"appName"}},
+	}
+}
+
+func TestRenderChart_Line(t *testing.T) {
+	chart, err := renderChart(testTimeseriesResult(), chartAuto, 80)
+	require.NoError(t, err)
+
+	lines := strings.Split(chart, "\n")
+	require.Contains(t, lines[0], "50 │")
+	require.Contains(t, chart, "● count (web)")
+	require.Contains(t, chart, "■ count (api)")
+	require.Contains(t, chart, "└──")
+
+	for _, l := range lines {
+		require.LessOrEqual(t, len([]rune(l)), 80)
+	}
+}
+
+func TestRenderChart_Sparkline(t *testing.T) {
+	chart, err := renderChart(testTimeseriesResult(), chartSparkline, 80)
+	require.NoError(t, err)
+	require.Contains(t, chart, "count (web)  ▁▂▄▅▇█  min 0  max 50  last 50")
+	require.Contains(t, chart, "count (api)  █▇▅▄▂▁  min 0  max 50  last 0")
+}
+
+func TestRenderChart_Bar(t *testing.T) {
+	result := &nrdb.NRDBResultContainer{
+		Results: []nrdb.NRDBResult{
+			{"facet": "web", "appName": "web", "count": 20000.0},
+			{"facet": "api", "appName": "api", "count": 5000.0},
+		},
+		Metadata: nrdb.NRDBMetadata{Facets: []string{"appName"}},
+	}
+
+	chart, err := renderChart(result, chartAuto, 40)
+	require.NoError(t, err)
+
+	lines := strings.Split(chart, "\n")
+	require.Equal(t, "count by appName", lines[0])
+	require.Equal(t, "web │"+strings.Repeat("█", 29)+" 20k", lines[1])
+	require.Equal(t, "api │"+strings.Repeat("█", 7)+"▎ 5000", lines[2])
+
+	_, err = renderChart(result, chartLine, 40)
+	require.Error(t, err)
+}
+
+func TestRenderChart_NoResults(t *testing.T) {
+	_, err := renderChart(&nrdb.NRDBResultContainer{}, chartAuto, 80)
+	require.Error(t, err)
+
+	_, err = renderChart(testTimeseriesResult(), "pie", 80)
+	require.Error(t, err)
+}
+
+func TestFormatValue(t *testing.T) {
+	require.Equal(t, "1.23", formatValue(1.2345))
+	require.Equal(t, "12.35k", formatValue(12345))
+	require.Equal(t, "2.5M", formatValue(2500000))
+	require.Equal(t, "-", formatValue(nanValues(1)[0]))
+}
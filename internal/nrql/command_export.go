@@ -0,0 +1,195 @@
+package nrql
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+)
+
+const exportStateSuffix = ".progress"
+
+var (
+	exportChunk  time.Duration
+	exportFormat string
+	exportOut    string
+	exportSince  string
+	exportUntil  string
+)
+
+var cmdExport = &cobra.Command{
+	Use:   "export",
+	Short: "Export the events matching a NRQL query to a file",
+	Long: `Export the events matching a NRQL query to a file
+
+The export command writes every event matching a query over a time window to a
+file.  A single query returns a limited number of events, so the window is queried
+in chunks, and chunks that reach the limit are split until they fit.
+
+The query must not include SINCE, UNTIL, LIMIT, TIMESERIES, FACET or COMPARE WITH
+clauses, which are managed by the command.  The window is given with --since and
+--until, either as RFC3339 timestamps or as durations before now, such as 24h.
+
+Events are written as newline delimited JSON, or as CSV when the output file has a
+.csv extension or --file-format csv is given.  CSV columns are taken from the first
+events exported.  Parquet and other columnar formats are not supported.
+
+Progress is saved alongside the output file as the export runs.  If an export is
+interrupted, running the same command again resumes it where it stopped, over the
+time window it started with.  Running it with a different query, format, --since
+or --until fails until the saved progress is removed.
+`,
+	Example: `newrelic nrql export --accountId 12345678 --query 'SELECT * FROM Transaction' --since 24h --out transactions.ndjson
+newrelic nrql export --accountId 12345678 --query 'SELECT * FROM Log' --since 2021-03-01T00:00:00Z --until 2021-03-02T00:00:00Z --out logs.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client.WithClient(func(nrClient *newrelic.NewRelic) {
+			state, err := newExportState(time.Now())
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			statePath := exportOut + exportStateSuffix
+
+			saved, err := readExportState(statePath)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+			if saved != nil {
+				if !saved.matches(state) {
+					log.Fatalf("%s records an export with a different query, format, --since or --until, remove it to start a new one", statePath)
+				}
+
+				log.Infof("resuming export from %s", saved.Completed.UTC().Format(time.RFC3339))
+				state = saved
+				flags = os.O_CREATE | os.O_WRONLY
+			}
+
+			if exportChunk, err = time.ParseDuration(state.Chunk); err != nil {
+				log.Fatal(err)
+			}
+
+			out, err := os.OpenFile(exportOut, flags, 0640)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer out.Close()
+
+			e := &exporter{
+				client:   &nrClient.Nrdb,
+				chunk:    exportChunk,
+				progress: os.Stderr,
+			}
+
+			save := func(s *exportState) error {
+				return writeExportState(statePath, s)
+			}
+
+			if err = e.export(utils.SignalCtx, out, state, save); err != nil {
+				if utils.SignalCtx.Err() != nil {
+					log.Fatal("export interrupted, run the same command again to resume it")
+				}
+
+				log.Fatal(err)
+			}
+
+			if err = os.Remove(statePath); err != nil {
+				log.Fatal(err)
+			}
+
+			log.Infof("exported %d events to %s", state.Events, exportOut)
+		})
+	},
+}
+
+// newExportState returns the state of a new export from the flags.
+func newExportState(now time.Time) (*exportState, error) {
+	if _, err := checkManagedClauses(query, append([]string{"LIMIT"}, managedClauses...)); err != nil {
+		return nil, fmt.Errorf("invalid export query: %s", err)
+	}
+
+	format := strings.ToLower(exportFormat)
+	if format == "" {
+		format = exportFormatFor(exportOut)
+	}
+
+	if format != exportFormatNDJSON && format != exportFormatCSV {
+		return nil, fmt.Errorf("unknown file format %s, expected one of %s", exportFormat, strings.Join(exportFormats, ", "))
+	}
+
+	since, err := parseExportTime(exportSince, now)
+	if err != nil {
+		return nil, err
+	}
+
+	until, err := parseExportTime(exportUntil, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !until.After(since) {
+		return nil, fmt.Errorf("--until must be after --since")
+	}
+
+	if exportChunk <= 0 {
+		return nil, fmt.Errorf("--chunk must be greater than zero")
+	}
+
+	return &exportState{
+		AccountID: accountID,
+		Query:     query,
+		Format:    format,
+		Since:     since,
+		Until:     until,
+		Chunk:     exportChunk.String(),
+		SinceFlag: exportSince,
+		UntilFlag: exportUntil,
+	}, nil
+}
+
+// parseExportTime parses an RFC3339 timestamp, a duration before now or
+// "now".
+func parseExportTime(value string, now time.Time) (time.Time, error) {
+	if strings.EqualFold(value, "now") {
+		return now, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected an RFC3339 timestamp or a duration such as 24h", value)
+	}
+
+	return now.Add(-d), nil
+}
+
+func init() {
+	Command.AddCommand(cmdExport)
+	cmdExport.Flags().IntVarP(&accountID, "accountId", "a", 0, "the New Relic account ID where you want to query")
+	utils.LogIfError(cmdExport.MarkFlagRequired("accountId"))
+
+	cmdExport.Flags().StringVarP(&query, "query", "q", "", "the NRQL query to export the events of, without a SINCE clause")
+	utils.LogIfError(cmdExport.MarkFlagRequired("query"))
+
+	cmdExport.Flags().StringVarP(&exportOut, "out", "o", "", "the file to write the events to")
+	utils.LogIfError(cmdExport.MarkFlagRequired("out"))
+
+	cmdExport.Flags().StringVar(&exportSince, "since", "", "the start of the time window, as an RFC3339 timestamp or a duration before now")
+	utils.LogIfError(cmdExport.MarkFlagRequired("since"))
+
+	cmdExport.Flags().StringVar(&exportUntil, "until", "now", "the end of the time window, as an RFC3339 timestamp or a duration before now")
+	cmdExport.Flags().DurationVar(&exportChunk, "chunk", time.Hour, "the length of the time window queried at once")
+	cmdExport.Flags().StringVar(&exportFormat, "file-format", "",
+		"the output file format ["+strings.Join(exportFormats, ", ")+"], defaults to the file's extension")
+}
@@ -0,0 +1,69 @@
+package nrql
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var cmdLint = &cobra.Command{
+	Use:   "lint",
+	Short: "Check NRQL queries without running them",
+	Long: `Check NRQL queries without running them
+
+The lint command parses NRQL queries locally and prints each one normalized, with
+one clause per line, followed by any problems found.  Errors, such as syntax errors,
+unknown or duplicate clauses and invalid times, would cause the query to fail.
+Warnings point out queries that may be slow, expensive or not return what was
+intended, such as a missing SINCE clause, SELECT * without a LIMIT or faceting on
+attributes likely to have a high cardinality.
+
+The queries are given with --query or, as for the query command, with --file and
+optionally --name and --var.  The command fails if any query has errors.
+`,
+	Example: `newrelic nrql lint --query 'SELECT * FROM Transaction FACET request.uri'
+newrelic nrql lint --file queries.nrql --var app=checkout --var since='1 hour ago'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		queries, err := loadQueries()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		errorCount := 0
+		for i, q := range queries {
+			if i > 0 {
+				fmt.Println()
+			}
+
+			if len(queries) > 1 {
+				fmt.Printf("-- %s\n", q.Name)
+			}
+
+			normalized, issues := lintQuery(q.Query)
+			if normalized != "" {
+				fmt.Println(normalized)
+			}
+
+			for _, issue := range issues {
+				fmt.Println(issue)
+
+				if issue.Severity == lintError {
+					errorCount++
+				}
+			}
+		}
+
+		if errorCount > 0 {
+			log.Fatalf("found %d errors", errorCount)
+		}
+	},
+}
+
+func init() {
+	Command.AddCommand(cmdLint)
+	cmdLint.Flags().StringVarP(&query, "query", "q", "", "the NRQL query to check")
+	cmdLint.Flags().StringVarP(&queryFile, "file", "f", "", "a file of named NRQL queries to check")
+	cmdLint.Flags().StringSliceVarP(&queryNames, "name", "n", []string{}, "the names of the queries in --file to check, defaults to all")
+	cmdLint.Flags().StringArrayVar(&queryVars, "var", []string{}, "a variable to substitute into the queries, as key=value")
+}
@@ -1,6 +1,11 @@
 package nrql
 
 import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
@@ -8,13 +13,26 @@ import (
 	"github.com/newrelic/newrelic-cli/internal/output"
 	"github.com/newrelic/newrelic-cli/internal/utils"
 	"github.com/newrelic/newrelic-client-go/newrelic"
+	"github.com/newrelic/newrelic-client-go/pkg/accounts"
 	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
 )
 
+// queryNameKey is the field added to each result row naming its query, when
+// the results of several queries are merged.
+const queryNameKey = "query"
+
 var (
 	accountID    int
+	accountIDs   []int
+	allAccounts  bool
+	concurrency  int
+	chartType    string
 	historyLimit int
 	query        string
+	queryFile    string
+	queryNames   []string
+	queryVars    []string
+	watch        time.Duration
 )
 
 var cmdQuery = &cobra.Command{
@@ -22,24 +40,242 @@ var cmdQuery = &cobra.Command{
 	Short: "Execute a NRQL query to New Relic",
 	Long: `Execute a NRQL query to New Relic
 
-The query command requires the --query flag which represents a NRQL query string.
-This command requires the --accountId <int> flag, which specifies the account to
-issue the query against.
+The query command requires either the --query flag, which represents a NRQL query
+string, or the --file flag, which names a file of queries.  This command requires
+the --accountId <int> flag, which specifies the account to issue the query against.
+
+To query several accounts at once use the --accountIds flag with a list of account
+IDs, or the --all-accounts flag for every account you have access to.  The accounts
+are queried concurrently and their results merged, with an accountId column added
+to each row.  Failures in individual accounts are reported without stopping the
+others.
+
+A query file holds one or more queries, each preceded by a "-- name: <name>" line.
+All of the queries are run, or only those selected with --name.  In text output
+each one's results are labeled with its name; in other formats the results are
+merged with a query column added to each row.  Queries may reference variables given with
+--var key=value as {{.key}}.
+
+The --chart flag renders the results in the terminal rather than printing them:
+TIMESERIES queries as a line chart or sparklines, and FACET queries as a bar chart.
+
+The --watch flag re-runs the query at the given interval, redrawing the results
+in place until interrupted.
 `,
-	Example: `newrelic nrql query --accountId 12345678 --query 'SELECT count(*) FROM Transaction TIMESERIES'`,
+	Example: `newrelic nrql query --accountId 12345678 --query 'SELECT count(*) FROM Transaction TIMESERIES'
+newrelic nrql query --accountId 12345678 --query 'SELECT count(*) FROM Transaction FACET appName' --chart
+newrelic nrql query --accountId 12345678 --query 'SELECT count(*) FROM Transaction TIMESERIES' --chart --watch 10s
+newrelic nrql query --accountIds 12345678,87654321 --query 'SELECT count(*) FROM Transaction'
+newrelic nrql query --all-accounts --query 'SELECT count(*) FROM Transaction' --concurrency 10
+newrelic nrql query --accountId 12345678 --file queries.nrql --var app=checkout --var since='1 hour ago'`,
 	Run: func(cmd *cobra.Command, args []string) {
+		queries, err := loadQueries()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if watch != 0 && len(queries) > 1 {
+			log.Fatal("--watch can only be used with a single query")
+		}
+
+		fanOut := allAccounts || len(accountIDs) > 0
+		if err = validateAccountFlags(fanOut); err != nil {
+			log.Fatal(err)
+		}
+
 		client.WithClient(func(nrClient *newrelic.NewRelic) {
+			if watch != 0 {
+				err = watchQuery(utils.SignalCtx, &nrClient.Nrdb, os.Stdout, accountID, queries[0].Query, watch, printQueryResult)
+				if err != nil {
+					log.Fatal(err)
+				}
 
-			result, err := nrClient.Nrdb.Query(accountID, nrdb.NRQL(query))
-			if err != nil {
-				log.Fatal(err)
+				return
 			}
 
-			utils.LogIfFatal(output.Print(result.Results))
+			ids := accountIDs
+			if allAccounts {
+				if ids, err = listAccountIDs(nrClient); err != nil {
+					log.Fatal(err)
+				}
+
+				if len(ids) == 0 {
+					log.Fatal("no accounts found for --all-accounts")
+				}
+			}
+
+			if failed := runQueries(nrClient, queries, ids, fanOut); failed > 0 {
+				log.Fatal("queries failed for every account")
+			}
 		})
 	},
 }
 
+// runQueries runs each query and prints its results, returning the number of
+// queries that failed for every account queried.
+func runQueries(nrClient *newrelic.NewRelic, queries []namedQuery, ids []int, fanOut bool) int {
+	// Several queries are labeled when printed as text or charts, and
+	// otherwise merged with each row tagged with its query's name, so
+	// that structured output stays parseable
+	labeled := len(queries) > 1 && (chartType != "" || output.IsText())
+	tagged := len(queries) > 1 && !labeled
+	merged := []nrdb.NRDBResult{}
+
+	failed := 0
+	for i, q := range queries {
+		if labeled {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("-- %s\n", q.Name)
+		}
+
+		var results []nrdb.NRDBResult
+
+		if fanOut {
+			var errs map[int]error
+			results, errs = queryAccounts(utils.SignalCtx, &nrClient.Nrdb, ids, q.Query, concurrency)
+
+			failedAccounts := 0
+			for _, id := range ids {
+				if errs[id] != nil {
+					failedAccounts++
+					log.Errorf("query failed for account %d: %s", id, errs[id])
+				}
+			}
+
+			if failedAccounts == len(ids) {
+				failed++
+			}
+
+			if !tagged {
+				utils.LogIfFatal(output.Print(results))
+			}
+		} else {
+			result, queryErr := nrClient.Nrdb.Query(accountID, nrdb.NRQL(q.Query))
+			if queryErr != nil {
+				log.Fatal(queryErr)
+			}
+
+			if !tagged {
+				utils.LogIfFatal(printQueryResult(result))
+			}
+
+			results = result.Results
+		}
+
+		if tagged {
+			for _, r := range results {
+				r[queryNameKey] = q.Name
+			}
+
+			merged = append(merged, results...)
+		}
+	}
+
+	if tagged {
+		utils.LogIfFatal(output.Print(merged))
+	}
+
+	return failed
+}
+
+// validateAccountFlags checks that the accounts to query were given in
+// exactly one way, and that fan-out is not combined with features that need
+// a single account's results.
+func validateAccountFlags(fanOut bool) error {
+	given := 0
+	for _, g := range []bool{accountID != 0, len(accountIDs) > 0, allAccounts} {
+		if g {
+			given++
+		}
+	}
+
+	if given != 1 {
+		return fmt.Errorf("one of --accountId, --accountIds or --all-accounts is required")
+	}
+
+	if fanOut && (watch != 0 || chartType != "") {
+		return fmt.Errorf("--watch and --chart can only be used with --accountId")
+	}
+
+	return nil
+}
+
+// listAccountIDs returns the IDs of all accounts the user has access to.
+func listAccountIDs(nrClient *newrelic.NewRelic) ([]int, error) {
+	outlines, err := nrClient.Accounts.ListAccountsWithContext(utils.SignalCtx, accounts.ListAccountsParams{})
+	if err != nil {
+		return nil, fmt.Errorf("could not list accounts: %s", err)
+	}
+
+	ids := make([]int, len(outlines))
+	for i, a := range outlines {
+		ids[i] = a.ID
+	}
+
+	return ids, nil
+}
+
+// loadQueries returns the queries to run, from either the --query or the
+// --file flag, with any variables substituted.
+func loadQueries() ([]namedQuery, error) {
+	if (query == "") == (queryFile == "") {
+		return nil, fmt.Errorf("one of --query or --file is required")
+	}
+
+	if queryFile == "" && len(queryNames) > 0 {
+		return nil, fmt.Errorf("--name can only be used with --file")
+	}
+
+	vars, err := parseQueryVars(queryVars)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := []namedQuery{{Name: "query", Query: query}}
+
+	if queryFile != "" {
+		f, openErr := os.Open(queryFile)
+		if openErr != nil {
+			return nil, openErr
+		}
+		defer f.Close()
+
+		if queries, err = parseQueryFile(f); err != nil {
+			return nil, fmt.Errorf("could not read %s: %s", queryFile, err)
+		}
+
+		if queries, err = selectQueries(queries, queryNames); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, q := range queries {
+		if queries[i].Query, err = renderQuery(q.Name, q.Query, vars); err != nil {
+			return nil, err
+		}
+	}
+
+	return queries, nil
+}
+
+// printQueryResult prints the results, or renders them as a chart if one was
+// requested.
+func printQueryResult(result *nrdb.NRDBResultContainer) error {
+	if chartType != "" {
+		chart, err := renderChart(result, chartType, output.TerminalWidth())
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(chart)
+		return nil
+	}
+
+	return output.Print(result.Results)
+}
+
 var cmdHistory = &cobra.Command{
 	Use:   "history",
 	Short: "Retrieve NRQL query history",
@@ -75,10 +311,18 @@ The history command will fetch a list of the most recent NRQL queries you execut
 func init() {
 	Command.AddCommand(cmdQuery)
 	cmdQuery.Flags().IntVarP(&accountID, "accountId", "a", 0, "the New Relic account ID where you want to query")
-	utils.LogIfError(cmdQuery.MarkFlagRequired("accountId"))
+	cmdQuery.Flags().IntSliceVar(&accountIDs, "accountIds", []int{}, "the New Relic account IDs where you want to query")
+	cmdQuery.Flags().BoolVar(&allAccounts, "all-accounts", false, "query every account you have access to")
+	cmdQuery.Flags().IntVar(&concurrency, "concurrency", defaultConcurrency, "the number of accounts to query at once")
 
 	cmdQuery.Flags().StringVarP(&query, "query", "q", "", "the NRQL query you want to execute")
-	utils.LogIfError(cmdQuery.MarkFlagRequired("query"))
+	cmdQuery.Flags().StringVarP(&queryFile, "file", "f", "", "a file of named NRQL queries to execute")
+	cmdQuery.Flags().StringSliceVarP(&queryNames, "name", "n", []string{}, "the names of the queries in --file to execute, defaults to all")
+	cmdQuery.Flags().StringArrayVar(&queryVars, "var", []string{}, "a variable to substitute into the queries, as key=value")
+
+	cmdQuery.Flags().StringVar(&chartType, "chart", "", "render the results as a chart ["+strings.Join(chartTypes, ", ")+"]")
+	cmdQuery.Flags().Lookup("chart").NoOptDefVal = chartAuto
+	cmdQuery.Flags().DurationVar(&watch, "watch", 0, "re-run the query at the given interval, e.g. 10s")
 
 	Command.AddCommand(cmdHistory)
 	cmdHistory.Flags().IntVarP(&historyLimit, "limit", "l", 10, "history items to return (default: 10, max: 100)")
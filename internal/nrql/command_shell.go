@@ -0,0 +1,108 @@
+package nrql
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chzyer/readline"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+)
+
+const shellHistoryFile = "nrql_history"
+
+var cmdShell = &cobra.Command{
+	Use:   "shell",
+	Short: "Start an interactive NRQL shell",
+	Long: `Start an interactive NRQL shell
+
+The shell command starts a prompt for running NRQL queries against an account,
+which defaults to the account of the default profile.  Queries may span several
+lines and are run when a line ends with ';' or when an empty line is entered.
+
+Tab completes NRQL keywords, the account's event types and the attributes of the
+event types in the FROM clause.  Queries are saved to a history file in the CLI
+configuration directory.
+
+Meta-commands change the shell's settings:
+
+  \account <id>   switch the account queries are run against
+  \format <name>  switch the output format
+  \help           list the meta-commands
+  \quit           exit the shell
+`,
+	Example: `newrelic nrql shell --accountId 12345678`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client.WithClientAndProfile(func(nrClient *newrelic.NewRelic, profile *credentials.Profile) {
+			if accountID == 0 && profile != nil {
+				accountID = profile.AccountID
+			}
+
+			if accountID == 0 {
+				log.Fatal("an account ID is required, set one in your default profile or use the --accountId flag")
+			}
+
+			if err := runShell(newNRQLShell(utils.SignalCtx, &nrClient.Nrdb, os.Stdout, accountID)); err != nil {
+				log.Fatal(err)
+			}
+		})
+	},
+}
+
+func runShell(s *nrqlShell) error {
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:                 s.prompt(),
+		HistoryFile:            filepath.Join(config.DefaultConfigDirectory, shellHistoryFile),
+		DisableAutoSaveHistory: true,
+		AutoComplete:           s,
+		InterruptPrompt:        "^C",
+		EOFPrompt:              `\quit`,
+	})
+	if err != nil {
+		return err
+	}
+	defer rl.Close()
+
+	for {
+		line, err := rl.Readline()
+		if err == readline.ErrInterrupt {
+			s.reset()
+			rl.SetPrompt(s.prompt())
+			continue
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		statement, exit := s.handleLine(line)
+		if exit {
+			return nil
+		}
+
+		if strings.TrimSpace(statement) != "" {
+			if err = rl.SaveHistory(statement); err != nil {
+				log.Debugf("could not save shell history: %s", err)
+			}
+		}
+
+		rl.SetPrompt(s.prompt())
+	}
+}
+
+func init() {
+	Command.AddCommand(cmdShell)
+	cmdShell.Flags().IntVarP(&accountID, "accountId", "a", 0, "the New Relic account ID to query, defaults to the profile's account")
+}
@@ -0,0 +1,18 @@
+// +build unit
+
+package nrql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+)
+
+func TestShell(t *testing.T) {
+	assert.Equal(t, "shell", cmdShell.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdShell)
+	testcobra.CheckCobraRequiredFlags(t, cmdShell, []string{})
+}
@@ -0,0 +1,62 @@
+package nrql
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+var (
+	tailInterval time.Duration
+	tailSince    string
+)
+
+var cmdTail = &cobra.Command{
+	Use:   "tail",
+	Short: "Stream new events matching a NRQL query",
+	Long: `Stream new events matching a NRQL query
+
+The tail command polls a NRQL query for events, printing new ones as they arrive
+until interrupted.  Each poll starts from the timestamp of the newest event seen,
+and events already printed are skipped.
+
+The query must return the timestamp attribute and must not include SINCE, UNTIL,
+TIMESERIES, FACET or COMPARE WITH clauses, which are managed by the command.
+`,
+	Example: `newrelic nrql tail --accountId 12345678 --query "SELECT * FROM Log WHERE level = 'error'"
+newrelic nrql tail --accountId 12345678 --query 'SELECT timestamp, message FROM Log' --interval 10s --since '5 minutes ago'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client.WithClient(func(nrClient *newrelic.NewRelic) {
+			t, err := newTailer(&nrClient.Nrdb, accountID, query, tailSince)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			err = t.run(utils.SignalCtx, tailInterval, func(events []nrdb.NRDBResult) error {
+				return output.Print(events)
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+		})
+	},
+}
+
+func init() {
+	Command.AddCommand(cmdTail)
+	cmdTail.Flags().IntVarP(&accountID, "accountId", "a", 0, "the New Relic account ID where you want to query")
+	utils.LogIfError(cmdTail.MarkFlagRequired("accountId"))
+
+	cmdTail.Flags().StringVarP(&query, "query", "q", "", "the NRQL query to tail, without a SINCE clause")
+	utils.LogIfError(cmdTail.MarkFlagRequired("query"))
+
+	cmdTail.Flags().DurationVar(&tailInterval, "interval", 5*time.Second, "how often to poll for new events")
+	cmdTail.Flags().StringVar(&tailSince, "since", "1 minute ago", "how far back to start, as a NRQL SINCE value")
+}
@@ -0,0 +1,18 @@
+// +build unit
+
+package nrql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/newrelic/newrelic-cli/internal/testcobra"
+)
+
+func TestTail(t *testing.T) {
+	assert.Equal(t, "tail", cmdTail.Name())
+
+	testcobra.CheckCobraMetadata(t, cmdTail)
+	testcobra.CheckCobraRequiredFlags(t, cmdTail, []string{"accountId", "query"})
+}
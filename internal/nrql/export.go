@@ -0,0 +1,335 @@
+package nrql
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+const (
+	exportFormatNDJSON = "ndjson"
+	exportFormatCSV    = "csv"
+
+	// maxQueryResults is the most results a single NRQL query returns with
+	// LIMIT MAX.  A chunk returning this many may have been truncated, so it
+	// is split in two and each half queried again.
+	maxQueryResults = 5000
+
+	// minExportChunk is the narrowest a chunk is split to.  Results beyond
+	// the limit in a window this narrow are lost.
+	minExportChunk = time.Second
+)
+
+var exportFormats = []string{exportFormatNDJSON, exportFormatCSV}
+
+// exportState records the progress of an export, so that an interrupted
+// export can be resumed where it stopped.
+type exportState struct {
+	AccountID int       `json:"accountId"`
+	Query     string    `json:"query"`
+	Format    string    `json:"format"`
+	Since     time.Time `json:"since"`
+	Until     time.Time `json:"until"`
+	Chunk     string    `json:"chunk"`
+	// SinceFlag and UntilFlag are the window as given, which for durations
+	// before now resolve to a different Since and Until on every run.
+	SinceFlag string `json:"sinceFlag"`
+	UntilFlag string `json:"untilFlag"`
+	// Completed is the end of the last chunk written.
+	Completed time.Time `json:"completed"`
+	// Offset is the size of the output file after the last chunk written.
+	Offset  int64    `json:"offset"`
+	Events  int      `json:"events"`
+	Columns []string `json:"columns,omitempty"`
+}
+
+// matches reports whether the state is for the same export, in which case it
+// can be resumed.  The window is compared as given, so that an export over the
+// last day resumes the day it started with.
+func (s *exportState) matches(other *exportState) bool {
+	return s.AccountID == other.AccountID && s.Query == other.Query && s.Format == other.Format &&
+		s.SinceFlag == other.SinceFlag && s.UntilFlag == other.UntilFlag
+}
+
+func readExportState(path string) (*exportState, error) {
+	out, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s exportState
+	if err = json.Unmarshal(out, &s); err != nil {
+		return nil, fmt.Errorf("could not read export progress from %s: %s", path, err)
+	}
+
+	return &s, nil
+}
+
+func writeExportState(path string, s *exportState) error {
+	out, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write then rename, so an interruption never leaves a partial file
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, out, 0640); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// exportFormatFor returns the format for an output file, from its extension.
+func exportFormatFor(path string) string {
+	if strings.EqualFold(strings.TrimPrefix(filepath.Ext(path), "."), exportFormatCSV) {
+		return exportFormatCSV
+	}
+
+	return exportFormatNDJSON
+}
+
+// exporter writes the events matching a query over a time window to a file,
+// querying the window in chunks to stay within the query result limit.
+type exporter struct {
+	client   nrdbClient
+	chunk    time.Duration
+	progress io.Writer
+}
+
+// export writes the events for the remainder of the export described by the
+// state, saving the state after each chunk.  The output file is truncated to
+// the last completed chunk first, dropping anything written by an interrupted
+// chunk.
+func (e *exporter) export(ctx context.Context, out *os.File, state *exportState, save func(*exportState) error) error {
+	if e.chunk <= 0 {
+		return fmt.Errorf("the chunk size must be greater than zero")
+	}
+
+	if err := out.Truncate(state.Offset); err != nil {
+		return err
+	}
+
+	if _, err := out.Seek(state.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	start := state.Since
+	if state.Completed.After(start) {
+		start = state.Completed
+	}
+
+	total := chunkCount(state.Since, state.Until, e.chunk)
+	done := chunkCount(state.Since, start, e.chunk)
+
+	for from := start; from.Before(state.Until); from = from.Add(e.chunk) {
+		to := from.Add(e.chunk)
+		if to.After(state.Until) {
+			to = state.Until
+		}
+
+		events, err := e.fetch(ctx, state, from, to)
+		if err != nil {
+			return err
+		}
+
+		if err = e.write(out, state, events); err != nil {
+			return err
+		}
+
+		if state.Offset, err = out.Seek(0, io.SeekCurrent); err != nil {
+			return err
+		}
+
+		state.Completed = to
+		state.Events += len(events)
+
+		if err = save(state); err != nil {
+			return err
+		}
+
+		done++
+		fmt.Fprintf(e.progress, "[%d/%d] %s - %s: %d events (%d total)\n",
+			done, total, from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339), len(events), state.Events)
+	}
+
+	return nil
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func chunkCount(from time.Time, to time.Time, chunk time.Duration) int {
+	if !to.After(from) {
+		return 0
+	}
+
+	n := int(to.Sub(from) / chunk)
+	if to.Sub(from)%chunk != 0 {
+		n++
+	}
+
+	return n
+}
+
+// fetch returns the events in the window, oldest first.  Windows that hit the
+// result limit are split in two until they fit.
+func (e *exporter) fetch(ctx context.Context, state *exportState, from time.Time, to time.Time) ([]nrdb.NRDBResult, error) {
+	query := fmt.Sprintf("%s SINCE %d UNTIL %d LIMIT MAX", state.Query, toMillis(from), toMillis(to))
+
+	result, err := e.client.QueryWithContext(ctx, state.AccountID, nrdb.NRQL(query))
+	if err != nil {
+		return nil, err
+	}
+
+	events := result.Results
+
+	if len(events) >= maxQueryResults {
+		half := to.Sub(from) / 2
+		if half < minExportChunk {
+			log.Warnf("more than %d events between %s and %s, some were not exported", maxQueryResults, from, to)
+		} else {
+			first, firstErr := e.fetch(ctx, state, from, from.Add(half))
+			if firstErr != nil {
+				return nil, firstErr
+			}
+
+			second, secondErr := e.fetch(ctx, state, from.Add(half), to)
+			if secondErr != nil {
+				return nil, secondErr
+			}
+
+			return append(first, second...), nil
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		a, _ := toFloat(events[i][timestampKey])
+		b, _ := toFloat(events[j][timestampKey])
+		return a < b
+	})
+
+	return events, nil
+}
+
+func (e *exporter) write(w io.Writer, state *exportState, events []nrdb.NRDBResult) error {
+	if state.Format == exportFormatCSV {
+		return writeCSVEvents(w, state, events)
+	}
+
+	enc := json.NewEncoder(w)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeCSVEvents writes events as CSV rows.  The columns are taken from the
+// first events written, with a header row, and attributes first seen in later
+// events are left out.
+func writeCSVEvents(w io.Writer, state *exportState, events []nrdb.NRDBResult) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	cw := csv.NewWriter(w)
+
+	if state.Columns == nil {
+		state.Columns = eventColumns(events)
+		if err := cw.Write(state.Columns); err != nil {
+			return err
+		}
+	}
+
+	known := map[string]bool{}
+	for _, c := range state.Columns {
+		known[c] = true
+	}
+
+	dropped := map[string]bool{}
+	row := make([]string, len(state.Columns))
+
+	for _, ev := range events {
+		for k := range ev {
+			if !known[k] {
+				dropped[k] = true
+			}
+		}
+
+		for i, c := range state.Columns {
+			row[i] = csvValue(ev[c])
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	for k := range dropped {
+		log.Warnf("attribute %s was not in the first events exported and is left out of the CSV", k)
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// eventColumns returns the attributes of the events, timestamp first and the
+// rest sorted.
+func eventColumns(events []nrdb.NRDBResult) []string {
+	seen := map[string]bool{}
+	columns := []string{}
+
+	for _, ev := range events {
+		for k := range ev {
+			if !seen[k] && k != timestampKey {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+
+	sort.Strings(columns)
+
+	return append([]string{timestampKey}, columns...)
+}
+
+func csvValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(out)
+}
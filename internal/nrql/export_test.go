@@ -0,0 +1,217 @@
+// +build unit
+
+package nrql
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+var testWindowClause = regexp.MustCompile(`SINCE (\d+) UNTIL (\d+) LIMIT MAX$`)
+
+// eventNRDBClient answers queries from a list of event timestamps, returning
+// at most maxQueryResults of the events in the queried window.
+type eventNRDBClient struct {
+	timestamps []int64
+	queries    int
+	failAfter  int
+}
+
+func (c *eventNRDBClient) QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) {
+	c.queries++
+	if c.failAfter > 0 && c.queries > c.failAfter {
+		return nil, fmt.Errorf("query failed")
+	}
+
+	m := testWindowClause.FindStringSubmatch(string(query))
+	if m == nil {
+		return nil, fmt.Errorf("unexpected query %s", query)
+	}
+
+	since, _ := strconv.ParseInt(m[1], 10, 64)
+	until, _ := strconv.ParseInt(m[2], 10, 64)
+
+	results := []nrdb.NRDBResult{}
+	for i := len(c.timestamps) - 1; i >= 0 && len(results) < maxQueryResults; i-- {
+		if ts := c.timestamps[i]; ts >= since && ts < until {
+			results = append(results, nrdb.NRDBResult{"timestamp": float64(ts), "n": float64(i)})
+		}
+	}
+
+	return &nrdb.NRDBResultContainer{Results: results}, nil
+}
+
+func newTestExport(t *testing.T, format string) (*exportState, *os.File) {
+	since := time.Unix(0, 0)
+
+	out, err := ioutil.TempFile("", "export")
+	require.NoError(t, err)
+
+	return &exportState{
+		AccountID: 1,
+		Query:     "SELECT * FROM Transaction",
+		Format:    format,
+		Since:     since,
+		Until:     since.Add(3 * time.Hour),
+		Chunk:     "1h",
+	}, out
+}
+
+func readNDJSON(t *testing.T, path string) []float64 {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	n := []float64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var ev map[string]float64
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev))
+		n = append(n, ev["n"])
+	}
+
+	return n
+}
+
+func TestExporter_SplitsFullChunks(t *testing.T) {
+	c := &eventNRDBClient{}
+	for i := 0; i < maxQueryResults+1000; i++ {
+		c.timestamps = append(c.timestamps, int64(i))
+	}
+	c.timestamps = append(c.timestamps, int64(2*time.Hour/time.Millisecond))
+
+	state, out := newTestExport(t, exportFormatNDJSON)
+	defer os.Remove(out.Name())
+
+	e := &exporter{client: c, chunk: time.Hour, progress: ioutil.Discard}
+
+	saves := 0
+	err := e.export(context.Background(), out, state, func(*exportState) error {
+		saves++
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, saves)
+	assert.Equal(t, len(c.timestamps), state.Events)
+
+	n := readNDJSON(t, out.Name())
+	require.Len(t, n, len(c.timestamps))
+	for i := range n {
+		assert.Equal(t, float64(i), n[i])
+	}
+}
+
+func TestExporter_Resume(t *testing.T) {
+	c := &eventNRDBClient{
+		timestamps: []int64{1000, int64(time.Hour/time.Millisecond) + 1000, int64(2*time.Hour/time.Millisecond) + 1000},
+		failAfter:  2,
+	}
+
+	state, out := newTestExport(t, exportFormatNDJSON)
+	defer os.Remove(out.Name())
+
+	statePath := out.Name() + exportStateSuffix
+	defer os.Remove(statePath)
+
+	save := func(s *exportState) error {
+		return writeExportState(statePath, s)
+	}
+
+	e := &exporter{client: c, chunk: time.Hour, progress: ioutil.Discard}
+	require.Error(t, e.export(context.Background(), out, state, save))
+
+	// Simulate a partial write from the interrupted chunk
+	_, err := out.WriteString(`{"partial":`)
+	require.NoError(t, err)
+
+	saved, err := readExportState(statePath)
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.True(t, saved.matches(state))
+	assert.Equal(t, 2, saved.Events)
+
+	c.failAfter = 0
+	require.NoError(t, e.export(context.Background(), out, saved, save))
+
+	assert.Equal(t, []float64{0, 1, 2}, readNDJSON(t, out.Name()))
+}
+
+func TestExportState_Matches(t *testing.T) {
+	state, out := newTestExport(t, exportFormatNDJSON)
+	defer os.Remove(out.Name())
+
+	state.SinceFlag = "24h"
+	state.UntilFlag = "now"
+
+	// The same flags an hour later resume the saved window
+	later := *state
+	later.Since = later.Since.Add(time.Hour)
+	later.Until = later.Until.Add(time.Hour)
+	assert.True(t, state.matches(&later))
+
+	other := *state
+	other.SinceFlag = "48h"
+	assert.False(t, state.matches(&other))
+
+	other = *state
+	other.UntilFlag = "2021-03-02T00:00:00Z"
+	assert.False(t, state.matches(&other))
+
+	other = *state
+	other.Format = exportFormatCSV
+	assert.False(t, state.matches(&other))
+}
+
+func TestExporter_CSV(t *testing.T) {
+	c := &eventNRDBClient{timestamps: []int64{2000, 1000}}
+
+	state, out := newTestExport(t, exportFormatCSV)
+	defer os.Remove(out.Name())
+
+	e := &exporter{client: c, chunk: time.Hour, progress: ioutil.Discard}
+
+	require.NoError(t, e.export(context.Background(), out, state, func(*exportState) error { return nil }))
+
+	contents, err := ioutil.ReadFile(out.Name())
+	require.NoError(t, err)
+	assert.Equal(t, "timestamp,n\n1000,1\n2000,0\n", string(contents))
+}
+
+func TestParseExportTime(t *testing.T) {
+	now := time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	ts, err := parseExportTime("24h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-24*time.Hour), ts)
+
+	ts, err = parseExportTime("2021-03-01T12:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC), ts)
+
+	ts, err = parseExportTime("now", now)
+	require.NoError(t, err)
+	assert.Equal(t, now, ts)
+
+	_, err = parseExportTime("yesterday", now)
+	require.Error(t, err)
+}
+
+func TestExportFormatFor(t *testing.T) {
+	assert.Equal(t, exportFormatCSV, exportFormatFor("results.CSV"))
+	assert.Equal(t, exportFormatNDJSON, exportFormatFor("results.ndjson"))
+	assert.Equal(t, exportFormatNDJSON, exportFormatFor("results"))
+}
@@ -0,0 +1,102 @@
+package nrql
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+const (
+	// accountIDKey is the attribute added to each result of a query run
+	// against several accounts, identifying the account it came from.
+	accountIDKey = "accountId"
+
+	// defaultConcurrency is the default number of accounts queried at once.
+	defaultConcurrency = 5
+)
+
+// accountResult is the outcome of running a query against one account.
+type accountResult struct {
+	accountID int
+	results   []nrdb.NRDBResult
+	err       error
+}
+
+// queryAccounts runs the query against each account, at most concurrency at
+// a time.  The results of each account are tagged with its ID and merged in
+// the order the accounts were given.  A failure in one account does not stop
+// the others; the errors are returned keyed by account ID.
+func queryAccounts(
+	ctx context.Context,
+	client nrdbClient,
+	accountIDs []int,
+	query string,
+	concurrency int,
+) ([]nrdb.NRDBResult, map[int]error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	outcomes := make([]accountResult, len(accountIDs))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(accountIDs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				outcomes[i] = queryAccount(ctx, client, accountIDs[i], query)
+			}
+		}()
+	}
+
+	for i := range accountIDs {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	merged := []nrdb.NRDBResult{}
+	errs := map[int]error{}
+
+	for _, o := range outcomes {
+		if o.err != nil {
+			errs[o.accountID] = o.err
+			continue
+		}
+
+		merged = append(merged, o.results...)
+	}
+
+	return merged, errs
+}
+
+func queryAccount(ctx context.Context, client nrdbClient, accountID int, query string) accountResult {
+	log.WithFields(log.Fields{
+		"accountId": accountID,
+		"query":     query,
+	}).Debug("querying account")
+
+	result, err := client.QueryWithContext(ctx, accountID, nrdb.NRQL(query))
+	if err != nil {
+		return accountResult{accountID: accountID, err: err}
+	}
+
+	results := make([]nrdb.NRDBResult, len(result.Results))
+	for i, r := range result.Results {
+		tagged := nrdb.NRDBResult{accountIDKey: accountID}
+		for k, v := range r {
+			tagged[k] = v
+		}
+
+		results[i] = tagged
+	}
+
+	return accountResult{accountID: accountID, results: results}
+}
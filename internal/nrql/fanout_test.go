@@ -0,0 +1,77 @@
+// +build unit
+
+package nrql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+type accountNRDBClient struct {
+	mu      sync.Mutex
+	running int
+	maxSeen int
+	release chan struct{}
+}
+
+func (c *accountNRDBClient) QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) {
+	c.mu.Lock()
+	c.running++
+	if c.running > c.maxSeen {
+		c.maxSeen = c.running
+	}
+	c.mu.Unlock()
+
+	<-c.release
+
+	c.mu.Lock()
+	c.running--
+	c.mu.Unlock()
+
+	if accountID == 3 {
+		return nil, errors.New("no access")
+	}
+
+	return &nrdb.NRDBResultContainer{
+		Results: []nrdb.NRDBResult{{"count": float64(accountID * 10)}},
+	}, nil
+}
+
+func TestQueryAccounts(t *testing.T) {
+	c := &accountNRDBClient{release: make(chan struct{})}
+	close(c.release)
+
+	results, errs := queryAccounts(context.Background(), c, []int{1, 2, 3, 4}, "SELECT count(*) FROM Transaction", 2)
+
+	require.Len(t, results, 3)
+	assert.Equal(t, nrdb.NRDBResult{"accountId": 1, "count": float64(10)}, results[0])
+	assert.Equal(t, nrdb.NRDBResult{"accountId": 2, "count": float64(20)}, results[1])
+	assert.Equal(t, nrdb.NRDBResult{"accountId": 4, "count": float64(40)}, results[2])
+
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[3], "no access")
+}
+
+func TestQueryAccounts_Concurrency(t *testing.T) {
+	c := &accountNRDBClient{release: make(chan struct{})}
+
+	done := make(chan struct{})
+	go func() {
+		queryAccounts(context.Background(), c, []int{1, 2, 4, 5, 6, 7}, "SELECT count(*) FROM Transaction", 3)
+		close(done)
+	}()
+
+	for i := 0; i < 6; i++ {
+		c.release <- struct{}{}
+	}
+	<-done
+
+	assert.LessOrEqual(t, c.maxSeen, 3)
+}
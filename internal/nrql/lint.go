@@ -0,0 +1,252 @@
+package nrql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/newrelic/newrelic-cli/internal/nrql/parser"
+)
+
+const (
+	lintError   = "error"
+	lintWarning = "warning"
+)
+
+// lintIssue is a problem found in a query.  Pos is the byte offset in the
+// query the problem was found at.
+type lintIssue struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Pos      int    `json:"position"`
+}
+
+func (i lintIssue) String() string {
+	return fmt.Sprintf("%s: %s (position %d)", i.Severity, i.Message, i.Pos+1)
+}
+
+var (
+	timeUnits = map[string]bool{
+		"SECOND": true, "SECONDS": true, "MINUTE": true, "MINUTES": true, "HOUR": true, "HOURS": true,
+		"DAY": true, "DAYS": true, "WEEK": true, "WEEKS": true, "MONTH": true, "MONTHS": true,
+		"QUARTER": true, "QUARTERS": true, "YEAR": true, "YEARS": true,
+	}
+
+	// highCardinalityAttribute matches attributes that usually have a value
+	// per request, host or entity, so produce a large number of facets.
+	highCardinalityAttribute = regexp.MustCompile(`(?i)(id|guid|uuid|uri|url|path|timestamp|message|ip|address|sessionid|traceid|spanid)$`)
+)
+
+// lintQuery checks a query, returning it normalized along with the problems
+// found.  The normalized query is empty if the query could not be parsed.
+func lintQuery(query string) (string, []lintIssue) {
+	q, err := parser.Parse(query)
+	if err != nil {
+		pos := 0
+		if pe, ok := err.(*parser.Error); ok {
+			pos = pe.Pos
+			err = fmt.Errorf("%s", pe.Msg)
+		}
+
+		return "", []lintIssue{{Severity: lintError, Message: err.Error(), Pos: pos}}
+	}
+
+	l := &linter{query: q}
+	l.check()
+
+	return q.String(), l.issues
+}
+
+type linter struct {
+	query  *parser.Query
+	issues []lintIssue
+}
+
+func (l *linter) add(severity string, pos int, format string, args ...interface{}) {
+	l.issues = append(l.issues, lintIssue{
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+		Pos:      pos,
+	})
+}
+
+func (l *linter) check() {
+	if l.query.Clause("SHOW EVENT TYPES") != nil {
+		return
+	}
+
+	if l.query.Clause("SELECT") == nil {
+		l.add(lintError, 0, "missing SELECT clause")
+	}
+
+	if l.query.Clause("FROM") == nil {
+		l.add(lintError, 0, "missing FROM clause")
+	}
+
+	for _, c := range l.query.Clauses {
+		l.checkClause(c)
+	}
+
+	l.checkTimeWindow()
+	l.checkLimits()
+	l.checkFacets()
+}
+
+func (l *linter) checkClause(c *parser.Clause) {
+	if len(c.Tokens) == 0 && c.Name != "TIMESERIES" && c.Name != "EXTRAPOLATE" {
+		l.add(lintError, c.Pos, "%s clause is empty", c.Name)
+		return
+	}
+
+	switch c.Name {
+	case "SELECT", "FACET", "ORDER BY":
+		l.checkList(c)
+	case "FROM":
+		for _, item := range l.checkList(c) {
+			if len(item) > 1 || (item[0].Kind != parser.Word && item[0].Kind != parser.Identifier) {
+				l.add(lintError, item[0].Pos, "FROM expects event type names, found %s", parser.JoinTokens(item))
+			}
+		}
+	case "WHERE":
+		last := c.Tokens[len(c.Tokens)-1]
+		if last.Is("AND", "OR", "NOT", "IN", "LIKE", "IS") || (last.Kind == parser.Symbol && last.Text != ")" && last.Text != "*") {
+			l.add(lintError, last.Pos, "WHERE clause ends with %s", last.Text)
+		}
+	case "SINCE", "UNTIL", "COMPARE WITH":
+		if !isTimeExpression(c.Tokens) {
+			l.add(lintError, c.Pos, "invalid time in %s clause: %s", c.Name, parser.JoinTokens(c.Tokens))
+		}
+	case "LIMIT", "OFFSET":
+		if len(c.Tokens) != 1 || !(c.Tokens[0].Kind == parser.Number || (c.Name == "LIMIT" && c.Tokens[0].Is("MAX"))) {
+			l.add(lintError, c.Pos, "%s expects a number, found %s", c.Name, parser.JoinTokens(c.Tokens))
+		}
+	case "TIMESERIES":
+		l.checkTimeseries(c)
+	case "WITH TIMEZONE":
+		if len(c.Tokens) != 1 || c.Tokens[0].Kind != parser.String {
+			l.add(lintError, c.Pos, "WITH TIMEZONE expects a quoted time zone, such as 'America/Los_Angeles'")
+		}
+	}
+}
+
+// checkList reports empty items in a comma separated list, returning the
+// items that are not empty.
+func (l *linter) checkList(c *parser.Clause) [][]parser.Token {
+	items := [][]parser.Token{}
+
+	for _, item := range parser.SplitList(c.Tokens) {
+		if len(item) == 0 {
+			l.add(lintError, c.Pos, "empty item in %s clause", c.Name)
+			continue
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func (l *linter) checkTimeseries(c *parser.Clause) {
+	tokens := c.Tokens
+
+	for i, t := range tokens {
+		if t.Is("SLIDE") {
+			if i+1 >= len(tokens) || !tokens[i+1].Is("BY") || !isBucket(tokens[i+2:]) {
+				l.add(lintError, t.Pos, "SLIDE BY expects AUTO or a duration, such as 5 minutes")
+			}
+
+			tokens = tokens[:i]
+			break
+		}
+	}
+
+	if len(tokens) > 0 && !isBucket(tokens) {
+		l.add(lintError, c.Pos, "TIMESERIES expects AUTO, MAX or a duration, found %s", parser.JoinTokens(tokens))
+	}
+}
+
+func (l *linter) checkTimeWindow() {
+	since := l.query.Clause("SINCE")
+	until := l.query.Clause("UNTIL")
+
+	if until != nil && since == nil {
+		l.add(lintError, until.Pos, "UNTIL requires a SINCE clause")
+	}
+
+	if since == nil {
+		l.add(lintWarning, 0, "no SINCE clause, the query covers the last hour by default")
+	}
+}
+
+func (l *linter) checkLimits() {
+	sel := l.query.Clause("SELECT")
+	if sel == nil || l.query.Clause("LIMIT") != nil {
+		return
+	}
+
+	for _, item := range parser.SplitList(sel.Tokens) {
+		if len(item) == 1 && item[0].Kind == parser.Symbol && item[0].Text == "*" {
+			l.add(lintWarning, sel.Pos, "SELECT * without a LIMIT returns only the 100 most recent events")
+			return
+		}
+	}
+}
+
+func (l *linter) checkFacets() {
+	facet := l.query.Clause("FACET")
+	if facet == nil {
+		return
+	}
+
+	items := parser.SplitList(facet.Tokens)
+
+	for _, item := range items {
+		if len(item) == 1 && item[0].Kind != parser.String && highCardinalityAttribute.MatchString(item[0].Value()) {
+			l.add(lintWarning, item[0].Pos, "FACET %s may have a high cardinality, consider faceting on a coarser attribute", item[0].Text)
+		}
+	}
+
+	if limit := l.query.Clause("LIMIT"); limit != nil && len(limit.Tokens) == 1 && limit.Tokens[0].Is("MAX") {
+		l.add(lintWarning, limit.Pos, "FACET with LIMIT MAX can return a very large number of facets")
+	}
+
+	if len(items) > 2 {
+		l.add(lintWarning, facet.Pos, "FACET on %d attributes multiplies the number of facets", len(items))
+	}
+
+	if l.query.Clause("TIMESERIES") != nil && len(items) > 1 {
+		l.add(lintWarning, facet.Pos, "FACET on several attributes with TIMESERIES returns a series per combination")
+	}
+}
+
+// isTimeExpression reports whether the tokens are a time, such as
+// "1 day ago", "today", "this week", "now", a timestamp or a quoted date.
+func isTimeExpression(tokens []parser.Token) bool {
+	switch len(tokens) {
+	case 1:
+		return tokens[0].Kind == parser.Number || tokens[0].Kind == parser.String || tokens[0].Is("NOW", "TODAY", "YESTERDAY")
+	case 2:
+		return tokens[0].Is("THIS", "LAST") && isTimeUnit(tokens[1])
+	case 3:
+		return tokens[0].Kind == parser.Number && isTimeUnit(tokens[1]) && tokens[2].Is("AGO")
+	}
+
+	return false
+}
+
+// isBucket reports whether the tokens are a TIMESERIES bucket size, such as
+// "AUTO", "MAX" or "5 minutes".
+func isBucket(tokens []parser.Token) bool {
+	switch len(tokens) {
+	case 1:
+		return tokens[0].Is("AUTO", "MAX") || isTimeUnit(tokens[0])
+	case 2:
+		return tokens[0].Kind == parser.Number && isTimeUnit(tokens[1])
+	}
+
+	return false
+}
+
+func isTimeUnit(t parser.Token) bool {
+	return t.Kind == parser.Word && timeUnits[strings.ToUpper(t.Text)]
+}
@@ -0,0 +1,62 @@
+// +build unit
+
+package nrql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lintMessages(query string) []string {
+	_, issues := lintQuery(query)
+
+	messages := []string{}
+	for _, i := range issues {
+		messages = append(messages, i.Severity+": "+i.Message)
+	}
+
+	return messages
+}
+
+func TestLintQuery_Clean(t *testing.T) {
+	normalized, issues := lintQuery("select average(duration) from Transaction where appName like '%checkout%' since 1 hour ago timeseries 5 minutes")
+	assert.Empty(t, issues)
+	assert.Equal(t, `SELECT average(duration)
+FROM Transaction
+WHERE appName LIKE '%checkout%'
+SINCE 1 hour AGO
+TIMESERIES 5 minutes`, normalized)
+
+	assert.Empty(t, lintMessages("SHOW EVENT TYPES"))
+	assert.Empty(t, lintMessages("SELECT * FROM Log SINCE today LIMIT MAX"))
+	assert.Empty(t, lintMessages("SELECT count(*) FROM Log SINCE 1614556800000 UNTIL 1614643200000 TIMESERIES AUTO SLIDE BY 5 minutes"))
+}
+
+func TestLintQuery_Errors(t *testing.T) {
+	assert.Equal(t, []string{"error: unknown clause FOO"}, lintMessages("FOO count(*) FROM Transaction"))
+
+	assert.Contains(t, lintMessages("SELECT count(*) SINCE 1 day ago"), "error: missing FROM clause")
+	assert.Contains(t, lintMessages("SELECT count(*) FROM Transaction SINCE 1 day"), "error: invalid time in SINCE clause: 1 day")
+	assert.Contains(t, lintMessages("SELECT count(*) FROM Transaction SINCE 1 day ago LIMIT ten"), "error: LIMIT expects a number, found ten")
+	assert.Contains(t, lintMessages("SELECT count(*) FROM Transaction WHERE a = 1 AND SINCE 1 day ago"), "error: WHERE clause ends with AND")
+	assert.Contains(t, lintMessages("SELECT count(*), FROM Transaction SINCE 1 day ago"), "error: empty item in SELECT clause")
+	assert.Contains(t, lintMessages("SELECT count(*) FROM Transaction UNTIL 1 day ago"), "error: UNTIL requires a SINCE clause")
+	assert.Contains(t, lintMessages("SELECT count(*) FROM Transaction SINCE 1 day ago TIMESERIES 5"),
+		"error: TIMESERIES expects AUTO, MAX or a duration, found 5")
+}
+
+func TestLintQuery_Warnings(t *testing.T) {
+	messages := lintMessages("SELECT * FROM Transaction")
+	require.Len(t, messages, 2)
+	assert.Contains(t, messages, "warning: no SINCE clause, the query covers the last hour by default")
+	assert.Contains(t, messages, "warning: SELECT * without a LIMIT returns only the 100 most recent events")
+
+	messages = lintMessages("SELECT count(*) FROM Transaction FACET request.uri, appName SINCE 1 day ago LIMIT MAX TIMESERIES")
+	assert.Equal(t, []string{
+		"warning: FACET request.uri may have a high cardinality, consider faceting on a coarser attribute",
+		"warning: FACET with LIMIT MAX can return a very large number of facets",
+		"warning: FACET on several attributes with TIMESERIES returns a series per combination",
+	}, messages)
+}
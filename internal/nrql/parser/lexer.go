@@ -0,0 +1,186 @@
+// Package parser tokenizes NRQL queries and splits them into their clauses.
+// It is shared by the commands that inspect queries locally, such as the
+// linter and the shell, and by the NRDB simulator used to validate installs.
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind is the kind of a lexical token.
+type TokenKind int
+
+const (
+	// Word is a keyword, function, attribute or event type name.
+	Word TokenKind = iota
+	// Number is a numeric literal.
+	Number
+	// String is a quoted string literal.
+	String
+	// Identifier is a name quoted with backticks.
+	Identifier
+	// Symbol is an operator or punctuation.
+	Symbol
+)
+
+// Token is a lexical token of a NRQL query.  Text is the token as written,
+// including any quotes, and Pos is its byte offset in the query.
+type Token struct {
+	Kind TokenKind
+	Text string
+	Pos  int
+}
+
+// Is reports whether the token is one of the given keywords, ignoring case.
+func (t Token) Is(words ...string) bool {
+	if t.Kind != Word {
+		return false
+	}
+
+	for _, w := range words {
+		if strings.EqualFold(t.Text, w) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsSymbol reports whether the token is the given symbol.
+func (t Token) IsSymbol(symbol string) bool {
+	return t.Kind == Symbol && t.Text == symbol
+}
+
+// Value returns the token without its quotes, with escaped characters in
+// strings unescaped.
+func (t Token) Value() string {
+	switch t.Kind {
+	case String:
+		var b strings.Builder
+
+		body := t.Text[1 : len(t.Text)-1]
+		for i := 0; i < len(body); i++ {
+			if body[i] == '\\' && i+1 < len(body) {
+				i++
+			}
+
+			b.WriteByte(body[i])
+		}
+
+		return b.String()
+	case Identifier:
+		return t.Text[1 : len(t.Text)-1]
+	}
+
+	return t.Text
+}
+
+// Error is an error at a position in a query.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos+1)
+}
+
+// Tokenize splits a query into tokens, dropping whitespace and comments.  The
+// tokens read before any error are returned along with it, so that partial
+// queries can still be inspected.
+func Tokenize(query string) ([]Token, error) {
+	tokens := []Token{}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case strings.HasPrefix(query[i:], "--") || strings.HasPrefix(query[i:], "//"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return tokens, nil
+			}
+			i += end + 1
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return tokens, &Error{i, "unterminated comment"}
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(query) && query[end] != c {
+				if query[end] == '\\' {
+					end++
+				}
+				end++
+			}
+
+			if end >= len(query) {
+				return tokens, &Error{i, "unterminated string"}
+			}
+
+			kind := String
+			if c == '`' {
+				kind = Identifier
+			}
+
+			tokens = append(tokens, Token{kind, query[i : end+1], i})
+			i = end + 1
+		case isDigit(c):
+			end := i
+			for end < len(query) && (isDigit(query[end]) || query[end] == '.') {
+				end++
+			}
+
+			tokens = append(tokens, Token{Number, query[i:end], i})
+			i = end
+		case isWordStart(query[i:]):
+			end := i
+			for end < len(query) && (isWordStart(query[end:]) || isDigit(query[end])) {
+				end++
+			}
+
+			tokens = append(tokens, Token{Word, query[i:end], i})
+			i = end
+		default:
+			symbol := string(c)
+			for _, s := range []string{"!=", "<>", "<=", ">="} {
+				if strings.HasPrefix(query[i:], s) {
+					symbol = s
+				}
+			}
+
+			if !strings.Contains("(),*=<>!+-/%.", symbol[:1]) {
+				r, _ := utf8.DecodeRuneInString(query[i:])
+				return tokens, &Error{i, fmt.Sprintf("unexpected character %q", r)}
+			}
+
+			tokens = append(tokens, Token{Symbol, symbol, i})
+			i += len(symbol)
+		}
+	}
+
+	return tokens, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isWordStart reports whether the text starts with a byte that may be part
+// of a word.  The bytes of multi-byte letters are all accepted.
+func isWordStart(text string) bool {
+	c := text[0]
+	if c >= utf8.RuneSelf {
+		r, _ := utf8.DecodeRuneInString(text)
+		return unicode.IsLetter(r) || r == utf8.RuneError
+	}
+
+	return c == '_' || c == '.' || c == ':' || c == '$' || unicode.IsLetter(rune(c))
+}
@@ -0,0 +1,40 @@
+// +build unit
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens, err := Tokenize("SELECT count(*) FROM `My Event` WHERE name != 'it\\'s' -- comment\nLIMIT 10.5")
+	require.NoError(t, err)
+
+	texts := []string{}
+	for _, tok := range tokens {
+		texts = append(texts, tok.Text)
+	}
+
+	assert.Equal(t, []string{"SELECT", "count", "(", "*", ")", "FROM", "`My Event`", "WHERE", "name", "!=", "'it\\'s'", "LIMIT", "10.5"}, texts)
+	assert.Equal(t, Identifier, tokens[6].Kind)
+	assert.Equal(t, String, tokens[10].Kind)
+	assert.Equal(t, "My Event", tokens[6].Value())
+	assert.Equal(t, "it's", tokens[10].Value())
+
+	tokens, err = Tokenize("SELECT * FROM Log WHERE message = 'abc")
+	require.Error(t, err)
+	assert.Len(t, tokens, 7)
+}
+
+func TestTokenize_Unicode(t *testing.T) {
+	tokens, err := Tokenize("SELECT count(*) FROM Log WHERE città = 'Zürich'")
+	require.NoError(t, err)
+	assert.Equal(t, "città", tokens[8].Text)
+
+	_, err = Tokenize("SELECT count(*) FROM Log WHERE a → 1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'→'")
+}
@@ -0,0 +1,252 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Clause is a top level clause of a query, such as WHERE, and the tokens
+// that follow its keywords.
+type Clause struct {
+	Name   string
+	Pos    int
+	Tokens []Token
+}
+
+// Query is a query split into its clauses.  The clauses themselves are left
+// for callers to interpret.
+type Query struct {
+	Clauses []*Clause
+}
+
+// clauseKeywords are the keywords that start a clause, some of which are
+// followed by further keywords.
+var clauseKeywords = map[string]string{
+	"SELECT":      "",
+	"FROM":        "",
+	"WHERE":       "",
+	"FACET":       "",
+	"SINCE":       "",
+	"UNTIL":       "",
+	"LIMIT":       "",
+	"OFFSET":      "",
+	"TIMESERIES":  "",
+	"EXTRAPOLATE": "",
+	"COMPARE":     "WITH",
+	"ORDER":       "BY",
+	"SHOW":        "EVENT TYPES",
+	"WITH":        "TIMEZONE",
+}
+
+// clauseOrder is the order clauses are printed in a normalized query.
+var clauseOrder = []string{
+	"SHOW EVENT TYPES", "SELECT", "FROM", "WHERE", "FACET", "ORDER BY", "LIMIT", "OFFSET",
+	"SINCE", "UNTIL", "WITH TIMEZONE", "COMPARE WITH", "TIMESERIES", "EXTRAPOLATE",
+}
+
+// IsClauseKeyword reports whether a word starts a clause.
+func IsClauseKeyword(word string) bool {
+	_, ok := clauseKeywords[strings.ToUpper(word)]
+	return ok
+}
+
+// Parse splits a query into its clauses, checking that parentheses are
+// balanced and that each clause appears at most once.
+func Parse(query string) (*Query, error) {
+	tokens, err := Tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{}
+	var current *Clause
+	depth := 0
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+
+		if t.IsSymbol("(") {
+			depth++
+		}
+
+		if t.IsSymbol(")") {
+			if depth == 0 {
+				return nil, &Error{t.Pos, "unbalanced ')'"}
+			}
+			depth--
+		}
+
+		name, following := clauseStart(tokens, i, depth)
+		if name == "" {
+			if current == nil {
+				return nil, &Error{t.Pos, fmt.Sprintf("unknown clause %s", t.Text)}
+			}
+
+			current.Tokens = append(current.Tokens, t)
+			continue
+		}
+
+		if q.Clause(name) != nil {
+			return nil, &Error{t.Pos, fmt.Sprintf("duplicate %s clause", name)}
+		}
+
+		current = &Clause{Name: name, Pos: t.Pos}
+		q.Clauses = append(q.Clauses, current)
+		i += following
+	}
+
+	if depth > 0 {
+		return nil, &Error{len(query), "missing ')'"}
+	}
+
+	if len(q.Clauses) == 0 {
+		return nil, &Error{0, "empty query"}
+	}
+
+	return q, nil
+}
+
+// clauseStart returns the name of the clause starting at the token, if any,
+// and how many further keywords the clause name takes up.
+func clauseStart(tokens []Token, i int, depth int) (string, int) {
+	if depth > 0 || tokens[i].Kind != Word {
+		return "", 0
+	}
+
+	word := strings.ToUpper(tokens[i].Text)
+	second, ok := clauseKeywords[word]
+	if !ok {
+		return "", 0
+	}
+
+	if second == "" {
+		return word, 0
+	}
+
+	words := strings.Fields(second)
+	for j, w := range words {
+		if i+1+j >= len(tokens) || !tokens[i+1+j].Is(w) {
+			return "", 0
+		}
+	}
+
+	return word + " " + second, len(words)
+}
+
+// Clause returns the named clause, or nil if the query does not have it.
+func (q *Query) Clause(name string) *Clause {
+	for _, c := range q.Clauses {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// EventTypes returns the event types in the FROM clause.
+func (q *Query) EventTypes() []string {
+	from := q.Clause("FROM")
+	if from == nil {
+		return nil
+	}
+
+	types := []string{}
+	for _, item := range SplitList(from.Tokens) {
+		if len(item) > 0 {
+			types = append(types, item[0].Value())
+		}
+	}
+
+	return types
+}
+
+// String returns the query with one clause per line, in the conventional
+// order, and keywords in upper case.
+func (q *Query) String() string {
+	lines := []string{}
+
+	for _, name := range clauseOrder {
+		if c := q.Clause(name); c != nil {
+			line := name
+			if body := JoinTokens(c.Tokens); body != "" {
+				line += " " + body
+			}
+
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// SplitList splits tokens at the commas outside parentheses.
+func SplitList(tokens []Token) [][]Token {
+	items := [][]Token{{}}
+	depth := 0
+
+	for _, t := range tokens {
+		if t.Kind == Symbol {
+			switch t.Text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			case ",":
+				if depth == 0 {
+					items = append(items, []Token{})
+					continue
+				}
+			}
+		}
+
+		items[len(items)-1] = append(items[len(items)-1], t)
+	}
+
+	return items
+}
+
+// operatorKeywords are written in upper case in a normalized query.
+var operatorKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "IN": true, "LIKE": true, "IS": true, "NULL": true,
+	"AS": true, "AGO": true, "BY": true, "SLIDE": true, "AUTO": true, "MAX": true, "WHERE": true,
+	"CASES": true, "ASC": true, "DESC": true, "NOW": true, "TODAY": true, "YESTERDAY": true,
+	"THIS": true, "LAST": true, "RLIKE": true,
+}
+
+// JoinTokens writes tokens back out with conventional spacing.
+func JoinTokens(tokens []Token) string {
+	var b strings.Builder
+
+	for i, t := range tokens {
+		text := t.Text
+		if t.Kind == Word && operatorKeywords[strings.ToUpper(text)] {
+			text = strings.ToUpper(text)
+		}
+
+		if i > 0 && needsSpace(tokens[i-1], t) {
+			b.WriteByte(' ')
+		}
+
+		b.WriteString(text)
+	}
+
+	return b.String()
+}
+
+func needsSpace(prev Token, next Token) bool {
+	if next.IsSymbol(",") || next.IsSymbol(")") {
+		return false
+	}
+
+	if prev.IsSymbol("(") {
+		return false
+	}
+
+	if next.IsSymbol("(") && prev.Kind == Word {
+		upper := strings.ToUpper(prev.Text)
+		return operatorKeywords[upper] && upper != "CASES"
+	}
+
+	return true
+}
@@ -0,0 +1,43 @@
+// +build unit
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	q, err := Parse("from Transaction, PageView select count(*) where appName = 'since' since 1 day ago facet cases(where a = 1, where b = 2)")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Transaction", "PageView"}, q.EventTypes())
+	assert.NotNil(t, q.Clause("SINCE"))
+	assert.Nil(t, q.Clause("UNTIL"))
+
+	assert.Equal(t, `SELECT count(*)
+FROM Transaction, PageView
+WHERE appName = 'since'
+FACET CASES(WHERE a = 1, WHERE b = 2)
+SINCE 1 day AGO`, q.String())
+
+	q, err = Parse("SELECT count(*) FROM Transaction COMPARE WITH 1 week ago")
+	require.NoError(t, err)
+	assert.NotNil(t, q.Clause("COMPARE WITH"))
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"count(*) FROM Transaction",
+		"SELECT count(* FROM Transaction",
+		"SELECT count(*)) FROM Transaction",
+		"SELECT count(*) FROM Transaction WHERE a = 1 WHERE b = 2",
+		"SELECT count(*) FROM Transaction WHERE a ; 1",
+	} {
+		_, err := Parse(query)
+		assert.Error(t, err, query)
+	}
+}
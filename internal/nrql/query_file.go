@@ -0,0 +1,157 @@
+package nrql
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// queryNameDirective starts a named query in a query file, e.g.
+// "-- name: errors".
+var queryNameDirective = regexp.MustCompile(`^--\s*name:\s*(\S+)\s*$`)
+
+// namedQuery is a query read from a query file.
+type namedQuery struct {
+	Name  string
+	Query string
+}
+
+// parseQueryFile reads the queries in a query file.  Each query is preceded by
+// a "-- name: <name>" line and runs until the next one.  Other lines starting
+// with "--" or "//" are comments.  A file without any names holds a single
+// query named "query".
+func parseQueryFile(r io.Reader) ([]namedQuery, error) {
+	queries := []namedQuery{}
+	names := map[string]bool{}
+
+	var current *namedQuery
+	var lines []string
+
+	finish := func() error {
+		q := strings.TrimSpace(strings.TrimRight(strings.Join(lines, "\n"), "; \n"))
+		lines = nil
+
+		if current == nil {
+			if q != "" {
+				current = &namedQuery{Name: "query"}
+			} else {
+				return nil
+			}
+		}
+
+		if q == "" {
+			return fmt.Errorf("query %s is empty", current.Name)
+		}
+
+		current.Query = q
+		queries = append(queries, *current)
+
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if m := queryNameDirective.FindStringSubmatch(trimmed); m != nil {
+			if current == nil && strings.TrimSpace(strings.Join(lines, "")) != "" {
+				return nil, fmt.Errorf("found a query before the first name, add a '-- name: <name>' line before it")
+			}
+
+			if err := finish(); err != nil {
+				return nil, err
+			}
+
+			if names[m[1]] {
+				return nil, fmt.Errorf("query %s is defined more than once", m[1])
+			}
+
+			names[m[1]] = true
+			current = &namedQuery{Name: m[1]}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "--") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := finish(); err != nil {
+		return nil, err
+	}
+
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no queries found")
+	}
+
+	return queries, nil
+}
+
+// selectQueries returns the queries with the given names, in the order
+// requested, or all of them when no names are given.
+func selectQueries(queries []namedQuery, names []string) ([]namedQuery, error) {
+	if len(names) == 0 {
+		return queries, nil
+	}
+
+	selected := []namedQuery{}
+	for _, n := range names {
+		found := false
+		for _, q := range queries {
+			if q.Name == n {
+				selected = append(selected, q)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("no query named %s", n)
+		}
+	}
+
+	return selected, nil
+}
+
+// parseQueryVars parses variables given as key=value pairs.
+func parseQueryVars(pairs []string) (map[string]string, error) {
+	vars := map[string]string{}
+
+	for _, p := range pairs {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected key=value", p)
+		}
+
+		vars[strings.TrimSpace(kv[0])] = kv[1]
+	}
+
+	return vars, nil
+}
+
+// renderQuery substitutes variables into a query, referenced as
+// {{.name}}.  Referencing a variable that was not given is an error.
+func renderQuery(name string, query string, vars map[string]string) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid query template %s: %s", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("could not render query %s: %s", name, err)
+	}
+
+	return buf.String(), nil
+}
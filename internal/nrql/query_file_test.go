@@ -0,0 +1,77 @@
+// +build unit
+
+package nrql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testQueryFile = `
+// Standard diagnostic queries
+-- name: errors
+SELECT count(*)
+  FROM TransactionError
+  WHERE appName = '{{.app}}' SINCE {{.since}};
+
+-- name: throughput
+-- Requests per minute
+SELECT rate(count(*), 1 minute) FROM Transaction WHERE appName = '{{.app}}' SINCE {{.since}}
+`
+
+func TestParseQueryFile(t *testing.T) {
+	queries, err := parseQueryFile(strings.NewReader(testQueryFile))
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+
+	assert.Equal(t, "errors", queries[0].Name)
+	assert.Equal(t, "SELECT count(*)\n  FROM TransactionError\n  WHERE appName = '{{.app}}' SINCE {{.since}}", queries[0].Query)
+	assert.Equal(t, "throughput", queries[1].Name)
+
+	queries, err = parseQueryFile(strings.NewReader("SELECT count(*) FROM Transaction;\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []namedQuery{{Name: "query", Query: "SELECT count(*) FROM Transaction"}}, queries)
+}
+
+func TestParseQueryFile_Errors(t *testing.T) {
+	_, err := parseQueryFile(strings.NewReader(""))
+	require.Error(t, err)
+
+	_, err = parseQueryFile(strings.NewReader("SELECT 1\n-- name: a\nSELECT 2"))
+	require.Error(t, err)
+
+	_, err = parseQueryFile(strings.NewReader("-- name: a\nSELECT 1\n-- name: a\nSELECT 2"))
+	require.Error(t, err)
+
+	_, err = parseQueryFile(strings.NewReader("-- name: a\n-- name: b\nSELECT 2"))
+	require.Error(t, err)
+}
+
+func TestSelectQueries(t *testing.T) {
+	queries := []namedQuery{{Name: "a"}, {Name: "b"}}
+
+	selected, err := selectQueries(queries, []string{"b"})
+	require.NoError(t, err)
+	assert.Equal(t, []namedQuery{{Name: "b"}}, selected)
+
+	_, err = selectQueries(queries, []string{"c"})
+	require.Error(t, err)
+}
+
+func TestRenderQuery(t *testing.T) {
+	vars, err := parseQueryVars([]string{"app=checkout", "since=1 hour ago"})
+	require.NoError(t, err)
+
+	q, err := renderQuery("errors", "SELECT count(*) FROM Transaction WHERE appName = '{{.app}}' SINCE {{.since}}", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT count(*) FROM Transaction WHERE appName = 'checkout' SINCE 1 hour ago", q)
+
+	_, err = renderQuery("errors", "SELECT count(*) FROM Transaction WHERE appName = '{{.missing}}'", vars)
+	require.Error(t, err)
+
+	_, err = parseQueryVars([]string{"app"})
+	require.Error(t, err)
+}
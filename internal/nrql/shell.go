@@ -0,0 +1,319 @@
+package nrql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/nrql/parser"
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+const shellHelp = `Queries may span several lines and are run when a line ends with ';' or
+when an empty line is entered.
+
+Meta-commands:
+  \account <id>   switch the account queries are run against
+  \format <name>  switch the output format (%s)
+  \reset          discard the query being entered
+  \help           show this help
+  \quit           exit the shell
+`
+
+var nrqlKeywords = []string{
+	"SELECT", "FROM", "WHERE", "FACET", "SINCE", "UNTIL", "LIMIT", "MAX", "TIMESERIES", "AUTO",
+	"COMPARE WITH", "AS", "AND", "OR", "NOT", "IN", "LIKE", "IS NULL", "IS NOT NULL", "ORDER BY",
+	"ago", "minutes", "hours", "days", "weeks", "now",
+	"count(*)", "average(", "sum(", "min(", "max(", "uniqueCount(", "uniques(", "latest(",
+	"percentile(", "histogram(", "rate(", "filter(", "keyset()",
+}
+
+// nrqlShell is an interactive NRQL prompt.  Lines are buffered until a
+// statement is complete, meta-commands change the shell's settings, and event
+// types and attribute names are looked up for completion as they are needed.
+type nrqlShell struct {
+	ctx       context.Context
+	client    nrdbClient
+	out       io.Writer
+	accountID int
+
+	pending    []string
+	eventTypes []string
+	attributes map[string][]string
+}
+
+func newNRQLShell(ctx context.Context, client nrdbClient, out io.Writer, accountID int) *nrqlShell {
+	return &nrqlShell{
+		ctx:        ctx,
+		client:     client,
+		out:        out,
+		accountID:  accountID,
+		attributes: map[string][]string{},
+	}
+}
+
+// prompt returns the prompt for the next line, which shows whether a
+// statement is being continued.
+func (s *nrqlShell) prompt() string {
+	if len(s.pending) > 0 {
+		return fmt.Sprintf("%*s> ", len(strconv.Itoa(s.accountID))+6, "...")
+	}
+
+	return fmt.Sprintf("nrql %d> ", s.accountID)
+}
+
+// handleLine processes a line of input.  It returns the statement that was
+// run, if any, for recording in the history, and whether the shell should
+// exit.
+func (s *nrqlShell) handleLine(line string) (statement string, exit bool) {
+	trimmed := strings.TrimSpace(line)
+
+	if len(s.pending) == 0 && strings.HasPrefix(trimmed, `\`) {
+		return trimmed, s.metaCommand(trimmed)
+	}
+
+	if trimmed != "" {
+		s.pending = append(s.pending, trimmed)
+	}
+
+	if len(s.pending) == 0 || (trimmed != "" && !strings.HasSuffix(trimmed, ";")) {
+		return "", false
+	}
+
+	statement = strings.Join(s.pending, " ")
+	s.pending = nil
+
+	if q := strings.TrimSpace(strings.TrimRight(statement, "; ")); q != "" {
+		s.run(q)
+	}
+
+	return statement, false
+}
+
+// reset discards any statement being entered.
+func (s *nrqlShell) reset() {
+	s.pending = nil
+}
+
+func (s *nrqlShell) run(query string) {
+	result, err := s.client.QueryWithContext(s.ctx, s.accountID, nrdb.NRQL(query))
+	if err != nil {
+		fmt.Fprintf(s.out, "Error: %s\n", err)
+		return
+	}
+
+	if err = output.Print(result.Results); err != nil {
+		fmt.Fprintf(s.out, "Error: %s\n", err)
+	}
+}
+
+func (s *nrqlShell) metaCommand(command string) (exit bool) {
+	fields := strings.Fields(command)
+
+	switch strings.ToLower(fields[0]) {
+	case `\q`, `\quit`, `\exit`:
+		return true
+	case `\account`:
+		if len(fields) != 2 {
+			fmt.Fprintf(s.out, "Current account: %d\n", s.accountID)
+			return false
+		}
+
+		id, err := strconv.Atoi(fields[1])
+		if err != nil || id <= 0 {
+			fmt.Fprintf(s.out, "Error: invalid account ID %q\n", fields[1])
+			return false
+		}
+
+		s.accountID = id
+		s.eventTypes = nil
+		s.attributes = map[string][]string{}
+	case `\format`:
+		if len(fields) != 2 {
+			fmt.Fprintf(s.out, "Usage: \\format <%s>\n", output.FormatOptions())
+			return false
+		}
+
+		format := output.ParseFormat(fields[1])
+		if !strings.EqualFold(format.String(), fields[1]) {
+			fmt.Fprintf(s.out, "Error: unknown format %q\n", fields[1])
+			return false
+		}
+
+		if err := output.SetFormat(format); err != nil {
+			fmt.Fprintf(s.out, "Error: %s\n", err)
+		}
+	case `\reset`:
+		s.reset()
+	case `\h`, `\help`, `\?`:
+		fmt.Fprintf(s.out, shellHelp, output.FormatOptions())
+	default:
+		fmt.Fprintf(s.out, "Unknown command %s, try \\help\n", fields[0])
+	}
+
+	return false
+}
+
+// Do implements readline.AutoCompleter, completing the word before the cursor
+// with NRQL keywords, event types and the attributes of the event types named
+// in the FROM clause.
+func (s *nrqlShell) Do(line []rune, pos int) ([][]rune, int) {
+	before := string(line[:pos])
+
+	start := strings.LastIndexFunc(before, func(r rune) bool {
+		return !(r == '_' || r == '.' || r == '(' || r == '*' || r == ')' || r == '-' ||
+			('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9'))
+	}) + 1
+	word := before[start:]
+
+	if word == "" || strings.HasPrefix(before, `\`) {
+		return nil, 0
+	}
+
+	statement := strings.Join(append(append([]string{}, s.pending...), string(line)), " ")
+
+	candidates := []string{}
+	seen := map[string]bool{}
+	add := func(c string) {
+		if !seen[c] && len(c) > len(word) {
+			seen[c] = true
+			candidates = append(candidates, c[len(word):])
+		}
+	}
+
+	for _, k := range nrqlKeywords {
+		if strings.HasPrefix(strings.ToLower(k), strings.ToLower(word)) {
+			if word == strings.ToLower(word) {
+				k = strings.ToLower(k)
+			}
+			add(word + k[len(word):])
+		}
+	}
+
+	known := map[string]bool{}
+	for _, t := range s.fetchEventTypes() {
+		known[t] = true
+		if strings.HasPrefix(t, word) {
+			add(t)
+		}
+	}
+
+	for _, t := range eventTypesIn(statement) {
+		if !known[t] {
+			continue
+		}
+
+		for _, a := range s.fetchAttributes(t) {
+			if strings.HasPrefix(a, word) {
+				add(a)
+			}
+		}
+	}
+
+	sort.Strings(candidates)
+
+	newLine := make([][]rune, len(candidates))
+	for i, c := range candidates {
+		newLine[i] = []rune(c)
+	}
+
+	return newLine, len(word)
+}
+
+// eventTypesIn returns the event types named in a statement's FROM clause.
+// The statement is usually incomplete, so only its tokens are looked at.
+func eventTypesIn(statement string) []string {
+	tokens, _ := parser.Tokenize(statement)
+
+	for i, t := range tokens {
+		if !t.Is("FROM") {
+			continue
+		}
+
+		types := []string{}
+		for j := i + 1; j < len(tokens) && (tokens[j].Kind == parser.Word || tokens[j].Kind == parser.Identifier); j += 2 {
+			if parser.IsClauseKeyword(tokens[j].Text) {
+				break
+			}
+
+			types = append(types, tokens[j].Value())
+
+			if j+1 >= len(tokens) || tokens[j+1].Text != "," {
+				break
+			}
+		}
+
+		return types
+	}
+
+	return nil
+}
+
+// fetchEventTypes returns the account's event types, querying them the
+// first time they are needed.
+func (s *nrqlShell) fetchEventTypes() []string {
+	if s.eventTypes != nil {
+		return s.eventTypes
+	}
+
+	s.eventTypes = []string{}
+
+	result, err := s.client.QueryWithContext(s.ctx, s.accountID, "SHOW EVENT TYPES")
+	if err != nil {
+		log.Debugf("could not fetch event types: %s", err)
+		return s.eventTypes
+	}
+
+	for _, r := range result.Results {
+		if t, ok := r["eventType"].(string); ok {
+			s.eventTypes = append(s.eventTypes, t)
+		}
+	}
+
+	return s.eventTypes
+}
+
+// fetchAttributes returns the attribute names of an event type, querying them
+// the first time they are needed.
+func (s *nrqlShell) fetchAttributes(eventType string) []string {
+	if a, ok := s.attributes[eventType]; ok {
+		return a
+	}
+
+	attributes := []string{}
+	s.attributes[eventType] = attributes
+
+	query := nrdb.NRQL(fmt.Sprintf("SELECT keyset() FROM %s", eventType))
+
+	result, err := s.client.QueryWithContext(s.ctx, s.accountID, query)
+	if err != nil {
+		log.Debugf("could not fetch attributes of %s: %s", eventType, err)
+		return attributes
+	}
+
+	// keyset() returns a row per attribute, or in some forms a single row
+	// holding lists of keys
+	for _, r := range result.Results {
+		if k, ok := r["key"].(string); ok {
+			attributes = append(attributes, k)
+		}
+
+		if keys, ok := r["allKeys"].([]interface{}); ok {
+			for _, k := range keys {
+				if name, isString := k.(string); isString {
+					attributes = append(attributes, name)
+				}
+			}
+		}
+	}
+
+	s.attributes[eventType] = attributes
+
+	return attributes
+}
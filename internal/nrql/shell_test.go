@@ -0,0 +1,93 @@
+// +build unit
+
+package nrql
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+func TestShell_HandleLine(t *testing.T) {
+	c := &mockNRDBClient{}
+	var out bytes.Buffer
+	s := newNRQLShell(context.Background(), c, &out, 1)
+
+	statement, exit := s.handleLine("SELECT count(*)")
+	assert.Empty(t, statement)
+	assert.False(t, exit)
+	assert.Equal(t, "    ...> ", s.prompt())
+
+	statement, exit = s.handleLine("  FROM Transaction;")
+	assert.Equal(t, "SELECT count(*) FROM Transaction;", statement)
+	assert.False(t, exit)
+	assert.Equal(t, "nrql 1> ", s.prompt())
+
+	s.handleLine("SELECT * FROM Log")
+	statement, _ = s.handleLine("")
+	assert.Equal(t, "SELECT * FROM Log", statement)
+
+	assert.Equal(t, []string{"SELECT count(*) FROM Transaction", "SELECT * FROM Log"}, c.queries)
+}
+
+func TestShell_MetaCommands(t *testing.T) {
+	var out bytes.Buffer
+	s := newNRQLShell(context.Background(), &mockNRDBClient{}, &out, 1)
+
+	_, exit := s.handleLine(`\account 2`)
+	assert.False(t, exit)
+	assert.Equal(t, 2, s.accountID)
+
+	s.handleLine(`\account abc`)
+	assert.Equal(t, 2, s.accountID)
+	assert.Contains(t, out.String(), "invalid account ID")
+
+	s.handleLine(`\format nope`)
+	assert.Contains(t, out.String(), "unknown format")
+
+	_, exit = s.handleLine(`\quit`)
+	assert.True(t, exit)
+}
+
+func TestShell_Complete(t *testing.T) {
+	c := &mockNRDBClient{
+		results: [][]nrdb.NRDBResult{
+			{{"eventType": "Transaction"}, {"eventType": "TransactionError"}, {"eventType": "Log"}},
+			{{"key": "appName", "type": "string"}, {"key": "appId", "type": "numeric"}},
+		},
+	}
+	s := newNRQLShell(context.Background(), c, &bytes.Buffer{}, 1)
+
+	line := []rune("sel")
+	candidates, length := s.Do(line, len(line))
+	require.Len(t, candidates, 1)
+	assert.Equal(t, "ect", string(candidates[0]))
+	assert.Equal(t, 3, length)
+
+	line = []rune("SELECT * FROM Trans")
+	candidates, _ = s.Do(line, len(line))
+	require.Len(t, candidates, 2)
+	assert.Equal(t, "action", string(candidates[0]))
+	assert.Equal(t, "actionError", string(candidates[1]))
+
+	line = []rune("SELECT * FROM Transaction WHERE app")
+	candidates, _ = s.Do(line, len(line))
+	require.Len(t, candidates, 2)
+	assert.Equal(t, "Id", string(candidates[0]))
+	assert.Equal(t, "Name", string(candidates[1]))
+
+	// Event types and attributes are only fetched once
+	s.Do(line, len(line))
+	assert.Equal(t, []string{"SHOW EVENT TYPES", "SELECT keyset() FROM Transaction"}, c.queries)
+}
+
+func TestEventTypesIn(t *testing.T) {
+	assert.Equal(t, []string{"Transaction"}, eventTypesIn("SELECT * FROM Transaction WHERE a = 1"))
+	assert.Equal(t, []string{"Transaction", "PageView"}, eventTypesIn("SELECT * FROM Transaction, PageView SINCE 1 hour ago"))
+	assert.Nil(t, eventTypesIn("SELECT"))
+}
@@ -0,0 +1,165 @@
+package nrql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/nrql/parser"
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+const timestampKey = "timestamp"
+
+// managedClauses are added to tailed and exported queries by the commands
+// themselves.
+var managedClauses = []string{"SINCE", "UNTIL", "TIMESERIES", "FACET", "COMPARE WITH"}
+
+// tailer repeatedly runs a query for new events, using the timestamp of the
+// newest event seen as the start of the next query's time window.  Events at
+// that boundary are returned again by the next query, so the events seen at
+// the newest timestamp are remembered and skipped.
+type tailer struct {
+	client    nrdbClient
+	accountID int
+	query     string
+	since     string
+	hasLimit  bool
+
+	lastTimestamp float64
+	seen          map[string]bool
+}
+
+func newTailer(client nrdbClient, accountID int, query string, since string) (*tailer, error) {
+	q, err := checkManagedClauses(query, managedClauses)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tail query: %s", err)
+	}
+
+	t := tailer{
+		client:    client,
+		accountID: accountID,
+		query:     query,
+		since:     since,
+		hasLimit:  q.Clause("LIMIT") != nil,
+		seen:      map[string]bool{},
+	}
+
+	return &t, nil
+}
+
+// checkManagedClauses parses the query, returning an error if it has any of
+// the given clauses.
+func checkManagedClauses(query string, clauses []string) (*parser.Query, error) {
+	q, err := parser.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range clauses {
+		if q.Clause(c) != nil {
+			return nil, fmt.Errorf("%s clauses are added by the command, remove the query's own", c)
+		}
+	}
+
+	return q, nil
+}
+
+// nextQuery returns the query for the next poll.
+func (t *tailer) nextQuery() string {
+	since := t.since
+	if t.lastTimestamp > 0 {
+		since = fmt.Sprintf("%d", int64(t.lastTimestamp))
+	}
+
+	q := fmt.Sprintf("%s SINCE %s UNTIL now", t.query, since)
+	if !t.hasLimit {
+		q += " LIMIT MAX"
+	}
+
+	return q
+}
+
+// poll runs the query once, returning the events not seen before, oldest
+// first.
+func (t *tailer) poll(ctx context.Context) ([]nrdb.NRDBResult, error) {
+	query := t.nextQuery()
+	log.Debugf("tailing with query: %s", query)
+
+	result, err := t.client.QueryWithContext(ctx, t.accountID, nrdb.NRQL(query))
+	if err != nil {
+		return nil, err
+	}
+
+	results := append([]nrdb.NRDBResult{}, result.Results...)
+	sort.SliceStable(results, func(i, j int) bool {
+		a, _ := toFloat(results[i][timestampKey])
+		b, _ := toFloat(results[j][timestampKey])
+		return a < b
+	})
+
+	events := []nrdb.NRDBResult{}
+	for _, r := range results {
+		ts, ok := toFloat(r[timestampKey])
+		if !ok {
+			return nil, fmt.Errorf("tail queries must return the timestamp attribute, e.g. SELECT * or SELECT timestamp, message")
+		}
+
+		key, err := json.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if ts < t.lastTimestamp || t.seen[string(key)] {
+			continue
+		}
+
+		if ts > t.lastTimestamp {
+			t.lastTimestamp = ts
+			t.seen = map[string]bool{}
+		}
+
+		t.seen[string(key)] = true
+		events = append(events, r)
+	}
+
+	return events, nil
+}
+
+// run polls for new events every interval, printing them, until the context
+// is cancelled.
+func (t *tailer) run(ctx context.Context, interval time.Duration, print func([]nrdb.NRDBResult) error) error {
+	if interval <= 0 {
+		return fmt.Errorf("the tail interval must be greater than zero")
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		events, err := t.poll(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if len(events) > 0 {
+			if err = print(events); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// +build unit
+
+package nrql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+type mockNRDBClient struct {
+	queries []string
+	results [][]nrdb.NRDBResult
+}
+
+func (c *mockNRDBClient) QueryWithContext(ctx context.Context, accountID int, query nrdb.NRQL) (*nrdb.NRDBResultContainer, error) {
+	c.queries = append(c.queries, string(query))
+
+	results := []nrdb.NRDBResult{}
+	if len(c.results) > 0 {
+		results = c.results[0]
+		c.results = c.results[1:]
+	}
+
+	return &nrdb.NRDBResultContainer{Results: results}, nil
+}
+
+func TestTailer_Poll(t *testing.T) {
+	c := &mockNRDBClient{
+		results: [][]nrdb.NRDBResult{
+			{
+				{"timestamp": float64(2000), "message": "b"},
+				{"timestamp": float64(1000), "message": "a"},
+			},
+			{
+				{"timestamp": float64(2000), "message": "b"},
+				{"timestamp": float64(2000), "message": "c"},
+				{"timestamp": float64(3000), "message": "d"},
+			},
+		},
+	}
+
+	tl, err := newTailer(c, 1, "SELECT * FROM Log", "1 minute ago")
+	require.NoError(t, err)
+
+	events, err := tl.poll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "a", events[0]["message"])
+	assert.Equal(t, "b", events[1]["message"])
+
+	events, err = tl.poll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "c", events[0]["message"])
+	assert.Equal(t, "d", events[1]["message"])
+
+	assert.Equal(t, []string{
+		"SELECT * FROM Log SINCE 1 minute ago UNTIL now LIMIT MAX",
+		"SELECT * FROM Log SINCE 2000 UNTIL now LIMIT MAX",
+	}, c.queries)
+}
+
+func TestTailer_Validation(t *testing.T) {
+	_, err := newTailer(&mockNRDBClient{}, 1, "SELECT * FROM Log SINCE 1 hour ago", "1 minute ago")
+	require.Error(t, err)
+
+	tl, err := newTailer(&mockNRDBClient{}, 1, "SELECT * FROM Log LIMIT 10", "1 minute ago")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM Log LIMIT 10 SINCE 1 minute ago UNTIL now", tl.nextQuery())
+
+	c := &mockNRDBClient{results: [][]nrdb.NRDBResult{{{"count": float64(1)}}}}
+	tl, err = newTailer(c, 1, "SELECT count(*) FROM Log", "1 minute ago")
+	require.NoError(t, err)
+
+	_, err = tl.poll(context.Background())
+	require.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package nrql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
+)
+
+// clearScreen moves the cursor to the top left of the terminal and clears it,
+// so each run of a watched query is redrawn in place.
+const clearScreen = "\033[H\033[2J"
+
+// watchQuery runs the query every interval until the context is cancelled,
+// clearing the screen and rendering the result each time.  Query errors are
+// reported in place of the result rather than ending the watch, so that a
+// transient failure does not interrupt it.
+func watchQuery(
+	ctx context.Context,
+	client nrdbClient,
+	w io.Writer,
+	accountID int,
+	query string,
+	interval time.Duration,
+	render func(*nrdb.NRDBResultContainer) error,
+) error {
+	if interval <= 0 {
+		return fmt.Errorf("the watch interval must be greater than zero")
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := client.QueryWithContext(ctx, accountID, nrdb.NRQL(query))
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		fmt.Fprint(w, clearScreen)
+		fmt.Fprintf(w, "Every %s: %s    %s\n\n", interval, query, time.Now().Format(time.RFC1123))
+
+		if err == nil {
+			err = render(result)
+		}
+
+		if err != nil {
+			log.Debugf("watched query failed: %s", err)
+			fmt.Fprintf(w, "Error: %s\n", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package output
 
 import (
+	"os"
+	"strconv"
+
 	"github.com/hokaccha/go-prettyjson"
 	"golang.org/x/term"
 )
@@ -14,10 +17,7 @@ func New(opts ...ConfigOption) (*Output, error) {
 	}
 
 	// Set some defaults
-	w, _, err := term.GetSize(0)
-	if err == nil {
-		config.terminalWidth = w
-	}
+	config.terminalWidth = terminalWidth()
 
 	// Loop through config options
 	for _, fn := range opts {
@@ -34,6 +34,27 @@ func New(opts ...ConfigOption) (*Output, error) {
 	return config, nil
 }
 
+// terminalWidth returns the width of the terminal stdout is attached to.  When
+// stdout is not a terminal the COLUMNS environment variable is used if set,
+// otherwise 0 is returned so output piped to other programs is not truncated.
+func terminalWidth() int {
+	fd := int(os.Stdout.Fd())
+
+	if term.IsTerminal(fd) {
+		if w, _, err := term.GetSize(fd); err == nil && w > 0 {
+			return w
+		}
+
+		return DefaultTerminalWidth
+	}
+
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+
+	return 0
+}
+
 type ConfigOption func(*Output) error
 
 func ConfigFormat(format Format) ConfigOption {
@@ -49,3 +70,31 @@ func ConfigPrettyPrint(pretty bool) ConfigOption {
 		return nil
 	}
 }
+
+func ConfigQuery(query string) ConfigOption {
+	return func(cfg *Output) error {
+		cfg.query = query
+		return nil
+	}
+}
+
+func ConfigTemplate(text string) ConfigOption {
+	return func(cfg *Output) (err error) {
+		cfg.template, err = parseTemplate(text)
+		return err
+	}
+}
+
+func ConfigColumns(columns []string) ConfigOption {
+	return func(cfg *Output) error {
+		cfg.columns = columns
+		return nil
+	}
+}
+
+func ConfigNoTruncate(noTruncate bool) ConfigOption {
+	return func(cfg *Output) error {
+		cfg.noTruncate = noTruncate
+		return nil
+	}
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"encoding/csv"
+	"errors"
+	"os"
+)
+
+// csv prints out data as comma separated values
+func (o *Output) csv(data interface{}) error {
+	return o.delimited(data, ',')
+}
+
+// tsv prints out data as tab separated values
+func (o *Output) tsv(data interface{}) error {
+	return o.delimited(data, '\t')
+}
+
+// delimited prints out data flattened into a header row followed by one
+// row per element, with fields separated by the given delimiter.
+func (o *Output) delimited(data interface{}, delimiter rune) error {
+	// Early quit on no data
+	if data == nil {
+		return nil
+	}
+
+	if o == nil {
+		return errors.New("invalid output formatter")
+	}
+
+	t, err := flatten(data)
+	if err != nil {
+		return err
+	}
+
+	if len(o.columns) > 0 {
+		t.columns = selectColumns(t.columns, o.columns)
+	}
+
+	if len(t.columns) == 0 {
+		return nil
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	w.Comma = delimiter
+
+	if err = w.Write(t.columns); err != nil {
+		return err
+	}
+
+	for _, row := range t.rows {
+		record := make([]string, len(t.columns))
+		for i, c := range t.columns {
+			record[i] = row[c]
+		}
+
+		if err = w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
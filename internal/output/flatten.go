@@ -0,0 +1,183 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// orderedMap is a JSON object that remembers the order of its keys, which
+// is the struct field order for structs and the sorted key order for maps.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// flatTable is data flattened into rows of scalar values keyed by dotted
+// column names.  Columns are in the order they were first seen.
+type flatTable struct {
+	columns []string
+	rows    []map[string]string
+}
+
+// flatten converts data into a flatTable.  A slice becomes one row per
+// element and anything else becomes a single row.  Nested objects and arrays
+// are flattened into dotted column names such as "tags.0.key", and elements
+// with differing keys produce the union of their columns.  Scalars, and
+// slices of scalars, are placed in a column named "value".  Null values are
+// left out.
+func flatten(data interface{}) (*flatTable, error) {
+	var raw []byte
+	var err error
+
+	switch d := data.(type) {
+	case *bytes.Buffer:
+		raw = d.Bytes()
+	case []byte:
+		raw = d
+	default:
+		if raw, err = json.Marshal(d); err != nil {
+			return nil, err
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	v, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &flatTable{
+		columns: []string{},
+		rows:    []map[string]string{},
+	}
+	seen := map[string]bool{}
+
+	elements, ok := v.([]interface{})
+	if !ok {
+		elements = []interface{}{v}
+	}
+
+	for _, e := range elements {
+		row := map[string]string{}
+		keys := []string{}
+
+		if m, isMap := e.(*orderedMap); isMap {
+			flattenInto(m, "", row, &keys)
+		} else {
+			flattenInto(e, "value", row, &keys)
+		}
+
+		for _, k := range keys {
+			if !seen[k] {
+				seen[k] = true
+				t.columns = append(t.columns, k)
+			}
+		}
+
+		t.rows = append(t.rows, row)
+	}
+
+	return t, nil
+}
+
+func flattenInto(v interface{}, prefix string, row map[string]string, keys *[]string) {
+	switch value := v.(type) {
+	case *orderedMap:
+		for _, k := range value.keys {
+			flattenInto(value.values[k], joinColumn(prefix, k), row, keys)
+		}
+	case []interface{}:
+		for i, e := range value {
+			flattenInto(e, joinColumn(prefix, strconv.Itoa(i)), row, keys)
+		}
+	case nil:
+		// Null values are treated as absent, leaving the cell empty.
+	default:
+		if _, ok := row[prefix]; !ok {
+			*keys = append(*keys, prefix)
+		}
+
+		row[prefix] = scalarString(value)
+	}
+}
+
+func joinColumn(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+func scalarString(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// decodeOrdered decodes the next JSON value, preserving the key order of
+// objects as *orderedMap values.
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			m := &orderedMap{values: map[string]interface{}{}}
+			for dec.More() {
+				keyTok, keyErr := dec.Token()
+				if keyErr != nil {
+					return nil, keyErr
+				}
+
+				key := keyTok.(string)
+				value, valueErr := decodeOrdered(dec)
+				if valueErr != nil {
+					return nil, valueErr
+				}
+
+				if _, ok := m.values[key]; !ok {
+					m.keys = append(m.keys, key)
+				}
+				m.values[key] = value
+			}
+
+			_, err = dec.Token()
+			return m, err
+		case '[':
+			a := []interface{}{}
+			for dec.More() {
+				value, valueErr := decodeOrdered(dec)
+				if valueErr != nil {
+					return nil, valueErr
+				}
+
+				a = append(a, value)
+			}
+
+			_, err = dec.Token()
+			return a, err
+		}
+	}
+
+	return tok, nil
+}
@@ -0,0 +1,71 @@
+// +build unit
+
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testTag struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+}
+
+type testEntity struct {
+	Name    string    `json:"name"`
+	GUID    string    `json:"guid"`
+	Account testTag   `json:"account"`
+	Tags    []testTag `json:"tags,omitempty"`
+}
+
+func TestFlatten_Structs(t *testing.T) {
+	data := []testEntity{
+		{
+			Name:    "one",
+			GUID:    "guid-1",
+			Account: testTag{Key: "account", Values: []string{"1"}},
+		},
+		{
+			Name:    "two",
+			GUID:    "guid-2",
+			Account: testTag{Key: "account"},
+			Tags:    []testTag{{Key: "env", Values: []string{"prod", "eu"}}},
+		},
+	}
+
+	ft, err := flatten(data)
+	require.NoError(t, err)
+	require.Equal(t, []string{"name", "guid", "account.key", "account.values.0", "tags.0.key", "tags.0.values.0", "tags.0.values.1"}, ft.columns)
+	require.Equal(t, 2, len(ft.rows))
+	require.Equal(t, "1", ft.rows[0]["account.values.0"])
+	require.Equal(t, "", ft.rows[0]["tags.0.key"])
+	require.Equal(t, "eu", ft.rows[1]["tags.0.values.1"])
+}
+
+func TestFlatten_HeterogeneousMaps(t *testing.T) {
+	data := []map[string]interface{}{
+		{"count": 10, "facet": "a", "missing": nil},
+		{"average.duration": 1.5, "count": 3, "nested": map[string]interface{}{"b": true, "a": nil}},
+	}
+
+	ft, err := flatten(data)
+	require.NoError(t, err)
+	require.Equal(t, []string{"count", "facet", "average.duration", "nested.b"}, ft.columns)
+	require.Equal(t, "10", ft.rows[0]["count"])
+	require.Equal(t, "1.5", ft.rows[1]["average.duration"])
+	require.Equal(t, "true", ft.rows[1]["nested.b"])
+}
+
+func TestFlatten_Scalars(t *testing.T) {
+	ft, err := flatten([]string{"a", "b"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"value"}, ft.columns)
+	require.Equal(t, "b", ft.rows[1]["value"])
+
+	ft, err = flatten([]byte(`{"data":{"actor":{"user":{"id":1}}}}`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"data.actor.user.id"}, ft.columns)
+	require.Equal(t, "1", ft.rows[0]["data.actor.user.id"])
+}
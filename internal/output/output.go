@@ -2,6 +2,7 @@ package output
 
 import (
 	"strings"
+	"text/template"
 
 	"github.com/hokaccha/go-prettyjson"
 
@@ -22,13 +23,16 @@ const (
 	FormatJSON Format = iota
 	FormatText
 	FormatYAML
-	//FormatCSV
+	FormatCSV
+	FormatTSV
 )
 
 var formatStrings = map[Format]string{
 	FormatJSON: "JSON",
 	FormatText: "Text",
 	FormatYAML: "YAML",
+	FormatCSV:  "CSV",
+	FormatTSV:  "TSV",
 }
 
 // Output is the main ref for the output package
@@ -36,6 +40,10 @@ type Output struct {
 	format        Format
 	prettyPrint   bool
 	terminalWidth int
+	columns       []string
+	noTruncate    bool
+	query         string
+	template      *template.Template
 
 	jsonFormatter *prettyjson.Formatter
 }
@@ -88,6 +96,68 @@ func SetPrettyPrint(pretty bool) (err error) {
 	return nil
 }
 
+// SetColumns sets the columns, and their order, of tabular output formats.
+func SetColumns(columns []string) (err error) {
+	if err = ensureGlobalOutput(); err != nil {
+		return err
+	}
+
+	globalOutput.columns = columns
+
+	return nil
+}
+
+// SetNoTruncate disables the truncation of text tables to the terminal width.
+func SetNoTruncate(noTruncate bool) (err error) {
+	if err = ensureGlobalOutput(); err != nil {
+		return err
+	}
+
+	globalOutput.noTruncate = noTruncate
+
+	return nil
+}
+
+// IsText reports whether data is printed as text tables, rather than in a
+// structured format or with a template.
+func IsText() bool {
+	return globalOutput != nil && globalOutput.format == FormatText && globalOutput.template == nil
+}
+
+// TerminalWidth returns the width output should fit in, DefaultTerminalWidth
+// when it is not limited by a terminal.
+func TerminalWidth() int {
+	if globalOutput == nil || globalOutput.terminalWidth <= 0 {
+		return DefaultTerminalWidth
+	}
+
+	return globalOutput.terminalWidth
+}
+
+// SetQuery sets the gjson or JSONPath expression used to select part of the
+// data before it is printed.
+func SetQuery(query string) (err error) {
+	if err = ensureGlobalOutput(); err != nil {
+		return err
+	}
+
+	globalOutput.query = query
+
+	return nil
+}
+
+// SetTemplate sets the Go template used to print each result in place of
+// the output format.
+func SetTemplate(text string) (err error) {
+	if err = ensureGlobalOutput(); err != nil {
+		return err
+	}
+
+	globalOutput.template, err = parseTemplate(text)
+
+	return err
+}
+
 // ensureGlobalOutput is a helper function to make sure that
 // we have a global instance of the outputter at all times
 func ensureGlobalOutput() (err error) {
@@ -6,12 +6,21 @@ import (
 	"github.com/newrelic/newrelic-cli/internal/utils"
 )
 
-// Print outputs the data in the expected format
+// Print outputs the data in the expected format, after applying the
+// configured query and template, if any
 func Print(data interface{}) (err error) {
 	if err = ensureGlobalOutput(); err != nil {
 		return err
 	}
 
+	if data, err = globalOutput.transform(data); err != nil {
+		return err
+	}
+
+	if globalOutput.template != nil {
+		return globalOutput.renderTemplate(data)
+	}
+
 	switch globalOutput.format {
 	case FormatJSON:
 		err = globalOutput.json(data)
@@ -19,6 +28,10 @@ func Print(data interface{}) (err error) {
 		err = globalOutput.text(data)
 	case FormatYAML:
 		err = globalOutput.yaml(data)
+	case FormatCSV:
+		err = globalOutput.csv(data)
+	case FormatTSV:
+		err = globalOutput.tsv(data)
 	default:
 		err = globalOutput.json(data)
 	}
@@ -49,6 +62,20 @@ func Text(data interface{}) {
 	utils.LogIfFatal(globalOutput.text(data))
 }
 
+// CSV allows you to override the default output method and
+// explicitly print CSV to the screen
+func CSV(data interface{}) {
+	utils.LogIfFatal(ensureGlobalOutput())
+	utils.LogIfFatal(globalOutput.csv(data))
+}
+
+// TSV allows you to override the default output method and
+// explicitly print TSV to the screen
+func TSV(data interface{}) {
+	utils.LogIfFatal(ensureGlobalOutput())
+	utils.LogIfFatal(globalOutput.tsv(data))
+}
+
 // YAML allows you to override the default output method and
 // explicitly print YAML to the screen
 func YAML(data interface{}) {
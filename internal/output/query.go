@@ -0,0 +1,173 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/tidwall/gjson"
+)
+
+var (
+	jsonPathWildcard = regexp.MustCompile(`\[\*\]`)
+	jsonPathIndex    = regexp.MustCompile(`\[(\d+)\]`)
+)
+
+// templateFuncs are the functions available to output templates in addition
+// to the text/template builtins.
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		out, err := json.Marshal(v)
+		return string(out), err
+	},
+	"join": func(sep string, v []interface{}) string {
+		s := make([]string, len(v))
+		for i, e := range v {
+			s[i] = fmt.Sprint(e)
+		}
+
+		return strings.Join(s, sep)
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// parseTemplate parses an output template, e.g. '{{.name}} {{.guid}}'.
+func parseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	t, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %s", err)
+	}
+
+	return t, nil
+}
+
+// normalizeQuery converts the simple JSONPath forms, such as
+// "$.results[*].name" or "$.results[0]", into the equivalent gjson path.
+// Anything else is assumed to be gjson syntax already.
+func normalizeQuery(query string) string {
+	query = strings.TrimSpace(query)
+	if !strings.HasPrefix(query, "$") {
+		return query
+	}
+
+	query = jsonPathWildcard.ReplaceAllString(query[1:], ".#")
+	query = jsonPathIndex.ReplaceAllString(query, ".$1")
+
+	return strings.TrimPrefix(query, ".")
+}
+
+// transform applies the configured query to the data, returning the matching
+// value decoded into maps, slices and scalars.  If nothing matches nil is
+// returned.  Data is left untouched when neither a query nor a template has
+// been configured.
+func (o *Output) transform(data interface{}) (interface{}, error) {
+	if data == nil || (o.query == "" && o.template == nil) {
+		return data, nil
+	}
+
+	raw, err := toJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	query := normalizeQuery(o.query)
+	if query == "" {
+		return decodeJSON([]byte(raw))
+	}
+
+	result := gjson.Get(raw, query)
+	if !result.Exists() {
+		return nil, nil
+	}
+
+	return decodeJSON([]byte(result.Raw))
+}
+
+// renderTemplate executes the output template once for each element of a
+// slice, or once for any other data, printing one line per execution.  The
+// data is expected to have been through transform.
+func (o *Output) renderTemplate(data interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	items, ok := data.([]interface{})
+	if !ok {
+		items = []interface{}{data}
+	}
+
+	var buf bytes.Buffer
+	for _, item := range items {
+		if err := o.template.Execute(&buf, item); err != nil {
+			return err
+		}
+
+		fmt.Println(buf.String())
+		buf.Reset()
+	}
+
+	return nil
+}
+
+func toJSON(data interface{}) (string, error) {
+	switch d := data.(type) {
+	case *bytes.Buffer:
+		return d.String(), nil
+	case []byte:
+		return string(d), nil
+	}
+
+	out, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// decodeJSON decodes JSON into maps, slices and scalars, keeping integers as
+// int64 so they are not rendered in exponent notation.
+func decodeJSON(raw []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return convertNumbers(v), nil
+}
+
+func convertNumbers(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, e := range value {
+			value[k] = convertNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range value {
+			value[i] = convertNumbers(e)
+		}
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+
+		if f, err := value.Float64(); err == nil {
+			return f
+		}
+
+		return value.String()
+	}
+
+	return v
+}
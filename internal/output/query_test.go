@@ -0,0 +1,68 @@
+// +build unit
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	require.Equal(t, "results.#.name", normalizeQuery("$.results[*].name"))
+	require.Equal(t, "results.0.tags.1", normalizeQuery("$.results[0].tags[1]"))
+	require.Equal(t, "#.name", normalizeQuery("$[*].name"))
+	require.Equal(t, "", normalizeQuery("$"))
+	require.Equal(t, "#(accountId>1).name", normalizeQuery("#(accountId>1).name"))
+}
+
+func TestTransform_Query(t *testing.T) {
+	data := []testEntity{
+		{Name: "one", GUID: "guid-1", Tags: []testTag{{Key: "env", Values: []string{"prod"}}}},
+		{Name: "two", GUID: "guid-2"},
+	}
+
+	o := &Output{query: "#.name"}
+	result, err := o.transform(data)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"one", "two"}, result)
+
+	o.query = "$[0].tags[0].values"
+	result, err = o.transform(data)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"prod"}, result)
+
+	o.query = "#(name==two)"
+	result, err = o.transform(data)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"name": "two", "guid": "guid-2", "account": map[string]interface{}{"key": "", "values": nil}}, result)
+
+	o.query = "missing"
+	result, err = o.transform(data)
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	o.query = "data.actor.account.id"
+	result, err = o.transform([]byte(`{"data":{"actor":{"account":{"id":2508259}}}}`))
+	require.NoError(t, err)
+	require.Equal(t, int64(2508259), result)
+}
+
+func TestTransform_Template(t *testing.T) {
+	tmpl, err := parseTemplate("{{.name}} {{.guid}} {{len .tags}}")
+	require.NoError(t, err)
+
+	o := &Output{template: tmpl}
+	result, err := o.transform([]testEntity{
+		{Name: "one", GUID: "guid-1", Tags: []testTag{{Key: "env"}}},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, o.template.Execute(&buf, result.([]interface{})[0]))
+	require.Equal(t, "one guid-1 1", buf.String())
+
+	_, err = parseTemplate("{{.name")
+	require.Error(t, err)
+}
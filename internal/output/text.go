@@ -1,15 +1,34 @@
 package output
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
 )
 
+const (
+	// minColumnWidth is the narrowest a column is shrunk to when fitting a
+	// table to the terminal width.
+	minColumnWidth = 8
+	// columnSeparatorWidth is the width taken by the separator between columns.
+	columnSeparatorWidth = 3
+	truncationIndicator  = "…"
+)
+
+// textTable holds data in tabular form, with cells already rendered as text.
+type textTable struct {
+	columns []string
+	rows    []map[string]string
+}
+
 func (o *Output) text(data interface{}) error {
 	// Early quit on no data
 	if data == nil {
@@ -20,19 +39,38 @@ func (o *Output) text(data interface{}) error {
 		return errors.New("invalid output formatter")
 	}
 
+	// Raw JSON is rendered from its decoded form
+	switch d := data.(type) {
+	case *bytes.Buffer:
+		return o.textFromJSON(d.Bytes())
+	case []byte:
+		return o.textFromJSON(d)
+	}
+
 	// Let's see what they sent us
-	switch v := reflect.ValueOf(data); v.Kind() {
+	switch v := indirect(reflect.ValueOf(data)); v.Kind() {
+	case reflect.Invalid:
+		return nil
 	case reflect.String:
-		fmt.Println(data)
-	case reflect.Slice, reflect.Struct:
+		fmt.Println(v.String())
+	case reflect.Slice, reflect.Array, reflect.Struct, reflect.Map:
 		return o.renderAsTable(data)
 	default:
-		return fmt.Errorf("unable to format data type: %T", data)
+		fmt.Println(formatScalar(v))
 	}
 
 	return nil
 }
 
+func (o *Output) textFromJSON(raw []byte) error {
+	data, err := decodeJSON(raw)
+	if err != nil {
+		return err
+	}
+
+	return o.text(data)
+}
+
 func (o *Output) renderAsTable(data interface{}) error {
 	// Early quit on no data
 	if data == nil {
@@ -43,64 +81,327 @@ func (o *Output) renderAsTable(data interface{}) error {
 		return errors.New("invalid output formatter")
 	}
 
-	tw := o.newTableWriter()
+	var t *textTable
 
 	// Let's see what they sent us
-	switch v := reflect.ValueOf(data); v.Kind() {
-	case reflect.Slice:
-		// Create the header from the field names
-		typ := reflect.TypeOf(data).Elem()
-
-		cols := typ.NumField()
-		header := make([]interface{}, cols)
-		colConfig := make([]table.ColumnConfig, cols)
-
-		for i := 0; i < cols; i++ {
-			header[i] = typ.Field(i).Name
-			colConfig[i].Name = typ.Field(i).Name
-			colConfig[i].WidthMin = len(typ.Field(i).Name)
-			colConfig[i].WidthMax = o.terminalWidth * 3 / 4
-			colConfig[i].WidthMaxEnforcer = text.WrapSoft
-		}
-		tw.SetColumnConfigs(colConfig)
-		tw.AppendHeader(table.Row(header))
-
-		// Add all the rows
-		for i := 0; i < v.Len(); i++ {
-			row := make([]interface{}, v.Index(i).NumField())
-			for f := 0; f < v.Index(i).NumField(); f++ {
-				row[f] = v.Index(i).Field(f).Interface()
+	switch v := indirect(reflect.ValueOf(data)); v.Kind() {
+	// Slices become one row per element, with the union of the elements'
+	// fields or keys as the header
+	case reflect.Slice, reflect.Array:
+		t = tabulateSlice(v)
+
+	// Single Struct or Map becomes table view of Field | Value
+	case reflect.Struct, reflect.Map:
+		t = tabulateRecord(v)
+
+	default:
+		return fmt.Errorf("unable to format data as table - type: %T", data)
+	}
+
+	if len(o.columns) > 0 {
+		t.columns = selectColumns(t.columns, o.columns)
+	}
+
+	if len(t.columns) == 0 {
+		return nil
+	}
+
+	widths := o.columnWidths(t)
+
+	tw := o.newTableWriter()
+
+	header := make(table.Row, len(t.columns))
+	colConfig := make([]table.ColumnConfig, len(t.columns))
+	for i, c := range t.columns {
+		header[i] = c
+		colConfig[i].Number = i + 1
+		colConfig[i].WidthMin = text.RuneCount(c)
+	}
+	tw.SetColumnConfigs(colConfig)
+	tw.AppendHeader(header)
+
+	for _, r := range t.rows {
+		row := make(table.Row, len(t.columns))
+		for i, c := range t.columns {
+			row[i] = text.Snip(r[c], widths[i], truncationIndicator)
+		}
+		tw.AppendRow(row)
+	}
+
+	tw.Render()
+
+	return nil
+}
+
+// tabulateSlice creates a row for each element.  Structs contribute their
+// exported fields, maps their keys and anything else a single Value column.
+// Columns are ordered as they are first seen.
+func tabulateSlice(v reflect.Value) *textTable {
+	t := &textTable{
+		columns: []string{},
+		rows:    []map[string]string{},
+	}
+	seen := map[string]bool{}
+
+	for i := 0; i < v.Len(); i++ {
+		names, values := fieldsOf(indirect(v.Index(i)))
+		if names == nil {
+			names = []string{"Value"}
+			values = map[string]reflect.Value{"Value": v.Index(i)}
+		}
+
+		row := map[string]string{}
+		for _, n := range names {
+			if !seen[n] {
+				seen[n] = true
+				t.columns = append(t.columns, n)
 			}
-			tw.AppendRow(table.Row(row))
+
+			row[n] = summarize(values[n])
 		}
 
-	// Single Struct becomes table view of Field | Value
+		t.rows = append(t.rows, row)
+	}
+
+	return t
+}
+
+// tabulateRecord creates a Field | Value row for each field or key.
+func tabulateRecord(v reflect.Value) *textTable {
+	t := &textTable{
+		columns: []string{"Field", "Value"},
+		rows:    []map[string]string{},
+	}
+
+	names, values := fieldsOf(v)
+	for _, n := range names {
+		t.rows = append(t.rows, map[string]string{
+			"Field": n,
+			"Value": summarize(values[n]),
+		})
+	}
+
+	return t
+}
+
+// fieldsOf returns the exported field names of a struct or the sorted keys of
+// a map, along with their values.  Names are nil for any other kind.
+func fieldsOf(v reflect.Value) ([]string, map[string]reflect.Value) {
+	values := map[string]reflect.Value{}
+
+	switch v.Kind() {
 	case reflect.Struct:
-		typ := reflect.TypeOf(data)
-		tw.AppendHeader(table.Row{"Field", "Value"})
+		names := []string{}
+		typ := v.Type()
+
+		for i := 0; i < typ.NumField(); i++ {
+			if typ.Field(i).PkgPath != "" {
+				continue
+			}
+
+			names = append(names, typ.Field(i).Name)
+			values[typ.Field(i).Name] = v.Field(i)
+		}
+
+		return names, values
+	case reflect.Map:
+		names := []string{}
+
+		for _, k := range v.MapKeys() {
+			name := fmt.Sprint(k.Interface())
+			names = append(names, name)
+			values[name] = v.MapIndex(k)
+		}
+
+		sort.Strings(names)
+
+		return names, values
+	}
+
+	return nil, nil
+}
 
-		for f := 0; f < typ.NumField(); f++ {
-			row := []interface{}{
-				typ.Field(f).Name,
-				v.Field(f).Interface(),
+// selectColumns returns the requested columns, matched case insensitively
+// against the available ones and in the requested order.  Requested columns
+// that do not exist are kept, and are rendered empty.
+func selectColumns(available []string, requested []string) []string {
+	selected := make([]string, len(requested))
+
+	for i, r := range requested {
+		selected[i] = r
+
+		for _, a := range available {
+			if strings.EqualFold(a, r) {
+				selected[i] = a
+				break
 			}
+		}
+	}
+
+	return selected
+}
+
+// columnWidths returns the widest each column may be.  When the table does not
+// fit the terminal the widest columns are narrowed first, down to
+// minColumnWidth.  A width of 0 means the column is not truncated.
+func (o *Output) columnWidths(t *textTable) []int {
+	widths := make([]int, len(t.columns))
+
+	if o.terminalWidth <= 0 || o.noTruncate {
+		return widths
+	}
+
+	total := columnSeparatorWidth * (len(t.columns) - 1)
+	for i, c := range t.columns {
+		widths[i] = text.RuneCount(c)
+		for _, r := range t.rows {
+			if w := text.RuneCount(r[c]); w > widths[i] {
+				widths[i] = w
+			}
+		}
+
+		total += widths[i]
+	}
+
+	for total > o.terminalWidth {
+		widest := 0
+		for i := range widths {
+			if widths[i] > widths[widest] {
+				widest = i
+			}
+		}
 
-			tw.AppendRow(table.Row(row))
+		if widths[widest] <= minColumnWidth {
+			break
 		}
 
+		widths[widest]--
+		total--
+	}
+
+	return widths
+}
+
+// summarize renders a value for a table cell.  Scalars are printed as is,
+// slices of scalars are joined, and nested structs and maps are shown inline
+// with their scalar fields, nested values being summarized as {…} or [n].
+func summarize(v reflect.Value) string {
+	return summarizeDepth(v, 0)
+}
+
+func summarizeDepth(v reflect.Value, depth int) string {
+	v = indirect(v)
+
+	if !v.IsValid() {
+		return ""
+	}
+
+	if s, ok := stringer(v); ok {
+		return s
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+		names, values := fieldsOf(v)
+		if len(names) == 0 {
+			return ""
+		}
+
+		if depth > 0 {
+			return "{…}"
+		}
+
+		parts := []string{}
+		for _, n := range names {
+			if s := summarizeDepth(values[n], depth+1); s != "" {
+				parts = append(parts, n+"="+s)
+			}
+		}
+
+		if len(parts) == 0 {
+			return ""
+		}
+
+		return "{" + strings.Join(parts, ", ") + "}"
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return ""
+		}
+
+		if depth > 0 {
+			return fmt.Sprintf("[%d]", v.Len())
+		}
+
+		parts := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			e := indirect(v.Index(i))
+			switch e.Kind() {
+			case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+				if _, ok := stringer(e); !ok {
+					return fmt.Sprintf("[%d items]", v.Len())
+				}
+			}
+
+			parts[i] = summarizeDepth(e, depth+1)
+		}
+
+		return strings.Join(parts, ", ")
+	}
+
+	return formatScalar(v)
+}
+
+func formatScalar(v reflect.Value) string {
+	var s string
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		s = strconv.FormatFloat(v.Float(), 'f', -1, 64)
 	default:
-		return fmt.Errorf("unable to format data as table - type: %T", data)
+		if !v.CanInterface() {
+			return ""
+		}
+		s = fmt.Sprint(v.Interface())
 	}
 
-	tw.Render()
+	// Keep each row on a single line
+	return strings.Join(strings.Fields(s), " ")
+}
 
-	return nil
+// stringer returns the String() of values implementing fmt.Stringer, such as
+// time.Time, other than strings themselves.
+func stringer(v reflect.Value) (string, bool) {
+	if !v.CanInterface() || v.Kind() == reflect.String {
+		return "", false
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), true
+	}
+
+	return "", false
+}
+
+// indirect dereferences pointers and interfaces, returning an invalid value
+// for nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+
+		v = v.Elem()
+	}
+
+	return v
 }
 
 func (o *Output) newTableWriter() table.Writer {
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.SetAllowedRowLength(o.terminalWidth)
+	if !o.noTruncate {
+		t.SetAllowedRowLength(o.terminalWidth)
+	}
 
 	t.SetStyle(table.StyleRounded)
 	t.SetStyle(table.Style{
@@ -0,0 +1,88 @@
+// +build unit
+
+package output
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTabulateSlice_Maps(t *testing.T) {
+	data := []map[string]interface{}{
+		{"facet": "a", "count": 10},
+		{"count": 3.5, "beginTimeSeconds": 1612345678.0, "nested": map[string]interface{}{"b": true, "a": []string{"x", "y"}}},
+	}
+
+	tt := tabulateSlice(reflect.ValueOf(data))
+	require.Equal(t, []string{"count", "facet", "beginTimeSeconds", "nested"}, tt.columns)
+	require.Equal(t, "10", tt.rows[0]["count"])
+	require.Equal(t, "", tt.rows[0]["nested"])
+	require.Equal(t, "1612345678", tt.rows[1]["beginTimeSeconds"])
+	require.Equal(t, "{a=[2], b=true}", tt.rows[1]["nested"])
+}
+
+func TestTabulateSlice_Structs(t *testing.T) {
+	var data []interface{}
+	data = append(data,
+		&testEntity{Name: "one", Tags: []testTag{{Key: "env"}}},
+		testTag{Key: "team", Values: []string{"a", "b"}},
+		"scalar",
+	)
+
+	tt := tabulateSlice(reflect.ValueOf(data))
+	require.Equal(t, []string{"Name", "GUID", "Account", "Tags", "Key", "Values", "Value"}, tt.columns)
+	require.Equal(t, "[1 items]", tt.rows[0]["Tags"])
+	require.Equal(t, "", tt.rows[0]["Account"])
+	require.Equal(t, "a, b", tt.rows[1]["Values"])
+	require.Equal(t, "scalar", tt.rows[2]["Value"])
+}
+
+func TestSummarize(t *testing.T) {
+	ts := time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)
+
+	require.Equal(t, ts.String(), summarize(reflect.ValueOf(ts)))
+	require.Equal(t, "", summarize(reflect.ValueOf((*testEntity)(nil))))
+	require.Equal(t, "multi line", summarize(reflect.ValueOf("multi\nline")))
+	require.Equal(t, "{Key=env, Values=[1]}", summarize(reflect.ValueOf(testTag{Key: "env", Values: []string{"prod"}})))
+}
+
+func TestSelectColumns(t *testing.T) {
+	require.Equal(t, []string{"guid", "Name", "missing"}, selectColumns([]string{"Name", "guid"}, []string{"guid", "name", "missing"}))
+}
+
+func TestColumnWidths(t *testing.T) {
+	tt := &textTable{
+		columns: []string{"a", "b", "c"},
+		rows: []map[string]string{
+			{"a": "12345678901234567890", "b": "1234567890", "c": "123"},
+		},
+	}
+
+	o := &Output{terminalWidth: 30}
+	widths := o.columnWidths(tt)
+	require.Equal(t, []int{11, 10, 3}, widths)
+
+	o.terminalWidth = 10
+	require.Equal(t, []int{8, 8, 3}, o.columnWidths(tt))
+
+	o.noTruncate = true
+	require.Equal(t, []int{0, 0, 0}, o.columnWidths(tt))
+}
+
+func TestIsText(t *testing.T) {
+	saved := *globalOutput
+	defer func() { *globalOutput = saved }()
+
+	require.NoError(t, SetFormat(FormatText))
+	require.True(t, IsText())
+
+	require.NoError(t, SetTemplate("{{.name}}"))
+	require.False(t, IsText())
+
+	require.NoError(t, SetTemplate(""))
+	require.NoError(t, SetFormat(FormatCSV))
+	require.False(t, IsText())
+}